@@ -0,0 +1,62 @@
+package services
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+// callerCache maps the program counters of the log call sites to their resolved shared.Caller.
+// Its size is bounded by the number of call sites in the binary.
+var callerCache sync.Map
+
+// RetrieveCaller returns the shared.Caller of the given program counter, as returned by runtime.Callers.
+// Frames are resolved only once per call site and then served from the cache, so the returned Caller
+// must not be modified. It returns nil if the program counter cannot be resolved.
+func RetrieveCaller(pc uintptr) *s.Caller {
+	if caller, ok := callerCache.Load(pc); ok {
+		return caller.(*s.Caller)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return nil
+	}
+
+	caller := &s.Caller{
+		File:     trimCallerFile(frame.File),
+		Line:     frame.Line,
+		Function: trimCallerFunction(frame.Function),
+	}
+	caller.Location = caller.File + ":" + strconv.Itoa(caller.Line)
+
+	actual, _ := callerCache.LoadOrStore(pc, caller)
+	return actual.(*s.Caller)
+}
+
+// trimCallerFile trims the given file path to its last directory and file name (e.g. "logs/logger.go").
+func trimCallerFile(file string) string {
+	idx := strings.LastIndexByte(file, '/')
+	if idx <= 0 {
+		return file
+	}
+
+	if parentIdx := strings.LastIndexByte(file[:idx], '/'); parentIdx >= 0 {
+		return file[parentIdx+1:]
+	}
+
+	return file
+}
+
+// trimCallerFunction removes the package path from the given fully qualified function name,
+// keeping the package name (e.g. "logs.(*Logger).Info").
+func trimCallerFunction(function string) string {
+	if idx := strings.LastIndexByte(function, '/'); idx >= 0 {
+		return function[idx+1:]
+	}
+
+	return function
+}
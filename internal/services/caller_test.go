@@ -0,0 +1,39 @@
+package services
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetrieveCaller(t *testing.T) {
+	pcs := make([]uintptr, 1)
+	runtime.Callers(1, pcs)
+	_, _, line, _ := runtime.Caller(0)
+
+	caller := RetrieveCaller(pcs[0])
+	assert.NotNil(t, caller)
+	assert.Equal(t, "services/caller_test.go", caller.File)
+	assert.Equal(t, line-1, caller.Line)
+	assert.Equal(t, "services.TestRetrieveCaller", caller.Function)
+	assert.Equal(t, "services/caller_test.go:"+strconv.Itoa(line-1), caller.Location)
+
+	// Resolved callers are cached
+	assert.Same(t, caller, RetrieveCaller(pcs[0]))
+	assert.Nil(t, RetrieveCaller(0))
+}
+
+func TestTrimCallerFile(t *testing.T) {
+	assert.Equal(t, "logs/logger.go", trimCallerFile("/home/user/tiny-logger/logs/logger.go"))
+	assert.Equal(t, "logs/logger.go", trimCallerFile("logs/logger.go"))
+	assert.Equal(t, "/logger.go", trimCallerFile("/logger.go"))
+	assert.Equal(t, "logger.go", trimCallerFile("logger.go"))
+}
+
+func TestTrimCallerFunction(t *testing.T) {
+	assert.Equal(t, "logs.(*Logger).Info", trimCallerFunction("github.com/Pho3b/tiny-logger/logs.(*Logger).Info"))
+	assert.Equal(t, "main.main", trimCallerFunction("main.main"))
+	assert.Equal(t, "", trimCallerFunction(""))
+}
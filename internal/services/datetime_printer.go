@@ -52,6 +52,34 @@ func (d *DateTimePrinter) RetrieveDateTime(fmt s.DateTimeFormat, addDate, addTim
 	return dateRes, timeRes, ""
 }
 
+// RetrieveDateTimeAt returns the date, time and unix timestamp strings of the given time like RetrieveDateTime does.
+// The cached current ones are returned for the zero time.
+func (d *DateTimePrinter) RetrieveDateTimeAt(
+	t time.Time,
+	fmt s.DateTimeFormat,
+	addDate, addTime bool,
+) (string, string, string) {
+	if t.IsZero() {
+		return d.RetrieveDateTime(fmt, addDate, addTime)
+	}
+
+	if fmt == s.UnixTimestamp {
+		return "", "", strconv.FormatInt(t.Unix(), 10)
+	}
+
+	var dateRes, timeRes string
+
+	if addDate {
+		dateRes = t.Format(dateFormat[fmt])
+	}
+
+	if addTime {
+		timeRes = t.Format(timeFormat[fmt])
+	}
+
+	return dateRes, timeRes, ""
+}
+
 // init initializes the current timestamp and cached formatted strings,
 // then starts background goroutines to keep them updated.
 func (d *DateTimePrinter) init() {
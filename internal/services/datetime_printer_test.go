@@ -144,6 +144,27 @@ func TestDateTimePrinter_UnixTimestamp(t *testing.T) {
 	})
 }
 
+func TestDateTimePrinter_RetrieveDateTimeAt(t *testing.T) {
+	dateTimePrinter := &DateTimePrinter{
+		timeNow: func() time.Time {
+			return time.Date(2023, time.November, 1, 15, 30, 45, 0, time.UTC)
+		},
+	}
+	dateTimePrinter.init()
+	entryTime := time.Date(2024, time.March, 11, 18, 35, 43, 0, time.UTC)
+
+	dateRes, timeRes, unixTs := dateTimePrinter.RetrieveDateTimeAt(entryTime, shared.US, true, true)
+	assert.Equal(t, "03/11/2024 06:35:43 PM", dateRes+" "+timeRes)
+	assert.Empty(t, unixTs)
+
+	_, _, unixTs = dateTimePrinter.RetrieveDateTimeAt(entryTime, shared.UnixTimestamp, true, true)
+	assert.Equal(t, "1710182143", unixTs)
+
+	// The zero time falls back to the cached current time
+	dateRes, timeRes, _ = dateTimePrinter.RetrieveDateTimeAt(time.Time{}, shared.IT, true, true)
+	assert.Equal(t, "01/11/2023 15:30:45", dateRes+" "+timeRes)
+}
+
 func TestNewDateTimePrinter(t *testing.T) {
 	assert.NotNil(t, GetDateTimePrinter())
 	assert.IsType(t, &DateTimePrinter{}, GetDateTimePrinter())
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+// errorToGroup returns the given error as a shared.Group holding its message, its concrete type and its wrapped
+// errors: a single "cause" for errors implementing Unwrap() error, or the "causes" list for the ones implementing
+// Unwrap() []error, like the errors.Join ones. The nil wrapped errors, including the typed nil pointers, are skipped.
+// The stack trace exposed through the fmt.Formatter "%+v" verb (as the pkg/errors errors do) is added as well,
+// but only for the outermost error exposing it, since its verbose output already includes the wrapped errors.
+func errorToGroup(err error, stackWritten bool, depth int) s.Group {
+	msg := err.Error()
+	group := s.Group{"msg", msg, "type", reflect.TypeOf(err).String()}
+
+	if formatter, ok := err.(fmt.Formatter); ok && !stackWritten {
+		if verbose := fmt.Sprintf("%+v", formatter); verbose != msg {
+			group = append(group, "stacktrace", strings.TrimLeft(strings.TrimPrefix(verbose, msg), "\n"))
+			stackWritten = true
+		}
+	}
+
+	if depth >= maxReflectDepth {
+		return group
+	}
+
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := wrapper.Unwrap(); cause != nil && !isNilPointer(cause) {
+			group = append(group, "cause", errorToGroup(cause, stackWritten, depth+1))
+		}
+	case interface{ Unwrap() []error }:
+		causes := make([]any, 0, len(wrapper.Unwrap()))
+
+		for _, cause := range wrapper.Unwrap() {
+			if cause != nil && !isNilPointer(cause) {
+				causes = append(causes, errorToGroup(cause, stackWritten, depth+1))
+			}
+		}
+
+		if len(causes) > 0 {
+			group = append(group, "causes", causes)
+		}
+	}
+
+	return group
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"testing"
+
+	s "github.com/Pho3b/tiny-logger/shared"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// stackError mimics the pkg/errors errors, exposing their stack trace through the "%+v" verb.
+type stackError struct {
+	msg   string
+	cause error
+}
+
+func (e *stackError) Error() string {
+	if e.cause != nil {
+		return e.msg + ": " + e.cause.Error()
+	}
+
+	return e.msg
+}
+
+func (e *stackError) Unwrap() error {
+	return e.cause
+}
+
+func (e *stackError) Format(f fmt.State, verb rune) {
+	_, _ = io.WriteString(f, e.Error())
+
+	if verb == 'v' && f.Flag('+') {
+		_, _ = io.WriteString(f, "\nmain.run\n\t/app/main.go:12")
+	}
+}
+
+func TestErrorToGroup(t *testing.T) {
+	assert.Equal(t, s.Group{"msg", "boom", "type", "*errors.errorString"}, errorToGroup(errors.New("boom"), false, 0))
+
+	wrapped := fmt.Errorf("reading config: %w", &fs.PathError{Op: "open", Path: "/cfg", Err: fs.ErrNotExist})
+	assert.Equal(
+		t,
+		s.Group{
+			"msg", "reading config: open /cfg: file does not exist",
+			"type", "*fmt.wrapError",
+			"cause", s.Group{
+				"msg", "open /cfg: file does not exist",
+				"type", "*fs.PathError",
+				"cause", s.Group{"msg", "file does not exist", "type", "*errors.errorString"},
+			},
+		},
+		errorToGroup(wrapped, false, 0),
+	)
+
+	joined := errors.Join(errors.New("first"), nil, errors.New("second"))
+	assert.Equal(
+		t,
+		s.Group{
+			"msg", "first\nsecond",
+			"type", "*errors.joinError",
+			"causes", []any{
+				s.Group{"msg", "first", "type", "*errors.errorString"},
+				s.Group{"msg", "second", "type", "*errors.errorString"},
+			},
+		},
+		errorToGroup(joined, false, 0),
+	)
+}
+
+// wrapperError wraps the given cause without formatting it.
+type wrapperError struct {
+	cause error
+}
+
+func (e wrapperError) Error() string {
+	return "wrapper"
+}
+
+func (e wrapperError) Unwrap() error {
+	return e.cause
+}
+
+// joinedError wraps the given causes as errors.Join does, without formatting them.
+type joinedError struct {
+	causes []error
+}
+
+func (e joinedError) Error() string {
+	return "joined"
+}
+
+func (e joinedError) Unwrap() []error {
+	return e.causes
+}
+
+func TestErrorToGroup_TypedNilCause(t *testing.T) {
+	var nilCause *fs.PathError
+
+	assert.Equal(
+		t,
+		s.Group{"msg", "wrapper", "type", "services.wrapperError"},
+		errorToGroup(wrapperError{cause: nilCause}, false, 0),
+	)
+	assert.Equal(
+		t,
+		s.Group{
+			"msg", "joined",
+			"type", "services.joinedError",
+			"causes", []any{s.Group{"msg", "first", "type", "*errors.errorString"}},
+		},
+		errorToGroup(joinedError{causes: []error{errors.New("first"), nilCause}}, false, 0),
+	)
+
+	var buf bytes.Buffer
+	marshaler := NewJsonMarshaler()
+	marshaler.MarshalInto(&buf, JsonLogEntry{Message: "msg", Extras: []any{"err", wrapperError{cause: nilCause}}})
+	assert.Equal(t, `{"msg":"msg","extras":{"err":{"msg":"wrapper","type":"services.wrapperError"}}}`, buf.String())
+}
+
+func TestErrorToGroup_Stacktrace(t *testing.T) {
+	err := fmt.Errorf("handler: %w", &stackError{msg: "query", cause: &stackError{msg: "timeout"}})
+
+	assert.Equal(
+		t,
+		s.Group{
+			"msg", "handler: query: timeout",
+			"type", "*fmt.wrapError",
+			"cause", s.Group{
+				"msg", "query: timeout",
+				"type", "*services.stackError",
+				"stacktrace", "main.run\n\t/app/main.go:12",
+				"cause", s.Group{"msg", "timeout", "type", "*services.stackError"},
+			},
+		},
+		errorToGroup(err, false, 0),
+	)
+}
+
+func TestErrorToGroup_MaxDepth(t *testing.T) {
+	err := errors.New("root")
+	for i := 0; i < maxReflectDepth+10; i++ {
+		err = fmt.Errorf("wrap: %w", err)
+	}
+
+	depth := 0
+	for group := errorToGroup(err, false, 0); len(group) > 4; group = group[5].(s.Group) {
+		depth++
+	}
+
+	assert.Equal(t, maxReflectDepth, depth)
+}
+
+func TestMarshalers_ErrorValues(t *testing.T) {
+	err := fmt.Errorf("reading config: %w", errors.Join(errors.New("first"), errors.New("second")))
+	buf := &bytes.Buffer{}
+
+	jsonMarshaler := NewJsonMarshaler()
+	jsonMarshaler.MarshalInto(buf, JsonLogEntry{Message: "failed", Extras: []any{"err", err}})
+	assert.Equal(
+		t,
+		`{"msg":"failed","extras":{"err":{"msg":"reading config: first\nsecond","type":"*fmt.wrapError",`+
+			`"cause":{"msg":"first\nsecond","type":"*errors.joinError","causes":[`+
+			`{"msg":"first","type":"*errors.errorString"},{"msg":"second","type":"*errors.errorString"}]}}}}`,
+		buf.String(),
+	)
+
+	var jsonLog s.JsonLog
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &jsonLog))
+
+	buf.Reset()
+	yamlMarshaler := NewYamlMarshaler()
+	yamlMarshaler.MarshalInto(buf, YamlLogEntry{Message: "failed", Extras: []any{"err", err}})
+	assert.Equal(
+		t,
+		"msg: failed\n"+
+			"extras:\n"+
+			"  err:\n"+
+			"    msg: |-\n"+
+			"      reading config: first\n"+
+			"      second\n"+
+			"    type: \"*fmt.wrapError\"\n"+
+			"    cause:\n"+
+			"      msg: |-\n"+
+			"        first\n"+
+			"        second\n"+
+			"      type: \"*errors.joinError\"\n"+
+			"      causes:\n"+
+			"        - msg: first\n"+
+			"          type: \"*errors.errorString\"\n"+
+			"        - msg: second\n"+
+			"          type: \"*errors.errorString\"\n",
+		buf.String(),
+	)
+
+	var yamlLog s.YamlLog
+	assert.NoError(t, yaml.Unmarshal(buf.Bytes(), &yamlLog))
+	assert.Equal(t, "first\nsecond", yamlLog.Extras["err"].(map[string]any)["cause"].(map[string]any)["msg"])
+}
@@ -3,27 +3,54 @@ package services
 import (
 	"bytes"
 	"fmt"
+	"math"
+	"reflect"
 	"strconv"
+	"unicode/utf8"
+
+	s "github.com/Pho3b/tiny-logger/shared"
 )
 
 const (
 	jsonCharOverhead = 80
 	averageExtraLen  = 30
+	hexDigits        = "0123456789abcdef"
 )
 
+// jsonSafeSet and jsonHTMLSafeSet report whether an ASCII byte can be written in a JSON string without escaping.
+var jsonSafeSet, jsonHTMLSafeSet [utf8.RuneSelf]bool
+
+func init() {
+	for b := 0x20; b < utf8.RuneSelf; b++ {
+		jsonSafeSet[b] = b != '"' && b != '\\'
+		jsonHTMLSafeSet[b] = jsonSafeSet[b] && b != '<' && b != '>' && b != '&'
+	}
+}
+
 // JsonLogEntry represents a structured log entry that can be marshaled to JSON format.
 // All fields except Message are optional and will be omitted if empty.
 type JsonLogEntry struct {
-	Level   string `json:"level,omitempty"`
-	Date    string `json:"date,omitempty"`
-	Time    string `json:"time,omitempty"`
-	Message string `json:"msg"`
-	UnixTS  string `json:"unixTimestamp,omitempty"`
-	Extras  []any  `json:"extras,omitempty"`
+	Level      string `json:"level,omitempty"`
+	Date       string `json:"date,omitempty"`
+	Time       string `json:"time,omitempty"`
+	Caller     string `json:"caller,omitempty"`
+	Function   string `json:"func,omitempty"`
+	Message    string `json:"msg"`
+	UnixTS     string `json:"unixTimestamp,omitempty"`
+	Extras     []any  `json:"extras,omitempty"`
+	Stacktrace string `json:"stacktrace,omitempty"`
+	Fields     []byte `json:"-"`
 }
 
 // JsonMarshaler provides custom JSON marshaling functionality optimized for log entries.
 type JsonMarshaler struct {
+	escapeHTML bool
+}
+
+// SetEscapeHTML enables or disables the escaping of '<', '>' and '&' inside JSON strings,
+// making the output safe to be embedded in HTML.
+func (j *JsonMarshaler) SetEscapeHTML(enable bool) {
+	j.escapeHTML = enable
 }
 
 // MarshalInto converts a JsonLogEntry into a JSON-formatted byte slice and adds it to the given buffer
@@ -34,81 +61,187 @@ func (j *JsonMarshaler) MarshalInto(buf *bytes.Buffer, logEntry JsonLogEntry) {
 
 	buf.WriteByte('{')
 	j.writeLogEntryProperties(buf, logEntry.Level, logEntry.Date, logEntry.Time, logEntry.UnixTS)
+	j.writeCaller(buf, logEntry.Caller, logEntry.Function)
 
 	buf.WriteString("\"msg\":\"")
-	buf.WriteString(logEntry.Message)
+	j.writeEscapedString(buf, logEntry.Message)
 	buf.WriteByte('"')
 
-	if extrasLen > 0 {
+	if extrasLen > 0 || len(logEntry.Fields) > 0 {
 		buf.WriteString(",\"extras\":{")
+		buf.Write(logEntry.Fields)
 
-		for i := 0; i < extrasLen; i += 2 {
-			if i < extrasLen {
-				buf.WriteByte('"')
-				j.writeValue(buf, logEntry.Extras[i], true)
-				buf.WriteString(`":`)
+		if extrasLen > 0 && len(logEntry.Fields) > 0 {
+			buf.WriteByte(',')
+		}
 
-				k := i + 1
-				if k < extrasLen {
-					j.writeValue(buf, logEntry.Extras[k], false)
+		j.MarshalFieldsInto(buf, logEntry.Extras...)
+		buf.WriteByte('}')
+	}
 
-					if k < extrasLen-1 {
-						buf.WriteByte(',')
-					}
-				}
-			}
+	if logEntry.Stacktrace != "" {
+		buf.WriteString(",\"stacktrace\":\"")
+		j.writeEscapedString(buf, logEntry.Stacktrace)
+		buf.WriteByte('"')
+	}
+
+	buf.WriteByte('}')
+}
+
+// MarshalFieldsInto writes the given key/value pairs into the buffer as comma separated JSON object members.
+// A missing value for the last key is written as null.
+func (j *JsonMarshaler) MarshalFieldsInto(buf *bytes.Buffer, keyVals ...any) {
+	keyValsLen := len(keyVals)
+
+	for i := 0; i < keyValsLen; i += 2 {
+		if i > 0 {
+			buf.WriteByte(',')
 		}
 
-		if extrasLen%2 != 0 {
+		buf.WriteByte('"')
+		j.writeValue(buf, keyVals[i], true)
+		buf.WriteString(`":`)
+
+		if i+1 < keyValsLen {
+			j.writeValue(buf, keyVals[i+1], false)
+		} else {
 			buf.WriteString("null")
 		}
-
-		buf.WriteByte('}')
 	}
-
-	buf.WriteByte('}')
 }
 
 // writeValue writes a value to the buffer with appropriate JSON formatting.
-// The method handles different types (string, rune, int, int64, float64, bool)
+// The method handles the common types (string, int, int64, float64, bool, shared.Group) directly,
 // with special consideration for whether the value is being written as a key or value.
+// Any other value is encoded recursively through writeReflectValue, while keys are always escaped strings.
+// Runes are int32 values, so they are written as numbers, like encoding/json does.
 func (j *JsonMarshaler) writeValue(buf *bytes.Buffer, v any, isKey bool) {
 	switch val := v.(type) {
 	case string:
-		if isKey {
-			buf.WriteString(val)
-		} else {
-			buf.WriteByte('"')
-			buf.WriteString(val)
-			buf.WriteByte('"')
-		}
-	case rune:
-		if isKey {
-			buf.WriteRune(val)
-		} else {
-			buf.WriteByte('"')
-			buf.WriteRune(val)
-			buf.WriteByte('"')
-		}
+		j.writeQuotedString(buf, val, isKey)
 	case int:
 		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(val), 10))
 	case int64:
 		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), val, 10))
 	case float64:
-		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), val, 'f', -1, 64))
+		j.writeFloat(buf, val, 64, isKey)
 	case bool:
 		buf.Write(strconv.AppendBool(buf.AvailableBuffer(), val))
+	case s.Group:
+		if isKey {
+			j.writeEscapedString(buf, fmt.Sprint(val))
+			return
+		}
+
+		buf.WriteByte('{')
+		j.MarshalFieldsInto(buf, val...)
+		buf.WriteByte('}')
 	default:
 		if isKey {
-			buf.WriteString(fmt.Sprint(val))
-		} else {
-			buf.WriteByte('"')
-			buf.WriteString(fmt.Sprint(val))
-			buf.WriteByte('"')
+			j.writeEscapedString(buf, fmt.Sprint(val))
+			return
 		}
+
+		j.writeReflectValue(buf, reflect.ValueOf(val), 0)
 	}
 }
 
+// writeFloat writes the given float, quoting it when it is a key or when JSON cannot represent it (NaN, ±Inf).
+func (j *JsonMarshaler) writeFloat(buf *bytes.Buffer, val float64, bitSize int, isKey bool) {
+	if !isKey && (math.IsNaN(val) || math.IsInf(val, 0)) {
+		buf.WriteByte('"')
+		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), val, 'f', -1, bitSize))
+		buf.WriteByte('"')
+		return
+	}
+
+	buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), val, 'f', -1, bitSize))
+}
+
+// writeQuotedString writes the given string escaped and, when it is not a key, enclosed in double quotes.
+func (j *JsonMarshaler) writeQuotedString(buf *bytes.Buffer, str string, isKey bool) {
+	if isKey {
+		j.writeEscapedString(buf, str)
+		return
+	}
+
+	buf.WriteByte('"')
+	j.writeEscapedString(buf, str)
+	buf.WriteByte('"')
+}
+
+// writeEscapedString writes the given string into the buffer escaped as defined by RFC 8259.
+// Invalid UTF-8 sequences are replaced by U+FFFD, U+2028 and U+2029 are always escaped
+// and '<', '>', '&' are escaped too when the HTML-safe mode is enabled.
+// Strings that do not need escaping are written with a single copy.
+func (j *JsonMarshaler) writeEscapedString(buf *bytes.Buffer, str string) {
+	safeSet := &jsonSafeSet
+	if j.escapeHTML {
+		safeSet = &jsonHTMLSafeSet
+	}
+
+	start := 0
+
+	for i := 0; i < len(str); {
+		if b := str[i]; b < utf8.RuneSelf {
+			if safeSet[b] {
+				i++
+				continue
+			}
+
+			buf.WriteString(str[start:i])
+
+			switch b {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(b)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			case '\b':
+				buf.WriteString(`\b`)
+			case '\f':
+				buf.WriteString(`\f`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[b>>4])
+				buf.WriteByte(hexDigits[b&0xF])
+			}
+
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(str[i:])
+
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(str[start:i])
+			buf.WriteString("\ufffd")
+			i += size
+			start = i
+			continue
+		}
+
+		// U+2028 and U+2029 are valid JSON but break JavaScript parsers
+		if r == '\u2028' || r == '\u2029' {
+			buf.WriteString(str[start:i])
+			buf.WriteString(`\u202`)
+			buf.WriteByte(hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+
+		i += size
+	}
+
+	buf.WriteString(str[start:])
+}
+
 // writeLogEntryProperties writes the standard log entry properties to the buffer.
 // Only non-empty properties are written, each followed by a comma.
 func (j *JsonMarshaler) writeLogEntryProperties(
@@ -120,7 +253,7 @@ func (j *JsonMarshaler) writeLogEntryProperties(
 ) {
 	if level != "" {
 		buf.WriteString("\"level\":\"")
-		buf.WriteString(level)
+		j.writeEscapedString(buf, level)
 		buf.WriteByte('"')
 		buf.WriteByte(',')
 	}
@@ -160,6 +293,22 @@ func (j *JsonMarshaler) writeLogEntryProperties(
 	}
 }
 
+// writeCaller writes the caller location and function properties to the buffer, each followed by a comma.
+// Empty properties are not written.
+func (j *JsonMarshaler) writeCaller(buf *bytes.Buffer, caller string, function string) {
+	if caller != "" {
+		buf.WriteString("\"caller\":\"")
+		j.writeEscapedString(buf, caller)
+		buf.WriteString("\",")
+	}
+
+	if function != "" {
+		buf.WriteString("\"func\":\"")
+		j.writeEscapedString(buf, function)
+		buf.WriteString("\",")
+	}
+}
+
 func NewJsonMarshaler() JsonMarshaler {
 	return JsonMarshaler{}
 }
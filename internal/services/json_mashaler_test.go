@@ -36,7 +36,7 @@ func TestJsonMarshaler_Marshal_SomeFields(t *testing.T) {
 
 	m.MarshalInto(buf, entry)
 	got := buf.String()
-	want := `{"level":"warn","time":"12:34:56","msg":"something odd happened","extras":{"rune":":","int":23}}`
+	want := `{"level":"warn","time":"12:34:56","msg":"something odd happened","extras":{"rune":58,"int":23}}`
 	if got != want {
 		t.Errorf("Marshal() = %q, want %q", got, want)
 	}
@@ -75,7 +75,7 @@ func TestJsonMarshaler_Marshal_WithExtras(t *testing.T) {
 	m.MarshalInto(buf, entry)
 	got := buf.String()
 	want := "{\"level\":\"INFO\",\"datetime\":\"20/06/2025 08:11:06\",\"msg\":\"all systems go\"," +
-		"\"extras\":{\"bool\":true,\"int\":3,\"float\":4.3,\"arr\":\"[1 2 3]\",\"rune\":\"A\",\"string\":\"ciaooo\",\"null\":null}}"
+		"\"extras\":{\"bool\":true,\"int\":3,\"float\":4.3,\"arr\":[1,2,3],\"rune\":65,\"string\":\"ciaooo\",\"null\":null}}"
 	if got != want {
 		t.Errorf("got = %q, want %q", got, want)
 	}
@@ -114,6 +114,42 @@ func TestJsonMarshaler_Marshal_UnixTimestamp(t *testing.T) {
 	}
 }
 
+func TestJsonMarshaler_Marshal_Caller(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m := &JsonMarshaler{}
+	entry := JsonLogEntry{
+		Level:    "info",
+		UnixTS:   "1700000000",
+		Caller:   "logs/logger.go:42",
+		Function: "logs.(*Logger).Info",
+		Message:  "called",
+	}
+
+	m.MarshalInto(buf, entry)
+	got := buf.String()
+	want := `{"level":"info","ts":"1700000000","caller":"logs/logger.go:42","func":"logs.(*Logger).Info","msg":"called"}`
+	if got != want {
+		t.Errorf("Marshal() = %q, want %q", got, want)
+	}
+}
+
+func TestJsonMarshaler_Marshal_Stacktrace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m := &JsonMarshaler{}
+	entry := JsonLogEntry{
+		Message:    "failed",
+		Extras:     []any{"id", 3},
+		Stacktrace: "main.main\n\t/app/main.go:7",
+	}
+
+	m.MarshalInto(buf, entry)
+	got := buf.String()
+	want := `{"msg":"failed","extras":{"id":3},"stacktrace":"main.main\n\t/app/main.go:7"}`
+	if got != want {
+		t.Errorf("Marshal() = %q, want %q", got, want)
+	}
+}
+
 func TestJsonMarshaler_Marshal_OnlyDate(t *testing.T) {
 	buf := &bytes.Buffer{}
 	m := &JsonMarshaler{}
@@ -148,3 +184,69 @@ func TestJsonMarshaler_Marshal_OnlyTime(t *testing.T) {
 		t.Errorf("Marshal() = %q, want %q", got, want)
 	}
 }
+
+func TestJsonMarshaler_Marshal_Groups(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m := NewJsonMarshaler()
+	entry := JsonLogEntry{
+		Message: "groups",
+		Extras:  []any{"user", shared.Group{"name", "alice", "roles", shared.Group{"admin", true}}, "empty", shared.Group{}},
+	}
+
+	m.MarshalInto(buf, entry)
+	want := `{"msg":"groups","extras":{"user":{"name":"alice","roles":{"admin":true}},"empty":{}}}`
+	assert.Equal(t, want, buf.String())
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &shared.JsonLog{}))
+}
+
+func TestJsonMarshaler_Marshal_GroupKey(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m := NewJsonMarshaler()
+	m.MarshalInto(buf, JsonLogEntry{Message: "group key", Extras: []any{shared.Group{"quote\"d", 1}, "value"}})
+
+	assert.Equal(t, `{"msg":"group key","extras":{"{quote\"d=1}":"value"}}`, buf.String())
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &shared.JsonLog{}))
+}
+
+func TestJsonMarshaler_Marshal_EscapedStrings(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m := NewJsonMarshaler()
+	entry := JsonLogEntry{
+		Message: "quote \" backslash \\ newline \n tab \t bell \a line-sep \u2028",
+		Extras:  []any{"key\"with\nescapes", "<b>tags & more</b>", "invalid", "a\xffb", "emoji", "🙂"},
+	}
+
+	m.MarshalInto(buf, entry)
+	want := `{"msg":"quote \" backslash \\ newline \n tab \t bell \u0007 line-sep \u2028",` +
+		`"extras":{"key\"with\nescapes":"<b>tags & more</b>","invalid":"a�b","emoji":"🙂"}}`
+	assert.Equal(t, want, buf.String())
+
+	var decoded shared.JsonLog
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, entry.Message, decoded.Message)
+	assert.Equal(t, "a�b", decoded.Extras["invalid"])
+	assert.Equal(t, "<b>tags & more</b>", decoded.Extras["key\"with\nescapes"])
+}
+
+func TestJsonMarshaler_Marshal_EscapeHTML(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m := NewJsonMarshaler()
+	m.SetEscapeHTML(true)
+
+	m.MarshalInto(buf, JsonLogEntry{Message: "<script>", Extras: []any{"a&b", "x > y"}})
+	assert.Equal(t, `{"msg":"\u003cscript\u003e","extras":{"a\u0026b":"x \u003e y"}}`, buf.String())
+}
+
+func TestJsonMarshaler_Marshal_EscapingMatchesStdLib(t *testing.T) {
+	m := NewJsonMarshaler()
+	m.SetEscapeHTML(true)
+
+	for _, str := range []string{"", "plain ascii", "\x00\x1f\x7f", "ünïcödé", " ", "<&>", "\xc3\x28", "\"\\/"} {
+		buf := &bytes.Buffer{}
+		m.writeValue(buf, str, false)
+
+		want, err := json.Marshal(str)
+		assert.NoError(t, err)
+		assert.Equal(t, string(want), buf.String())
+	}
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+var jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+
+// writeReflectValue recursively writes the given value as JSON.
+// Maps, slices, arrays, pointers and structs are written as native JSON objects and arrays, honouring
+// the struct 'json' tags like encoding/json does. Nil values and values nested deeper than maxReflectDepth
+// are written as null, while the values that JSON cannot represent (channels, functions...) are written as strings.
+func (j *JsonMarshaler) writeReflectValue(buf *bytes.Buffer, rv reflect.Value, depth int) {
+	if !rv.IsValid() || depth > maxReflectDepth {
+		buf.WriteString("null")
+		return
+	}
+
+	kind := rv.Kind()
+
+	switch kind {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			buf.WriteString("null")
+			return
+		}
+	}
+
+	if rv.CanInterface() && isJsonSpecialType(rv.Type()) {
+		j.writeSpecialValue(buf, rv.Interface(), depth)
+		return
+	}
+
+	switch kind {
+	case reflect.String:
+		j.writeQuotedString(buf, rv.String(), false)
+	case reflect.Bool:
+		buf.Write(strconv.AppendBool(buf.AvailableBuffer(), rv.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), rv.Uint(), 10))
+	case reflect.Float32:
+		j.writeFloat(buf, rv.Float(), 32, false)
+	case reflect.Float64:
+		j.writeFloat(buf, rv.Float(), 64, false)
+	case reflect.Pointer, reflect.Interface:
+		j.writeReflectValue(buf, rv.Elem(), depth+1)
+	case reflect.Slice, reflect.Array:
+		j.writeReflectList(buf, rv, depth)
+	case reflect.Map:
+		j.writeReflectMap(buf, rv, depth)
+	case reflect.Struct:
+		j.writeReflectStruct(buf, rv, depth)
+	default:
+		j.writeQuotedString(buf, fmt.Sprint(rv), false)
+	}
+}
+
+// writeSpecialValue writes the values whose type is reported by isJsonSpecialType.
+// time.Time is written in RFC 3339 format and time.Duration as its string representation, while errors
+// not implementing any marshaler interface are written as objects describing their wrapped chain.
+func (j *JsonMarshaler) writeSpecialValue(buf *bytes.Buffer, v any, depth int) {
+	switch val := v.(type) {
+	case time.Time:
+		buf.WriteByte('"')
+		buf.Write(val.AppendFormat(buf.AvailableBuffer(), time.RFC3339Nano))
+		buf.WriteByte('"')
+	case time.Duration:
+		j.writeQuotedString(buf, val.String(), false)
+	case s.Group:
+		j.writeValue(buf, val, false)
+	case json.Marshaler:
+		marshaled, err := val.MarshalJSON()
+		if err == nil {
+			// Compact validates the marshaled JSON, leaving the buffer untouched on error
+			err = json.Compact(buf, marshaled)
+		}
+
+		if err != nil {
+			j.writeQuotedString(buf, "!marshal-error: "+err.Error(), false)
+		}
+	case encoding.TextMarshaler:
+		text, err := val.MarshalText()
+		if err != nil {
+			j.writeQuotedString(buf, "!marshal-error: "+err.Error(), false)
+			return
+		}
+
+		buf.WriteByte('"')
+		j.writeEscapedString(buf, string(text))
+		buf.WriteByte('"')
+	case error:
+		j.writeValue(buf, errorToGroup(val, false, 0), false)
+	default:
+		j.writeReflectValue(buf, reflect.ValueOf(val), depth+1)
+	}
+}
+
+// writeReflectList writes the given slice or array as a JSON array. Byte slices are written
+// as base64 strings, like encoding/json does.
+func (j *JsonMarshaler) writeReflectList(buf *bytes.Buffer, rv reflect.Value, depth int) {
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+		buf.WriteByte('"')
+		buf.Write(base64.StdEncoding.AppendEncode(buf.AvailableBuffer(), rv.Bytes()))
+		buf.WriteByte('"')
+		return
+	}
+
+	buf.WriteByte('[')
+
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		j.writeReflectValue(buf, rv.Index(i), depth+1)
+	}
+
+	buf.WriteByte(']')
+}
+
+// writeReflectMap writes the given map as a JSON object with its keys sorted, so that the output is deterministic.
+func (j *JsonMarshaler) writeReflectMap(buf *bytes.Buffer, rv reflect.Value, depth int) {
+	buf.WriteByte('{')
+
+	for i, entry := range sortedMapEntries(rv) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		buf.WriteByte('"')
+		j.writeEscapedString(buf, entry.key)
+		buf.WriteString("\":")
+		j.writeReflectValue(buf, entry.value, depth+1)
+	}
+
+	buf.WriteByte('}')
+}
+
+// writeReflectStruct writes the exported fields of the given struct as a JSON object.
+// Like encoding/json does, the "omitempty" option skips false, 0, nil pointers and interfaces, and empty
+// strings, arrays, slices and maps, while the "string" option writes the field value as a JSON string.
+func (j *JsonMarshaler) writeReflectStruct(buf *bytes.Buffer, rv reflect.Value, depth int) {
+	first := true
+	buf.WriteByte('{')
+
+	for _, field := range cachedStructFields(rv.Type(), "json") {
+		fieldValue, err := rv.FieldByIndexErr(field.index)
+		if err != nil || (field.omitEmpty && isEmptyJsonValue(fieldValue)) {
+			continue
+		}
+
+		if !first {
+			buf.WriteByte(',')
+		}
+
+		first = false
+		buf.WriteByte('"')
+		j.writeEscapedString(buf, field.name)
+		buf.WriteString("\":")
+
+		if field.quoted {
+			j.writeReflectQuoted(buf, fieldValue, depth+1)
+			continue
+		}
+
+		j.writeReflectValue(buf, fieldValue, depth+1)
+	}
+
+	buf.WriteByte('}')
+}
+
+// writeReflectQuoted writes the given value of a field with the "string" option as a JSON string,
+// quoting again the strings, like encoding/json does. Nil pointers and the values with a dedicated
+// representation are written as they are.
+func (j *JsonMarshaler) writeReflectQuoted(buf *bytes.Buffer, rv reflect.Value, depth int) {
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() == reflect.Pointer || (rv.CanInterface() && isJsonSpecialType(rv.Type())) {
+		j.writeReflectValue(buf, rv, depth)
+		return
+	}
+
+	if rv.Kind() == reflect.String {
+		var quoted bytes.Buffer
+		j.writeQuotedString(&quoted, rv.String(), false)
+		j.writeQuotedString(buf, quoted.String(), false)
+
+		return
+	}
+
+	buf.WriteByte('"')
+	j.writeReflectValue(buf, rv, depth)
+	buf.WriteByte('"')
+}
+
+// isEmptyJsonValue reports whether the given value is omitted by the "omitempty" option,
+// following the encoding/json rules.
+func isEmptyJsonValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return rv.IsZero()
+	}
+
+	return false
+}
+
+// isJsonSpecialType reports whether the values of the given type are not encoded by kind,
+// but have a dedicated representation.
+func isJsonSpecialType(t reflect.Type) bool {
+	return t == timeType || t == durationType || t == groupType ||
+		t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) || t.Implements(errorType)
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Pho3b/tiny-logger/shared"
+	"github.com/stretchr/testify/assert"
+)
+
+type reflectAddress struct {
+	Street string `json:"street"`
+	Zip    int    `json:"zip,omitempty"`
+}
+
+type reflectMeta struct {
+	Source string
+	hidden string
+}
+
+type reflectUser struct {
+	ID       int              `json:"id"`
+	Name     string           `json:"name"`
+	Password string           `json:"-"`
+	Address  *reflectAddress  `json:"address"`
+	Tags     []string         `json:"tags"`
+	Scores   map[string]uint8 `json:"scores,omitempty"`
+	Labels   map[int]string   `json:"labels"`
+	Nickname string           `json:",omitempty"`
+	internal string
+	*reflectMeta
+}
+
+type rawMarshaler struct{}
+
+func (rawMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{ "raw" : [1, 2] }`), nil
+}
+
+type invalidMarshaler struct{}
+
+func (invalidMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{"raw"`), nil
+}
+
+type failingTextMarshaler struct{}
+
+func (failingTextMarshaler) MarshalText() ([]byte, error) {
+	return nil, errors.New("boom")
+}
+
+type reflectNode struct {
+	Next *reflectNode
+}
+
+func marshalValue(v any) string {
+	buf := &bytes.Buffer{}
+	m := NewJsonMarshaler()
+	m.writeValue(buf, v, false)
+
+	return buf.String()
+}
+
+func TestJsonMarshaler_Reflect_Struct(t *testing.T) {
+	user := reflectUser{
+		ID:          1,
+		Name:        "alice",
+		Password:    "secret",
+		Address:     &reflectAddress{Street: "123 Go Lane"},
+		Tags:        []string{"admin", "dev"},
+		Labels:      map[int]string{10: "ten", 2: "two"},
+		internal:    "internal",
+		reflectMeta: &reflectMeta{Source: "api", hidden: "hidden"},
+	}
+
+	got := marshalValue(user)
+	assert.Equal(
+		t,
+		`{"id":1,"name":"alice","address":{"street":"123 Go Lane"},"tags":["admin","dev"],`+
+			`"labels":{"10":"ten","2":"two"},"Source":"api"}`,
+		got,
+	)
+
+	want, err := json.Marshal(user)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(want), got)
+}
+
+func TestJsonMarshaler_Reflect_OmitEmpty(t *testing.T) {
+	type options struct {
+		Tags    []string          `json:"tags,omitempty"`
+		Labels  map[string]string `json:"labels,omitempty"`
+		Array   [0]int            `json:"array,omitempty"`
+		Address reflectAddress    `json:"address,omitempty"`
+		When    time.Time         `json:"when,omitempty"`
+		Ratio   float64           `json:"ratio,omitempty"`
+		Any     any               `json:"any,omitempty"`
+	}
+
+	// Empty collections are omitted even if not nil, while structs are never omitted
+	value := options{Tags: []string{}, Labels: map[string]string{}}
+	got := marshalValue(value)
+	assert.Equal(t, `{"address":{"street":""},"when":"0001-01-01T00:00:00Z"}`, got)
+
+	want, err := json.Marshal(value)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(want), got)
+}
+
+func TestJsonMarshaler_Reflect_StringOption(t *testing.T) {
+	count := 3
+	type quoted struct {
+		ID      int64   `json:"id,string"`
+		Ratio   float64 `json:"ratio,string"`
+		Enabled bool    `json:"enabled,string"`
+		Name    string  `json:"name,string"`
+		Count   *int    `json:"count,string"`
+		Missing *int    `json:"missing,string"`
+		Tags    []int   `json:"tags,string"`
+		Zero    int     `json:"zero,string,omitempty"`
+	}
+
+	value := quoted{ID: 42, Ratio: 0.5, Enabled: true, Name: `a "b"`, Count: &count, Tags: []int{1}}
+	got := marshalValue(value)
+	assert.Equal(
+		t,
+		`{"id":"42","ratio":"0.5","enabled":"true","name":"\"a \\\"b\\\"\"","count":"3","missing":null,"tags":[1]}`,
+		got,
+	)
+
+	want, err := json.Marshal(value)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(want), got)
+}
+
+func TestJsonMarshaler_Reflect_NilValues(t *testing.T) {
+	var nilMap map[string]any
+	var nilPointer *reflectAddress
+
+	assert.Equal(t, "null", marshalValue(nil))
+	assert.Equal(t, "null", marshalValue(nilMap))
+	assert.Equal(t, "null", marshalValue(nilPointer))
+	assert.Equal(t, `{"id":0,"name":"","address":null,"tags":null,"labels":null}`, marshalValue(reflectUser{}))
+}
+
+func TestJsonMarshaler_Reflect_CollectionsAndPointers(t *testing.T) {
+	number := 42
+
+	assert.Equal(t, `[1,"two",{"three":3}]`, marshalValue([]any{1, "two", map[string]any{"three": 3}}))
+	assert.Equal(t, `[1.5,2.5]`, marshalValue([2]float32{1.5, 2.5}))
+	assert.Equal(t, `42`, marshalValue(&number))
+	assert.Equal(t, `"aGVsbG8="`, marshalValue([]byte("hello")))
+	assert.Equal(t, `{"a":{"b":["c"]}}`, marshalValue(map[string]map[string][]string{"a": {"b": {"c"}}}))
+	assert.Equal(t, `{"k\"ey":"v\nal"}`, marshalValue(map[string]string{"k\"ey": "v\nal"}))
+}
+
+func TestJsonMarshaler_Reflect_SpecialTypes(t *testing.T) {
+	ts := time.Date(2025, 6, 20, 8, 11, 6, 500, time.UTC)
+
+	assert.Equal(t, `"2025-06-20T08:11:06.0000005Z"`, marshalValue(ts))
+	assert.Equal(t, `"1.5s"`, marshalValue(1500*time.Millisecond))
+	assert.Equal(t, `{"raw":[1,2]}`, marshalValue(rawMarshaler{}))
+	assert.Equal(t, `"10.0.0.1"`, marshalValue(net.ParseIP("10.0.0.1")))
+	assert.Equal(t, `{"user":"alice"}`, marshalValue(shared.Group{"user", "alice"}))
+	assert.Equal(t, `[{"user":"alice"}]`, marshalValue([]shared.Group{{"user", "alice"}}))
+	assert.Equal(t, `{"at":"2025-06-20T08:11:06.0000005Z"}`, marshalValue(map[string]time.Time{"at": ts}))
+}
+
+func TestJsonMarshaler_Reflect_Unrepresentable(t *testing.T) {
+	node := &reflectNode{}
+	node.Next = node
+
+	assert.True(t, json.Valid([]byte(marshalValue(node))))
+	assert.True(t, json.Valid([]byte(marshalValue(func() {}))))
+	assert.Equal(t, `"NaN"`, marshalValue(math.NaN()))
+	assert.Equal(t, `"+Inf"`, marshalValue(float32(math.Inf(1))))
+	assert.Contains(t, marshalValue(invalidMarshaler{}), `"!marshal-error: `)
+	assert.Equal(t, `"!marshal-error: boom"`, marshalValue(failingTextMarshaler{}))
+}
+
+func TestJsonMarshaler_Reflect_Int32(t *testing.T) {
+	value := struct {
+		Code int32 `json:"code"`
+	}{Code: 65}
+
+	buf := &bytes.Buffer{}
+	m := NewJsonMarshaler()
+	m.MarshalInto(buf, JsonLogEntry{Message: "int32", Extras: []any{"code", int32(65), "nested", value, 'A', 'B'}})
+
+	// The int32 values, runes included, are written as numbers by both the direct and the reflection paths
+	assert.Equal(t, `{"msg":"int32","extras":{"code":65,"nested":{"code":65},"65":66}}`, buf.String())
+
+	expected, err := json.Marshal(value)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), string(expected))
+}
+
+func TestJsonMarshaler_Reflect_Extras(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m := NewJsonMarshaler()
+	m.MarshalInto(buf, JsonLogEntry{
+		Message: "nested",
+		Extras:  []any{"address", reflectAddress{Street: "Main", Zip: 1}, "ids", []int64{1, 2}},
+	})
+
+	assert.Equal(t, `{"msg":"nested","extras":{"address":{"street":"Main","zip":1},"ids":[1,2]}}`, buf.String())
+}
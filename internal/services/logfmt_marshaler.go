@@ -0,0 +1,408 @@
+package services
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+const logfmtCharOverhead = 50
+
+// LogfmtLogEntry represents a structured log entry that can be marshaled to logfmt format.
+// All fields except Message are optional and will be omitted if empty.
+type LogfmtLogEntry struct {
+	Level      string
+	Date       string
+	Time       string
+	UnixTS     string
+	Caller     string
+	Function   string
+	Message    string
+	Extras     []any
+	Stacktrace string
+	Fields     []byte
+}
+
+// LogfmtMarshaler provides custom logfmt marshaling functionality optimized for log entries.
+// Extras are written as flat key=value pairs: shared.Group keys are joined to their parent key
+// by a dot, while maps, slices and structs are written as quoted JSON values.
+type LogfmtMarshaler struct {
+	jsonMarshaler JsonMarshaler
+	scratchPool   *sync.Pool
+}
+
+// MarshalInto converts a LogfmtLogEntry into a logfmt-formatted byte slice and adds it to the given buffer
+// to minimize allocations during marshaling.
+func (l *LogfmtMarshaler) MarshalInto(buf *bytes.Buffer, logEntry LogfmtLogEntry) {
+	buf.Grow(logfmtCharOverhead + len(logEntry.Message) + (averageExtraLen * len(logEntry.Extras)))
+
+	l.writeLogEntryProperties(buf, logEntry.Level, logEntry.Date, logEntry.Time, logEntry.UnixTS)
+	l.writeCaller(buf, logEntry.Caller, logEntry.Function)
+
+	buf.WriteString("msg=")
+	l.writeString(buf, logEntry.Message)
+
+	if len(logEntry.Fields) > 0 {
+		buf.WriteByte(' ')
+		buf.Write(logEntry.Fields)
+	}
+
+	if len(logEntry.Extras) > 0 {
+		buf.WriteByte(' ')
+		l.MarshalFieldsInto(buf, logEntry.Extras...)
+	}
+
+	if logEntry.Stacktrace != "" {
+		buf.WriteString(" stacktrace=")
+		l.writeString(buf, logEntry.Stacktrace)
+	}
+}
+
+// MarshalFieldsInto writes the given key/value pairs into the buffer as space separated key=value pairs.
+// A missing value for the last key is written as null.
+func (l *LogfmtMarshaler) MarshalFieldsInto(buf *bytes.Buffer, keyVals ...any) {
+	l.writeKeyVals(buf, "", keyVals)
+}
+
+// writeKeyVals writes the given key/value pairs prefixing every key with the given prefix, if any.
+// shared.Group values are flattened, prefixing their keys with the group one.
+func (l *LogfmtMarshaler) writeKeyVals(buf *bytes.Buffer, prefix string, keyVals []any) {
+	keyValsLen := len(keyVals)
+	start := buf.Len()
+
+	for i := 0; i < keyValsLen; i += 2 {
+		if i+1 < keyValsLen {
+			if group, ok := keyVals[i+1].(s.Group); ok {
+				if len(group) > 0 {
+					if buf.Len() > start {
+						buf.WriteByte(' ')
+					}
+
+					l.writeKeyVals(buf, l.joinKey(prefix, keyVals[i]), group)
+				}
+
+				continue
+			}
+		}
+
+		if buf.Len() > start {
+			buf.WriteByte(' ')
+		}
+
+		if prefix != "" {
+			l.writeKey(buf, prefix)
+			buf.WriteByte('.')
+		}
+
+		l.writeKey(buf, l.keyToString(keyVals[i]))
+		buf.WriteByte('=')
+
+		if i+1 < keyValsLen {
+			l.writeValue(buf, keyVals[i+1])
+		} else {
+			buf.WriteString("null")
+		}
+	}
+}
+
+// writeValue writes the given value with appropriate logfmt formatting, quoting it only when needed.
+// Nil values, typed nil pointers implementing error, fmt.Stringer or encoding.TextMarshaler included,
+// are written as null.
+func (l *LogfmtMarshaler) writeValue(buf *bytes.Buffer, v any) {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		l.writeString(buf, val)
+	case rune:
+		l.writeString(buf, string(val))
+	case int:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(val), 10))
+	case int64:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), val, 10))
+	case float64:
+		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), val, 'f', -1, 64))
+	case bool:
+		buf.Write(strconv.AppendBool(buf.AvailableBuffer(), val))
+	case time.Time:
+		buf.Write(val.AppendFormat(buf.AvailableBuffer(), time.RFC3339Nano))
+	case time.Duration:
+		buf.WriteString(val.String())
+	case error:
+		if isNilPointer(val) {
+			buf.WriteString("null")
+			return
+		}
+
+		l.writeString(buf, val.Error())
+	case fmt.Stringer:
+		if isNilPointer(val) {
+			buf.WriteString("null")
+			return
+		}
+
+		l.writeString(buf, val.String())
+	case encoding.TextMarshaler:
+		if isNilPointer(val) {
+			buf.WriteString("null")
+			return
+		}
+
+		text, err := val.MarshalText()
+		if err != nil {
+			l.writeString(buf, "!marshal-error: "+err.Error())
+			return
+		}
+
+		l.writeString(buf, string(text))
+	default:
+		l.writeReflectValue(buf, reflect.ValueOf(val))
+	}
+}
+
+// writeReflectValue writes the values not handled by writeValue: numbers and strings of any kind are written
+// as they are, while maps, slices, arrays and structs are written as quoted JSON.
+func (l *LogfmtMarshaler) writeReflectValue(buf *bytes.Buffer, rv reflect.Value) {
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		l.writeString(buf, rv.String())
+	case reflect.Bool:
+		buf.Write(strconv.AppendBool(buf.AvailableBuffer(), rv.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), rv.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), rv.Float(), 'f', -1, rv.Type().Bits()))
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Pointer, reflect.Interface:
+		scratch := l.scratchPool.Get().(*bytes.Buffer)
+		l.jsonMarshaler.writeReflectValue(scratch, rv, 0)
+		l.writeString(buf, scratch.String())
+		scratch.Reset()
+		l.scratchPool.Put(scratch)
+	default:
+		l.writeString(buf, fmt.Sprint(rv))
+	}
+}
+
+// writeString writes the given string value, quoting and escaping it when it is empty or contains spaces,
+// '=', '"' or non-printable characters. Invalid UTF-8 sequences are replaced by U+FFFD.
+func (l *LogfmtMarshaler) writeString(buf *bytes.Buffer, str string) {
+	if !l.needsQuoting(str) {
+		buf.WriteString(str)
+		return
+	}
+
+	start := 0
+	buf.WriteByte('"')
+
+	for i := 0; i < len(str); {
+		if b := str[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != 0x7f {
+				i++
+				continue
+			}
+
+			buf.WriteString(str[start:i])
+
+			switch b {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(b)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[b>>4])
+				buf.WriteByte(hexDigits[b&0xF])
+			}
+
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(str[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(str[start:i])
+			buf.WriteRune(utf8.RuneError)
+			start = i + size
+		}
+
+		i += size
+	}
+
+	buf.WriteString(str[start:])
+	buf.WriteByte('"')
+}
+
+// writeKey writes the given key replacing the characters not allowed in logfmt keys
+// (spaces, '=', '"' and non-printable characters) with an underscore. Empty keys are written as "_".
+func (l *LogfmtMarshaler) writeKey(buf *bytes.Buffer, key string) {
+	if key == "" {
+		buf.WriteByte('_')
+		return
+	}
+
+	for i := 0; i < len(key); {
+		r, size := utf8.DecodeRuneInString(key[i:])
+
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || (r == utf8.RuneError && size == 1) {
+			buf.WriteByte('_')
+		} else {
+			buf.WriteString(key[i : i+size])
+		}
+
+		i += size
+	}
+}
+
+// writeLogEntryProperties writes the standard log entry properties to the buffer.
+// Only non-empty properties are written, the level in lowercase.
+func (l *LogfmtMarshaler) writeLogEntryProperties(
+	buf *bytes.Buffer,
+	level string,
+	date string,
+	time string,
+	unixTS string,
+) {
+	if level != "" {
+		buf.WriteString("level=")
+
+		for i := 0; i < len(level); i++ {
+			c := level[i]
+			if c >= 'A' && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+
+			buf.WriteByte(c)
+		}
+
+		buf.WriteByte(' ')
+	}
+
+	if unixTS != "" {
+		buf.WriteString("ts=")
+		buf.WriteString(unixTS)
+		buf.WriteByte(' ')
+
+		return
+	}
+
+	if date != "" && time != "" {
+		buf.WriteString("datetime=\"")
+		buf.WriteString(date)
+		buf.WriteByte(' ')
+		buf.WriteString(time)
+		buf.WriteString("\" ")
+
+		return
+	}
+
+	if date != "" {
+		buf.WriteString("date=")
+		l.writeString(buf, date)
+		buf.WriteByte(' ')
+	}
+
+	if time != "" {
+		buf.WriteString("time=")
+		l.writeString(buf, time)
+		buf.WriteByte(' ')
+	}
+}
+
+// writeCaller writes the caller location and function properties to the buffer, each followed by a space.
+// Empty properties are not written.
+func (l *LogfmtMarshaler) writeCaller(buf *bytes.Buffer, caller string, function string) {
+	if caller != "" {
+		buf.WriteString("caller=")
+		l.writeString(buf, caller)
+		buf.WriteByte(' ')
+	}
+
+	if function != "" {
+		buf.WriteString("func=")
+		l.writeString(buf, function)
+		buf.WriteByte(' ')
+	}
+}
+
+// needsQuoting checks if the given string value must be quoted in logfmt.
+func (l *LogfmtMarshaler) needsQuoting(str string) bool {
+	if str == "" {
+		return true
+	}
+
+	for i := 0; i < len(str); {
+		if b := str[i]; b < utf8.RuneSelf {
+			if b <= ' ' || b == '=' || b == '"' || b == '\\' || b == 0x7f {
+				return true
+			}
+
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(str[i:])
+		if (r == utf8.RuneError && size == 1) || r <= 0x9f || unicode.IsSpace(r) {
+			return true
+		}
+
+		i += size
+	}
+
+	return false
+}
+
+// keyToString returns the given key as a string.
+func (l *LogfmtMarshaler) keyToString(key any) string {
+	if str, ok := key.(string); ok {
+		return str
+	}
+
+	return fmt.Sprint(key)
+}
+
+// joinKey returns the given key prefixed by 'prefix' and a dot, if the prefix is not empty.
+func (l *LogfmtMarshaler) joinKey(prefix string, key any) string {
+	if prefix == "" {
+		return l.keyToString(key)
+	}
+
+	return prefix + "." + l.keyToString(key)
+}
+
+func NewLogfmtMarshaler() LogfmtMarshaler {
+	return LogfmtMarshaler{
+		jsonMarshaler: NewJsonMarshaler(),
+		scratchPool: &sync.Pool{
+			New: func() any {
+				return new(bytes.Buffer)
+			},
+		},
+	}
+}
+
+// isNilPointer reports whether the given value is a typed nil pointer, whose methods may panic when called.
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
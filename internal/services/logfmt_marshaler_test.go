@@ -0,0 +1,164 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Pho3b/tiny-logger/shared"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogfmtMarshaler_Marshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    LogfmtLogEntry
+		expected string
+	}{
+		{
+			name:     "message only",
+			entry:    LogfmtLogEntry{Message: "started"},
+			expected: "msg=started",
+		},
+		{
+			name:     "quoted message with level",
+			entry:    LogfmtLogEntry{Level: "INFO", Message: "server started"},
+			expected: `level=info msg="server started"`,
+		},
+		{
+			name:     "date and time",
+			entry:    LogfmtLogEntry{Level: "WARN", Date: "21/06/2025", Time: "11:34:56", Message: "disk"},
+			expected: `level=warn datetime="21/06/2025 11:34:56" msg=disk`,
+		},
+		{
+			name:     "only date",
+			entry:    LogfmtLogEntry{Date: "21/06/2025", Message: "disk"},
+			expected: `date=21/06/2025 msg=disk`,
+		},
+		{
+			name:     "unix timestamp",
+			entry:    LogfmtLogEntry{Level: "DEBUG", Date: "21/06/2025", UnixTS: "1715421234", Message: "tick"},
+			expected: `level=debug ts=1715421234 msg=tick`,
+		},
+		{
+			name: "caller and function",
+			entry: LogfmtLogEntry{
+				Level:    "INFO",
+				Caller:   "logs/logger.go:42",
+				Function: "logs.(*Logger).Info",
+				Message:  "called",
+			},
+			expected: `level=info caller=logs/logger.go:42 func=logs.(*Logger).Info msg=called`,
+		},
+		{
+			name:     "stacktrace",
+			entry:    LogfmtLogEntry{Message: "failed", Extras: []any{"id", 3}, Stacktrace: "main.main\n\tmain.go:7"},
+			expected: `msg=failed id=3 stacktrace="main.main\n\tmain.go:7"`,
+		},
+		{
+			name: "extras",
+			entry: LogfmtLogEntry{
+				Message: "request",
+				Extras:  []any{"id", 3, "path", "/api/v1", "ok", true, "ratio", 0.5, "char", 'x', "missing"},
+			},
+			expected: `msg=request id=3 path=/api/v1 ok=true ratio=0.5 char=x missing=null`,
+		},
+		{
+			name:     "bound fields and extras",
+			entry:    LogfmtLogEntry{Message: "bound", Fields: []byte("user=alice"), Extras: []any{"id", 3}},
+			expected: `msg=bound user=alice id=3`,
+		},
+		{
+			name:     "bound fields only",
+			entry:    LogfmtLogEntry{Message: "bound", Fields: []byte("user=alice")},
+			expected: `msg=bound user=alice`,
+		},
+	}
+
+	marshaler := NewLogfmtMarshaler()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			marshaler.MarshalInto(buf, tt.entry)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestLogfmtMarshaler_Quoting(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected string
+	}{
+		{"", `""`},
+		{"plain", `plain`},
+		{"ünïcödé", `ünïcödé`},
+		{"with space", `"with space"`},
+		{"a=b", `"a=b"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`back\slash`, `"back\\slash"`},
+		{"multi\nline\ttab\r", `"multi\nline\ttab\r"`},
+		{"bell\a", `"bell\u0007"`},
+		{"a\xffb", `"a�b"`},
+		{"non\u00a0breaking", "\"non\u00a0breaking\""},
+		{nil, `null`},
+		{errors.New("connection refused"), `"connection refused"`},
+		{1500 * time.Millisecond, `1.5s`},
+		{time.Date(2025, 6, 20, 8, 11, 6, 0, time.UTC), `2025-06-20T08:11:06Z`},
+		{uint8(7), `7`},
+		{float32(1.5), `1.5`},
+		{math.Inf(1), `+Inf`},
+		{map[string]any{"b": 1, "a": "x y"}, `"{\"a\":\"x y\",\"b\":1}"`},
+		{[]int{1, 2}, `[1,2]`},
+		{struct{ Name string }{"alice"}, `"{\"Name\":\"alice\"}"`},
+	}
+
+	marshaler := NewLogfmtMarshaler()
+
+	for _, tt := range tests {
+		buf := bytes.NewBuffer(nil)
+		marshaler.writeValue(buf, tt.value)
+		assert.Equal(t, tt.expected, buf.String())
+	}
+}
+
+func TestLogfmtMarshaler_Keys(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	marshaler := NewLogfmtMarshaler()
+	marshaler.MarshalFieldsInto(buf, "with space", 1, "a=b", 2, `"q"`, 3, "", 4, 42, 5)
+
+	assert.Equal(t, `with_space=1 a_b=2 _q_=3 _=4 42=5`, buf.String())
+}
+
+func TestLogfmtMarshaler_Groups(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	marshaler := NewLogfmtMarshaler()
+	marshaler.MarshalFieldsInto(
+		buf,
+		"empty", shared.Group{},
+		"user", shared.Group{"name", "alice", "roles", shared.Group{"admin", true}},
+		"id", 3,
+	)
+
+	assert.Equal(t, `user.name=alice user.roles.admin=true id=3`, buf.String())
+}
+
+// logfmtValueError is an error implemented on a value receiver, panicking when called on a nil pointer.
+type logfmtValueError struct{}
+
+func (logfmtValueError) Error() string {
+	return "value error"
+}
+
+func TestLogfmtMarshaler_TypedNil(t *testing.T) {
+	var nilTime *time.Time
+	var nilError *logfmtValueError
+	buf := bytes.NewBuffer(nil)
+	marshaler := NewLogfmtMarshaler()
+
+	marshaler.MarshalFieldsInto(buf, "time", nilTime, "err", nilError, "ok", logfmtValueError{})
+	assert.Equal(t, `time=null err=null ok="value error"`, buf.String())
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"bytes"
+	"io"
 	"os"
 
 	c "github.com/Pho3b/tiny-logger/logs/colors"
@@ -12,26 +13,23 @@ import (
 type Printer struct {
 }
 
-// PrintLog prints the given msgBuffer to the given outputType (stdout or stderr).
-// If 'file' is not nil AND (outType == FileOutput), the message is written to the file.
-func (p *Printer) PrintLog(outType s.OutputType, msgBuffer *bytes.Buffer, file *os.File) {
-	var err error
-
-	switch outType {
-	case s.StdOutput:
-		_, err = os.Stdout.Write(msgBuffer.Bytes())
-	case s.StdErrOutput:
-		_, err = os.Stderr.Write(msgBuffer.Bytes())
-	case s.FileOutput:
-		if file == nil {
+// PrintLog writes the given msgBuffer to the given 'out' writer.
+// When 'out' is nil, StdOutput and StdErrOutput fall back to os.Stdout and os.Stderr, while
+// FileOutput reports an error on stderr since a redirected output always requires a writer.
+func (p *Printer) PrintLog(outType s.OutputType, msgBuffer *bytes.Buffer, out io.Writer) {
+	if out == nil {
+		switch outType {
+		case s.StdOutput:
+			out = os.Stdout
+		case s.StdErrOutput:
+			out = os.Stderr
+		default:
 			_, _ = os.Stderr.Write([]byte("tiny-logger-err: given out file is nil"))
 			return
 		}
-
-		_, err = file.Write(msgBuffer.Bytes())
 	}
 
-	if err != nil {
+	if _, err := out.Write(msgBuffer.Bytes()); err != nil {
 		_, _ = os.Stderr.Write([]byte("tiny-logger-err: " + err.Error() + "\n"))
 	}
 }
@@ -53,6 +51,12 @@ func (p *Printer) RetrieveColorsFromLogLevel(enableColors bool, logLevelInt int8
 			res[0] = c.Cyan
 		case log_level.DebugLvl:
 			res[0] = c.Gray
+		case log_level.TraceLvl:
+			res[0] = c.DarkGray
+		case log_level.PanicLvl:
+			res[0] = c.Pink
+		default:
+			res[0], _ = log_level.RetrieveCustomLogLvlColor(logLevelInt)
 		}
 
 		res[1] = c.Reset
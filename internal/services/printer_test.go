@@ -77,6 +77,18 @@ func TestPrinter_PrintLog_WriteError_LogsToStderr(t *testing.T) {
 	assert.Contains(t, output, "tiny-logger-err:")
 }
 
+func TestPrinter_PrintLog_Writer(t *testing.T) {
+	p := NewPrinter()
+	var out bytes.Buffer
+
+	stdOut := test.CaptureOutput(func() {
+		p.PrintLog(s.StdOutput, bytes.NewBufferString("to writer"), &out)
+	})
+
+	assert.Empty(t, stdOut)
+	assert.Equal(t, "to writer", out.String())
+}
+
 func TestPrintColors_EnableColorsTrue(t *testing.T) {
 	printer := Printer{}
 
@@ -99,6 +111,19 @@ func TestPrintColors_EnableColorsTrue(t *testing.T) {
 	result = printer.RetrieveColorsFromLogLevel(true, log_level.DebugLvl)
 	assert.Equal(t, c.Gray, result[0], "Expected first element to be the provided color")
 	assert.Equal(t, c.Reset, result[1], "Expected second element to be the reset color")
+	result = printer.RetrieveColorsFromLogLevel(true, log_level.TraceLvl)
+	assert.Equal(t, c.DarkGray, result[0], "Expected first element to be the provided color")
+
+	result = printer.RetrieveColorsFromLogLevel(true, log_level.PanicLvl)
+	assert.Equal(t, c.Pink, result[0], "Expected first element to be the provided color")
+
+	result = printer.RetrieveColorsFromLogLevel(true, int8(-100))
+	assert.Equal(t, c.Color(""), result[0], "Expected no color for an unknown log level")
+	assert.Equal(t, c.Reset, result[1], "Expected second element to be the reset color")
+
+	assert.NoError(t, log_level.RegisterLogLvl("PRINTER_TEST", int8(-101), c.Green))
+	result = printer.RetrieveColorsFromLogLevel(true, int8(-101))
+	assert.Equal(t, c.Green, result[0], "Expected first element to be the custom level color")
 }
 
 func TestPrintColors_EnableColorsFalse(t *testing.T) {
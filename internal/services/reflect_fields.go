@@ -0,0 +1,169 @@
+package services
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+// maxReflectDepth is the maximum nesting level the marshalers encode, protecting them from cyclic values.
+const maxReflectDepth = 32
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	durationType      = reflect.TypeFor[time.Duration]()
+	groupType         = reflect.TypeFor[s.Group]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	errorType         = reflect.TypeFor[error]()
+
+	// structFieldsCache maps every encoded struct type and tag key pair to its []structField
+	structFieldsCache sync.Map
+)
+
+// structField describes a struct field encoded by the marshalers.
+type structField struct {
+	index     []int
+	name      string
+	omitEmpty bool
+	// quoted is set for the JSON fields with the "string" option, whose values are written as JSON strings
+	quoted bool
+}
+
+// structFieldsKey is the structFieldsCache key.
+type structFieldsKey struct {
+	t      reflect.Type
+	tagKey string
+}
+
+// mapEntry is a map entry whose key has already been converted to string.
+type mapEntry struct {
+	key   string
+	value reflect.Value
+}
+
+// sortedMapEntries returns the entries of the given map sorted by key, so that the output is deterministic.
+func sortedMapEntries(rv reflect.Value) []mapEntry {
+	entries := make([]mapEntry, 0, rv.Len())
+	iter := rv.MapRange()
+
+	for iter.Next() {
+		entries = append(entries, mapEntry{key: mapKeyString(iter.Key()), value: iter.Value()})
+	}
+
+	slices.SortFunc(entries, func(a, b mapEntry) int {
+		return strings.Compare(a.key, b.key)
+	})
+
+	return entries
+}
+
+// mapKeyString converts the given map key to string, like encoding/json does for string,
+// integer and encoding.TextMarshaler keys. Any other key is formatted through fmt.Sprint.
+func mapKeyString(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+
+	if key.CanInterface() {
+		if textMarshaler, ok := key.Interface().(encoding.TextMarshaler); ok {
+			if text, err := textMarshaler.MarshalText(); err == nil {
+				return string(text)
+			}
+		}
+	}
+
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10)
+	default:
+		return fmt.Sprint(key)
+	}
+}
+
+// cachedStructFields returns the encoded fields of the given struct type honouring the tags with the given key,
+// collecting and caching them on the first call.
+func cachedStructFields(t reflect.Type, tagKey string) []structField {
+	key := structFieldsKey{t: t, tagKey: tagKey}
+
+	if fields, found := structFieldsCache.Load(key); found {
+		return fields.([]structField)
+	}
+
+	fields, _ := structFieldsCache.LoadOrStore(key, collectStructFields(t, tagKey, nil, nil))
+
+	return fields.([]structField)
+}
+
+// collectStructFields appends to 'fields' the exported fields of the given struct type honouring the 'tagKey' tags:
+// fields tagged with "-" are skipped, the tag name replaces the field name and the "omitempty" option is supported,
+// like the JSON "string" option on the fields of string, boolean and numeric types (or pointers to them).
+// The fields of embedded structs without a tag name, or with the "inline" option, are promoted.
+// Untagged fields keep their name in JSON, while they are lowercased in YAML, like gopkg.in/yaml.v3 does.
+func collectStructFields(t reflect.Type, tagKey string, index []int, fields []structField) []structField {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get(tagKey)
+
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		opts = "," + opts + ","
+		fieldIndex := append(index[:len(index):len(index)], i)
+		fieldType := field.Type
+
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		promoted := (field.Anonymous && name == "") || strings.Contains(opts, ",inline,")
+		if promoted && fieldType.Kind() == reflect.Struct && len(fieldIndex) <= maxReflectDepth {
+			fields = collectStructFields(fieldType, tagKey, fieldIndex, fields)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+
+			if tagKey == "yaml" {
+				name = strings.ToLower(name)
+			}
+		}
+
+		fields = append(fields, structField{
+			index:     fieldIndex,
+			name:      name,
+			omitEmpty: strings.Contains(opts, ",omitempty,"),
+			quoted:    tagKey == "json" && strings.Contains(opts, ",string,") && isQuotableKind(fieldType.Kind()),
+		})
+	}
+
+	return fields
+}
+
+// isQuotableKind reports whether the JSON "string" option applies to the fields of the given kind.
+func isQuotableKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"sync/atomic"
+)
+
+const cacheLinePadSize = 64
+
+type ringSlot[T any] struct {
+	seq atomic.Uint64
+	val T
+}
+
+// RingBuffer is a bounded lock-free multi-producer multi-consumer queue.
+// Every slot carries a sequence number telling producers and consumers whether it is free or filled,
+// so that positions are claimed with a single CAS and no lock is ever taken.
+type RingBuffer[T any] struct {
+	_     [cacheLinePadSize]byte
+	head  atomic.Uint64
+	_     [cacheLinePadSize - 8]byte
+	tail  atomic.Uint64
+	_     [cacheLinePadSize - 8]byte
+	mask  uint64
+	slots []ringSlot[T]
+}
+
+// Push adds the given value to the buffer, returning false if the buffer is full.
+func (r *RingBuffer[T]) Push(v T) bool {
+	pos := r.tail.Load()
+
+	for {
+		slot := &r.slots[pos&r.mask]
+		diff := int64(slot.seq.Load()) - int64(pos)
+
+		switch {
+		case diff == 0:
+			if r.tail.CompareAndSwap(pos, pos+1) {
+				slot.val = v
+				slot.seq.Store(pos + 1)
+				return true
+			}
+
+			pos = r.tail.Load()
+		case diff < 0:
+			return false
+		default:
+			pos = r.tail.Load()
+		}
+	}
+}
+
+// Pop removes and returns the oldest value of the buffer, returning false if the buffer is empty.
+func (r *RingBuffer[T]) Pop() (T, bool) {
+	var zero T
+	pos := r.head.Load()
+
+	for {
+		slot := &r.slots[pos&r.mask]
+		diff := int64(slot.seq.Load()) - int64(pos+1)
+
+		switch {
+		case diff == 0:
+			if r.head.CompareAndSwap(pos, pos+1) {
+				v := slot.val
+				slot.val = zero
+				slot.seq.Store(pos + r.mask + 1)
+				return v, true
+			}
+
+			pos = r.head.Load()
+		case diff < 0:
+			return zero, false
+		default:
+			pos = r.head.Load()
+		}
+	}
+}
+
+// Len returns the number of values currently stored in the buffer.
+func (r *RingBuffer[T]) Len() int {
+	return int(r.tail.Load() - r.head.Load())
+}
+
+// Cap returns the buffer capacity.
+func (r *RingBuffer[T]) Cap() int {
+	return len(r.slots)
+}
+
+// NewRingBuffer initializes and returns a new RingBuffer whose capacity is the given one
+// rounded up to the next power of two.
+func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+	size := 1
+	for size < capacity {
+		size <<= 1
+	}
+
+	ring := &RingBuffer[T]{mask: uint64(size - 1), slots: make([]ringSlot[T], size)}
+	for i := range ring.slots {
+		ring.slots[i].seq.Store(uint64(i))
+	}
+
+	return ring
+}
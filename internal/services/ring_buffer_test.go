@@ -0,0 +1,81 @@
+package services
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRingBuffer_Capacity(t *testing.T) {
+	assert.Equal(t, 1, NewRingBuffer[int](0).Cap())
+	assert.Equal(t, 8, NewRingBuffer[int](8).Cap())
+	assert.Equal(t, 16, NewRingBuffer[int](9).Cap())
+}
+
+func TestRingBuffer_PushPop(t *testing.T) {
+	ring := NewRingBuffer[int](4)
+
+	_, ok := ring.Pop()
+	assert.False(t, ok)
+
+	for i := 0; i < 4; i++ {
+		assert.True(t, ring.Push(i))
+	}
+
+	assert.False(t, ring.Push(4))
+	assert.Equal(t, 4, ring.Len())
+
+	for i := 0; i < 4; i++ {
+		v, ok := ring.Pop()
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+
+	assert.Equal(t, 0, ring.Len())
+	assert.True(t, ring.Push(5))
+	v, _ := ring.Pop()
+	assert.Equal(t, 5, v)
+}
+
+func TestRingBuffer_Concurrency(t *testing.T) {
+	var wg sync.WaitGroup
+
+	ring := NewRingBuffer[int](64)
+	producers, perProducer := 8, 1000
+	seen := make(map[int]bool, producers*perProducer)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for count := 0; count < producers*perProducer; {
+			if v, ok := ring.Pop(); ok {
+				seen[v] = true
+				count++
+				continue
+			}
+
+			runtime.Gosched()
+		}
+	}()
+
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+
+		go func(p int) {
+			defer wg.Done()
+
+			for i := 0; i < perProducer; i++ {
+				for !ring.Push(p*perProducer + i) {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+
+	wg.Wait()
+	<-done
+	assert.Len(t, seen, producers*perProducer)
+}
@@ -2,21 +2,39 @@ package services
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"math"
+	"reflect"
 	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+const (
+	yamlCharOverhead = 70
+	// yamlIndicators are the characters a plain scalar cannot start with
+	yamlIndicators = "-?:,[]{}#&*!|>'\"%@`"
 )
 
-const yamlCharOverhead = 70
+// yamlAmbiguousScalars are the plain scalars resolved to booleans, null or special floats by YAML parsers.
+var yamlAmbiguousScalars = []string{"true", "false", "yes", "no", "on", "off", "y", "n", "null", "~", ".inf", "+.inf", ".nan"}
 
 // YamlLogEntry represents a structured log entry that can be marshaled to YAML format.
 // All fields except Message are optional and will be omitted if empty.
 type YamlLogEntry struct {
-	Level   string `yaml:"level,omitempty"`
-	Date    string `yaml:"date,omitempty"`
-	Time    string `yaml:"time,omitempty"`
-	UnixTS  string `yaml:"unixTimestamp,omitempty"`
-	Message string `yaml:"msg"`
-	Extras  []any  `yaml:"extras,omitempty"`
+	Level      string `yaml:"level,omitempty"`
+	Date       string `yaml:"date,omitempty"`
+	Time       string `yaml:"time,omitempty"`
+	UnixTS     string `yaml:"unixTimestamp,omitempty"`
+	Caller     string `yaml:"caller,omitempty"`
+	Function   string `yaml:"func,omitempty"`
+	Message    string `yaml:"msg"`
+	Extras     []any  `yaml:"extras,omitempty"`
+	Stacktrace string `yaml:"stacktrace,omitempty"`
+	Fields     []byte `yaml:"-"`
 }
 
 // YamlMarshaler provides custom YAML marshaling functionality optimized for log entries.
@@ -31,74 +49,308 @@ func (y *YamlMarshaler) MarshalInto(buf *bytes.Buffer, logEntry YamlLogEntry) {
 	buf.Grow(yamlCharOverhead + (averageExtraLen * extrasLen))
 
 	y.writeLogEntryProperties(buf, logEntry.Level, logEntry.Date, logEntry.Time, logEntry.UnixTS)
+	y.writeCaller(buf, logEntry.Caller, logEntry.Function)
 
-	buf.WriteString("msg: ")
-	buf.WriteString(logEntry.Message)
-	buf.WriteByte('\n')
+	buf.WriteString("msg:")
+	y.writeMessage(buf, logEntry.Message)
 
-	if extrasLen > 0 {
+	if extrasLen > 0 || len(logEntry.Fields) > 0 {
 		buf.WriteString("extras:\n")
+		buf.Write(logEntry.Fields)
+		y.MarshalFieldsInto(buf, logEntry.Extras...)
+	}
 
-		for i := 0; i < extrasLen; i += 2 {
-			buf.WriteString("  ")
+	if logEntry.Stacktrace != "" {
+		buf.WriteString("stacktrace:")
+		y.writeString(buf, logEntry.Stacktrace, 0)
+	}
+}
 
-			if i < extrasLen {
-				y.writeStr(buf, logEntry.Extras[i], true)
-				buf.WriteString(": ")
+// MarshalFieldsInto writes the given key/value pairs into the buffer as indented YAML mapping entries.
+// A missing value for the last key is written as null.
+func (y *YamlMarshaler) MarshalFieldsInto(buf *bytes.Buffer, keyVals ...any) {
+	y.writeKeyVals(buf, 1, false, keyVals)
+}
 
-				if i+1 < extrasLen {
-					y.writeStr(buf, logEntry.Extras[i+1], false)
-					buf.WriteByte('\n')
-				}
-			}
+// writeKeyVals writes the given key/value pairs as YAML mapping entries indented by the given depth.
+// When 'inline' is true the first entry is written on the current line, right after a sequence indicator.
+func (y *YamlMarshaler) writeKeyVals(buf *bytes.Buffer, depth int, inline bool, keyVals []any) {
+	keyValsLen := len(keyVals)
+
+	for i := 0; i < keyValsLen; i += 2 {
+		if !inline || i > 0 {
+			y.writeIndent(buf, depth)
 		}
 
-		if extrasLen%2 != 0 {
-			buf.WriteString("null")
-			buf.WriteByte('\n')
+		y.writeStr(buf, keyVals[i], true)
+		buf.WriteByte(':')
+
+		if i+1 >= keyValsLen {
+			buf.WriteString(" null\n")
+			continue
 		}
+
+		y.writeValue(buf, keyVals[i+1], depth, false)
 	}
 }
 
-// writeStr writes a string value to the buffer with appropriate YAML formatting.
-func (y *YamlMarshaler) writeStr(buf *bytes.Buffer, v any, isKey bool) {
+// writeValue writes the given value right after a mapping key or a sequence indicator at the given depth,
+// terminating it with a new line. shared.Group values are written as nested mappings, while any other
+// non-scalar value is written through writeReflectValue.
+// When 'isItem' is true the value is a sequence item, and nested collections start on the same line.
+func (y *YamlMarshaler) writeValue(buf *bytes.Buffer, v any, depth int, isItem bool) {
 	switch val := v.(type) {
 	case string:
-		if y.containsSpecialChars(val) {
-			buf.WriteByte('"')
-			buf.WriteString(val)
-			buf.WriteByte('"')
-		} else {
-			buf.WriteString(val)
+		y.writeString(buf, val, depth)
+	case s.Group:
+		y.writeGroup(buf, val, depth, isItem)
+	case rune, int, int64, float64, bool:
+		buf.WriteByte(' ')
+		y.writeStr(buf, val, false)
+		buf.WriteByte('\n')
+	default:
+		y.writeReflectValue(buf, reflect.ValueOf(val), depth, isItem, 0)
+	}
+}
+
+// writeGroup writes the given shared.Group as a nested mapping, or as {} when it is empty.
+func (y *YamlMarshaler) writeGroup(buf *bytes.Buffer, group s.Group, depth int, isItem bool) {
+	if len(group) == 0 {
+		buf.WriteString(" {}\n")
+		return
+	}
+
+	y.startCollection(buf, isItem)
+	y.writeKeyVals(buf, depth+1, isItem, group)
+}
+
+// startCollection writes what precedes the entries of a nested mapping or sequence: a space when
+// the collection starts on the sequence indicator line, a new line otherwise.
+func (y *YamlMarshaler) startCollection(buf *bytes.Buffer, isItem bool) {
+	if isItem {
+		buf.WriteByte(' ')
+	} else {
+		buf.WriteByte('\n')
+	}
+}
+
+// writeString writes the given string value, as a literal block scalar if it spans multiple lines.
+func (y *YamlMarshaler) writeString(buf *bytes.Buffer, str string, depth int) {
+	if y.isBlockScalarCandidate(str) {
+		y.writeBlockScalar(buf, str, depth)
+		return
+	}
+
+	buf.WriteByte(' ')
+	y.writeScalar(buf, str)
+	buf.WriteByte('\n')
+}
+
+// writeMessage writes the log message, quoting it only when a plain scalar would not be parsed back as the same string.
+func (y *YamlMarshaler) writeMessage(buf *bytes.Buffer, msg string) {
+	if y.isBlockScalarCandidate(msg) {
+		y.writeBlockScalar(buf, msg, 0)
+		return
+	}
+
+	buf.WriteByte(' ')
+
+	if y.isPlainSafe(msg) {
+		buf.WriteString(msg)
+	} else {
+		y.writeQuoted(buf, msg)
+	}
+
+	buf.WriteByte('\n')
+}
+
+// writeBlockScalar writes the given multi-line string as a literal block scalar ("|"), whose lines are indented
+// one level deeper than the given depth. The "strip" chomping indicator is used when there is no trailing new line.
+func (y *YamlMarshaler) writeBlockScalar(buf *bytes.Buffer, str string, depth int) {
+	content := strings.TrimSuffix(str, "\n")
+
+	buf.WriteString(" |")
+	if len(content) == len(str) {
+		buf.WriteByte('-')
+	}
+
+	buf.WriteByte('\n')
+
+	for line := range strings.SplitSeq(content, "\n") {
+		if line != "" {
+			y.writeIndent(buf, depth+1)
+			buf.WriteString(line)
 		}
+
+		buf.WriteByte('\n')
+	}
+}
+
+// writeStr writes a scalar value to the buffer with appropriate YAML formatting.
+func (y *YamlMarshaler) writeStr(buf *bytes.Buffer, v any, isKey bool) {
+	switch val := v.(type) {
+	case string:
+		y.writeScalar(buf, val)
 	case rune:
 		if isKey {
-			buf.WriteRune(val)
+			y.writeScalar(buf, string(val))
 		} else {
-			buf.WriteByte('"')
-			buf.WriteRune(val)
-			buf.WriteByte('"')
+			y.writeQuoted(buf, string(val))
 		}
 	case int:
 		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(val), 10))
 	case int64:
 		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), val, 10))
 	case float64:
-		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), val, 'f', -1, 64))
+		y.writeFloat(buf, val, 64)
 	case bool:
 		buf.Write(strconv.AppendBool(buf.AvailableBuffer(), val))
 	default:
-		// Check if the string representation needs quotes
-		str := fmt.Sprint(val)
+		y.writeScalar(buf, fmt.Sprint(val))
+	}
+}
 
-		if y.containsSpecialChars(str) {
-			buf.WriteByte('"')
-			buf.WriteString(str)
-			buf.WriteByte('"')
-		} else {
-			buf.WriteString(str)
+// writeScalar writes the given string as a plain scalar, or as a double-quoted one if it contains special characters
+// or if it would be parsed back as a different type (e.g. "true", "42", "null").
+func (y *YamlMarshaler) writeScalar(buf *bytes.Buffer, str string) {
+	if str == "" || y.containsSpecialChars(str) || containsNonPrintable(str) || isAmbiguousYamlScalar(str) {
+		y.writeQuoted(buf, str)
+		return
+	}
+
+	buf.WriteString(str)
+}
+
+// writeQuoted writes the given string as a double-quoted scalar, escaping double quotes, backslashes and
+// the non-printable characters. Invalid UTF-8 sequences are replaced by U+FFFD.
+func (y *YamlMarshaler) writeQuoted(buf *bytes.Buffer, str string) {
+	start := 0
+	buf.WriteByte('"')
+
+	for i := 0; i < len(str); {
+		if b := str[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != 0x7f && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+
+			buf.WriteString(str[start:i])
+
+			switch b {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(b)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				y.writeHexEscape(buf, rune(b))
+			}
+
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(str[i:])
+		if !isNonPrintableRune(r, size) {
+			i += size
+			continue
+		}
+
+		buf.WriteString(str[start:i])
+
+		switch {
+		case r == utf8.RuneError:
+			buf.WriteRune(utf8.RuneError)
+		case r == '\u2028':
+			buf.WriteString(`\L`)
+		case r == '\u2029':
+			buf.WriteString(`\P`)
+		case r == '\ufeff':
+			buf.WriteString(`\ufeff`)
+		default:
+			y.writeHexEscape(buf, r)
+		}
+
+		i += size
+		start = i
+	}
+
+	buf.WriteString(str[start:])
+	buf.WriteByte('"')
+}
+
+// writeHexEscape writes the given 8-bit character as a "\xNN" escape sequence.
+func (y *YamlMarshaler) writeHexEscape(buf *bytes.Buffer, r rune) {
+	buf.WriteString(`\x`)
+	buf.WriteByte(hexDigits[r>>4])
+	buf.WriteByte(hexDigits[r&0xF])
+}
+
+// writeFloat writes the given float, using the YAML notation for NaN and infinite values.
+func (y *YamlMarshaler) writeFloat(buf *bytes.Buffer, val float64, bitSize int) {
+	switch {
+	case math.IsNaN(val):
+		buf.WriteString(".nan")
+	case math.IsInf(val, 1):
+		buf.WriteString(".inf")
+	case math.IsInf(val, -1):
+		buf.WriteString("-.inf")
+	default:
+		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), val, 'f', -1, bitSize))
+	}
+}
+
+// writeIndent writes the indentation of the given depth.
+func (y *YamlMarshaler) writeIndent(buf *bytes.Buffer, depth int) {
+	for d := 0; d < depth; d++ {
+		buf.WriteString("  ")
+	}
+}
+
+// isBlockScalarCandidate reports whether the given string spans multiple lines and can be written as a literal
+// block scalar. Strings with non-printable characters, more than one trailing new line or a leading space on
+// their first line are double-quoted instead, since a block scalar could not represent them faithfully.
+func (y *YamlMarshaler) isBlockScalarCandidate(str string) bool {
+	if !strings.Contains(str, "\n") || strings.HasSuffix(str, "\n\n") {
+		return false
+	}
+
+	firstLine := strings.TrimLeft(str, "\n")
+	if firstLine == "" || firstLine[0] == ' ' {
+		return false
+	}
+
+	for i := 0; i < len(str); {
+		r, size := utf8.DecodeRuneInString(str[i:])
+		if r != '\n' && r != '\t' && isNonPrintableRune(r, size) {
+			return false
 		}
+
+		i += size
 	}
+
+	return true
+}
+
+// isPlainSafe reports whether the given string can be written as a plain scalar without being altered when parsed:
+// it must not start with an indicator character, contain ": " or " #" sequences, have leading or trailing spaces,
+// contain non-printable characters or be resolved to a different type.
+func (y *YamlMarshaler) isPlainSafe(str string) bool {
+	if str == "" || str[0] == ' ' || str[len(str)-1] == ' ' || str[len(str)-1] == ':' {
+		return false
+	}
+
+	if strings.ContainsRune(yamlIndicators, rune(str[0])) {
+		return false
+	}
+
+	return !strings.Contains(str, ": ") && !strings.Contains(str, " #") &&
+		!containsNonPrintable(str) && !isAmbiguousYamlScalar(str)
 }
 
 // writeLogEntryProperties writes the standard log entry properties to the buffer.
@@ -112,7 +364,7 @@ func (y *YamlMarshaler) writeLogEntryProperties(
 ) {
 	if level != "" {
 		buf.WriteString("level: ")
-		buf.WriteString(level)
+		y.writeScalar(buf, level)
 		buf.WriteByte('\n')
 	}
 
@@ -147,6 +399,22 @@ func (y *YamlMarshaler) writeLogEntryProperties(
 	}
 }
 
+// writeCaller writes the caller location and function properties to the buffer, each on its own line.
+// Empty properties are not written.
+func (y *YamlMarshaler) writeCaller(buf *bytes.Buffer, caller string, function string) {
+	if caller != "" {
+		buf.WriteString("caller: ")
+		y.writeScalar(buf, caller)
+		buf.WriteByte('\n')
+	}
+
+	if function != "" {
+		buf.WriteString("func: ")
+		y.writeScalar(buf, function)
+		buf.WriteByte('\n')
+	}
+}
+
 // containsSpecialChars checks if a string contains characters that require quoting in YAML
 func (y *YamlMarshaler) containsSpecialChars(s string) bool {
 	for _, c := range s {
@@ -158,11 +426,139 @@ func (y *YamlMarshaler) containsSpecialChars(s string) bool {
 	return false
 }
 
+// containsNonPrintable reports whether the given string contains characters that must be escaped in YAML.
+func containsNonPrintable(str string) bool {
+	for i := 0; i < len(str); {
+		r, size := utf8.DecodeRuneInString(str[i:])
+		if isNonPrintableRune(r, size) {
+			return true
+		}
+
+		i += size
+	}
+
+	return false
+}
+
+// isNonPrintableRune reports whether the given decoded rune must be escaped in a YAML scalar:
+// control characters, invalid UTF-8 sequences, line and paragraph separators and the byte order mark.
+func isNonPrintableRune(r rune, size int) bool {
+	return r < 0x20 || (r >= 0x7f && r <= 0x9f) || (r == utf8.RuneError && size == 1) ||
+		r == '\u2028' || r == '\u2029' || r == '\ufeff'
+}
+
+// isAmbiguousYamlScalar reports whether the given plain scalar would be resolved by a YAML parser
+// to a boolean, null, number or timestamp instead of a string.
+func isAmbiguousYamlScalar(str string) bool {
+	if len(str) <= 5 {
+		for _, ambiguous := range yamlAmbiguousScalars {
+			if strings.EqualFold(str, ambiguous) {
+				return true
+			}
+		}
+	}
+
+	if c := str[0]; (c < '0' || c > '9') && c != '.' && c != '+' && c != '-' {
+		return false
+	}
+
+	if isYamlTimestamp(str) {
+		return true
+	}
+
+	_, err := strconv.ParseFloat(str, 64)
+	if err == nil || errors.Is(err, strconv.ErrRange) {
+		return true
+	}
+
+	_, err = strconv.ParseInt(str, 0, 64)
+
+	return err == nil || errors.Is(err, strconv.ErrRange)
+}
+
+// isYamlTimestamp reports whether the given string matches the YAML timestamp format, either a date
+// like "2024-03-11" or a date and time like "2024-03-11T10:00:00Z" or "2024-03-11 10:00:00.5 +01:00".
+func isYamlTimestamp(str string) bool {
+	rest, ok := skipYamlDigits(str, true, 4, 4)
+	rest, ok = skipYamlByte(rest, ok, '-')
+	rest, ok = skipYamlDigits(rest, ok, 1, 2)
+	rest, ok = skipYamlByte(rest, ok, '-')
+
+	if rest, ok = skipYamlDigits(rest, ok, 1, 2); !ok || rest == "" {
+		return ok
+	}
+
+	// The date and the time are separated by a 'T' or by white spaces
+	if rest[0] == 'T' || rest[0] == 't' {
+		rest = rest[1:]
+	} else {
+		trimmed := strings.TrimLeft(rest, " \t")
+		if len(trimmed) == len(rest) {
+			return false
+		}
+
+		rest = trimmed
+	}
+
+	rest, ok = skipYamlDigits(rest, true, 1, 2)
+	rest, ok = skipYamlByte(rest, ok, ':')
+	rest, ok = skipYamlDigits(rest, ok, 2, 2)
+	rest, ok = skipYamlByte(rest, ok, ':')
+
+	if rest, ok = skipYamlDigits(rest, ok, 2, 2); !ok {
+		return false
+	}
+
+	if rest != "" && rest[0] == '.' {
+		rest = strings.TrimLeft(rest[1:], "0123456789")
+	}
+
+	// The optional time zone is either 'Z' or a numeric offset, possibly preceded by white spaces
+	switch rest = strings.TrimLeft(rest, " \t"); {
+	case rest == "" || rest == "Z":
+		return true
+	case rest[0] == '+' || rest[0] == '-':
+		if rest, ok = skipYamlDigits(rest[1:], true, 1, 2); ok && rest != "" {
+			rest, ok = skipYamlByte(rest, ok, ':')
+			rest, ok = skipYamlDigits(rest, ok, 2, 2)
+		}
+
+		return ok && rest == ""
+	default:
+		return false
+	}
+}
+
+// skipYamlDigits returns the given string without its leading digits, up to maxDigits, reporting whether they
+// are at least minDigits. Like skipYamlByte, it is a no-op returning false if the previous step failed.
+func skipYamlDigits(str string, ok bool, minDigits, maxDigits int) (string, bool) {
+	if !ok {
+		return str, false
+	}
+
+	i := 0
+	for i < len(str) && i < maxDigits && str[i] >= '0' && str[i] <= '9' {
+		i++
+	}
+
+	return str[i:], i >= minDigits
+}
+
+// skipYamlByte returns the given string without its leading byte, reporting whether it is the expected one.
+// It is a no-op returning false if the previous step failed, so that the steps can be chained.
+func skipYamlByte(str string, ok bool, expected byte) (string, bool) {
+	if !ok || str == "" || str[0] != expected {
+		return str, false
+	}
+
+	return str[1:], true
+}
+
 func NewYamlMarshaler() YamlMarshaler {
 	return YamlMarshaler{
 		specialCharsSet: map[rune]any{':': nil, '{': nil, '}': nil, '[': nil, ']': nil, ',': nil, '&': nil,
 			'*': nil, '#': nil, '?': nil, '|': nil, '-': nil, '<': nil, '>': nil, '=': nil, '!': nil, '%': nil,
-			'@': nil, '`': nil, ' ': nil,
+			'@': nil, '`': nil, ' ': nil, '"': nil, '\'': nil,
 		},
 	}
 }
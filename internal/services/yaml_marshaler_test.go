@@ -2,7 +2,12 @@ package services
 
 import (
 	"bytes"
+	"strings"
 	"testing"
+
+	"github.com/Pho3b/tiny-logger/shared"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
 )
 
 func TestYamlMarshaler_Marshal(t *testing.T) {
@@ -26,6 +31,25 @@ func TestYamlMarshaler_Marshal(t *testing.T) {
 			},
 			expected: "level: INFO\nmsg: test message\n",
 		},
+		{
+			name: "message with caller",
+			entry: YamlLogEntry{
+				Level:    "INFO",
+				Caller:   "logs/logger.go:42",
+				Function: "logs.(*Logger).Info",
+				Message:  "test message",
+			},
+			expected: "level: INFO\ncaller: \"logs/logger.go:42\"\nfunc: \"logs.(*Logger).Info\"\nmsg: test message\n",
+		},
+		{
+			name: "message with stacktrace",
+			entry: YamlLogEntry{
+				Message:    "failed",
+				Extras:     []any{"id", 3},
+				Stacktrace: "main.main\n\t/app/main.go:7",
+			},
+			expected: "msg: failed\nextras:\n  id: 3\nstacktrace: |-\n  main.main\n  \t/app/main.go:7\n",
+		},
 		{
 			name: "full log entry",
 			entry: YamlLogEntry{
@@ -215,3 +239,120 @@ func TestYamlMarshaler_Marshal_OnlyTime(t *testing.T) {
 		t.Errorf("Marshal() = %q, want %q", got, want)
 	}
 }
+
+func TestYamlMarshaler_Marshal_Groups(t *testing.T) {
+	buf := &bytes.Buffer{}
+	m := NewYamlMarshaler()
+	entry := YamlLogEntry{
+		Message: "groups",
+		Extras:  []any{"user", shared.Group{"name", "alice", "roles", shared.Group{"admin", true}}, "empty", shared.Group{}},
+	}
+
+	m.MarshalInto(buf, entry)
+	want := "msg: groups\nextras:\n  user:\n    name: alice\n    roles:\n      admin: true\n  empty: {}\n"
+	assert.Equal(t, want, buf.String())
+
+	var yamlLog shared.YamlLog
+	assert.NoError(t, yaml.Unmarshal(buf.Bytes(), &yamlLog))
+	assert.Equal(t, map[string]any{"name": "alice", "roles": map[string]any{"admin": true}}, yamlLog.Extras["user"])
+}
+
+func TestYamlMarshaler_Marshal_QuotedStringsRoundTrip(t *testing.T) {
+	values := []string{
+		`say "hi"`, `back\slash`, "tab\tand\rreturn", "bell\a", "", " padded ", "true", "No", "null", "~", "42",
+		"-1.5e3", "0x1F", ".inf", "key: value", "# comment", "'single'", "a\xffb", "line\u2028sep", "bom\ufeff", "plain",
+		"2024-03-11", "2024-03-11T10:00:00Z", "2024-3-1 10:00:00.123 +01:00",
+	}
+	marshaler := NewYamlMarshaler()
+
+	for _, value := range values {
+		buf := bytes.NewBuffer(nil)
+		marshaler.MarshalInto(buf, YamlLogEntry{Message: value, Extras: []any{value, value}})
+
+		var yamlLog shared.YamlLog
+		assert.NoError(t, yaml.Unmarshal(buf.Bytes(), &yamlLog), buf.String())
+
+		expected := strings.ToValidUTF8(value, "�")
+		assert.Equal(t, expected, yamlLog.Message, buf.String())
+		assert.Equal(t, expected, yamlLog.Extras[expected], buf.String())
+	}
+}
+
+func TestYamlMarshaler_Marshal_QuotedStrings(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	marshaler := NewYamlMarshaler()
+	marshaler.MarshalInto(buf, YamlLogEntry{
+		Message: "true",
+		Extras:  []any{"quote", `say "hi"`, "bell", "a\ab", "count", "42", "empty", ""},
+	})
+
+	want := "msg: \"true\"\nextras:\n  quote: \"say \\\"hi\\\"\"\n  bell: \"a\\x07b\"\n  count: \"42\"\n  empty: \"\"\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestYamlMarshaler_Marshal_Timestamps(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	marshaler := NewYamlMarshaler()
+	marshaler.MarshalInto(buf, YamlLogEntry{
+		Message: "2024-03-11T10:00:00Z",
+		Extras:  []any{"day", "2024-03-11", "at", "2024-03-11 10:00:00.5 -05"},
+	})
+
+	want := "msg: \"2024-03-11T10:00:00Z\"\nextras:\n  day: \"2024-03-11\"\n  at: \"2024-03-11 10:00:00.5 -05\"\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestIsYamlTimestamp(t *testing.T) {
+	timestamps := []string{
+		"2024-03-11", "2024-3-1", "2024-03-11T10:00:00", "2024-03-11t10:00:00Z", "2024-03-11 10:00:00",
+		"2024-03-11\t 1:02:03.456789", "2024-03-11T10:00:00.5Z", "2024-03-11T10:00:00+01:00",
+		"2024-03-11T10:00:00 -5", "2024-03-11 10:00:00 Z",
+	}
+	for _, str := range timestamps {
+		assert.True(t, isYamlTimestamp(str), str)
+	}
+
+	others := []string{
+		"2024", "2024-03", "24-03-11", "2024-03-111", "2024/03/11", "2024-03-11T", "2024-03-11 10:00",
+		"2024-03-11T10:00:0", "2024-03-11T10:00:00X", "2024-03-11T10:00:00+01:0", "2024-03-11x10:00:00",
+		"2024-03-11-rc1", "20240311",
+	}
+	for _, str := range others {
+		assert.False(t, isYamlTimestamp(str), str)
+	}
+}
+
+func TestYamlMarshaler_Marshal_BlockScalars(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	marshaler := NewYamlMarshaler()
+	stackTrace := "goroutine 1 [running]:\nmain.main()\n\t/app/main.go:12 +0x1d\n"
+	marshaler.MarshalInto(buf, YamlLogEntry{
+		Message: "first line\n\nthird line: with colon",
+		Extras:  []any{"stack", stackTrace, "nested", shared.Group{"text", "a\nb"}},
+	})
+
+	want := "msg: |-\n  first line\n\n  third line: with colon\n" +
+		"extras:\n  stack: |\n    goroutine 1 [running]:\n    main.main()\n    \t/app/main.go:12 +0x1d\n" +
+		"  nested:\n    text: |-\n      a\n      b\n"
+	assert.Equal(t, want, buf.String())
+
+	var yamlLog shared.YamlLog
+	assert.NoError(t, yaml.Unmarshal(buf.Bytes(), &yamlLog))
+	assert.Equal(t, "first line\n\nthird line: with colon", yamlLog.Message)
+	assert.Equal(t, stackTrace, yamlLog.Extras["stack"])
+	assert.Equal(t, map[string]any{"text": "a\nb"}, yamlLog.Extras["nested"])
+}
+
+func TestYamlMarshaler_Marshal_MultiLineFallbacks(t *testing.T) {
+	marshaler := NewYamlMarshaler()
+
+	for _, value := range []string{"two trailing\n\n", "  leading space\nline", "\n", "ctrl\x01\nline"} {
+		buf := bytes.NewBuffer(nil)
+		marshaler.MarshalInto(buf, YamlLogEntry{Message: value})
+		assert.NotContains(t, buf.String(), "|")
+
+		var yamlLog shared.YamlLog
+		assert.NoError(t, yaml.Unmarshal(buf.Bytes(), &yamlLog))
+		assert.Equal(t, value, yamlLog.Message)
+	}
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+// writeReflectValue recursively writes the given value right after a mapping key or a sequence indicator.
+// Maps and structs, honouring their 'yaml' tags, are written as indented mappings, while slices and arrays
+// as sequences. Nil values and values nested deeper than maxReflectDepth are written as null, while
+// the values that YAML cannot represent (channels, functions...) are written as strings.
+func (y *YamlMarshaler) writeReflectValue(buf *bytes.Buffer, rv reflect.Value, depth int, isItem bool, nesting int) {
+	if !rv.IsValid() || nesting > maxReflectDepth {
+		buf.WriteString(" null\n")
+		return
+	}
+
+	kind := rv.Kind()
+
+	switch kind {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			buf.WriteString(" null\n")
+			return
+		}
+	}
+
+	if rv.CanInterface() && isYamlSpecialType(rv.Type()) {
+		y.writeSpecialValue(buf, rv.Interface(), depth, isItem, nesting)
+		return
+	}
+
+	switch kind {
+	case reflect.String:
+		y.writeString(buf, rv.String(), depth)
+	case reflect.Bool:
+		buf.WriteByte(' ')
+		buf.Write(strconv.AppendBool(buf.AvailableBuffer(), rv.Bool()))
+		buf.WriteByte('\n')
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteByte(' ')
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), rv.Int(), 10))
+		buf.WriteByte('\n')
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		buf.WriteByte(' ')
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), rv.Uint(), 10))
+		buf.WriteByte('\n')
+	case reflect.Float32, reflect.Float64:
+		buf.WriteByte(' ')
+		y.writeFloat(buf, rv.Float(), rv.Type().Bits())
+		buf.WriteByte('\n')
+	case reflect.Pointer, reflect.Interface:
+		y.writeReflectValue(buf, rv.Elem(), depth, isItem, nesting+1)
+	case reflect.Slice, reflect.Array:
+		y.writeReflectSequence(buf, rv, depth, isItem, nesting)
+	case reflect.Map:
+		y.writeReflectMap(buf, rv, depth, isItem, nesting)
+	case reflect.Struct:
+		y.writeReflectStruct(buf, rv, depth, isItem, nesting)
+	default:
+		y.writeString(buf, fmt.Sprint(rv), depth)
+	}
+}
+
+// writeSpecialValue writes the values whose type is reported by isYamlSpecialType.
+// time.Time is written in RFC 3339 format and time.Duration as its string representation, while errors
+// not implementing encoding.TextMarshaler are written as mappings describing their wrapped chain.
+func (y *YamlMarshaler) writeSpecialValue(buf *bytes.Buffer, v any, depth int, isItem bool, nesting int) {
+	switch val := v.(type) {
+	case time.Time:
+		buf.WriteByte(' ')
+		y.writeQuoted(buf, val.Format(time.RFC3339Nano))
+		buf.WriteByte('\n')
+	case time.Duration:
+		y.writeString(buf, val.String(), depth)
+	case s.Group:
+		y.writeGroup(buf, val, depth, isItem)
+	case encoding.TextMarshaler:
+		text, err := val.MarshalText()
+		if err != nil {
+			y.writeString(buf, "!marshal-error: "+err.Error(), depth)
+			return
+		}
+
+		y.writeString(buf, string(text), depth)
+	case error:
+		y.writeGroup(buf, errorToGroup(val, false, 0), depth, isItem)
+	default:
+		y.writeReflectValue(buf, reflect.ValueOf(val), depth, isItem, nesting+1)
+	}
+}
+
+// writeReflectSequence writes the given slice or array as a YAML sequence, or as [] when it is empty.
+// Byte slices are written as base64 !!binary scalars.
+func (y *YamlMarshaler) writeReflectSequence(buf *bytes.Buffer, rv reflect.Value, depth int, isItem bool, nesting int) {
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+		buf.WriteString(" !!binary ")
+		buf.Write(base64.StdEncoding.AppendEncode(buf.AvailableBuffer(), rv.Bytes()))
+		buf.WriteByte('\n')
+		return
+	}
+
+	if rv.Len() == 0 {
+		buf.WriteString(" []\n")
+		return
+	}
+
+	y.startCollection(buf, isItem)
+
+	for i := 0; i < rv.Len(); i++ {
+		if !isItem || i > 0 {
+			y.writeIndent(buf, depth+1)
+		}
+
+		buf.WriteByte('-')
+		y.writeReflectValue(buf, rv.Index(i), depth+1, true, nesting+1)
+	}
+}
+
+// writeReflectMap writes the given map as a YAML mapping with its keys sorted, or as {} when it is empty.
+func (y *YamlMarshaler) writeReflectMap(buf *bytes.Buffer, rv reflect.Value, depth int, isItem bool, nesting int) {
+	if rv.Len() == 0 {
+		buf.WriteString(" {}\n")
+		return
+	}
+
+	y.startCollection(buf, isItem)
+
+	for i, entry := range sortedMapEntries(rv) {
+		y.writeReflectEntry(buf, entry.key, entry.value, depth+1, isItem && i == 0, nesting)
+	}
+}
+
+// writeReflectStruct writes the exported fields of the given struct as a YAML mapping, or as {} when none is written.
+func (y *YamlMarshaler) writeReflectStruct(buf *bytes.Buffer, rv reflect.Value, depth int, isItem bool, nesting int) {
+	first := true
+
+	for _, field := range cachedStructFields(rv.Type(), "yaml") {
+		fieldValue, err := rv.FieldByIndexErr(field.index)
+		if err != nil || (field.omitEmpty && fieldValue.IsZero()) {
+			continue
+		}
+
+		if first {
+			y.startCollection(buf, isItem)
+		}
+
+		y.writeReflectEntry(buf, field.name, fieldValue, depth+1, isItem && first, nesting)
+		first = false
+	}
+
+	if first {
+		buf.WriteString(" {}\n")
+	}
+}
+
+// writeReflectEntry writes a single mapping entry at the given depth.
+func (y *YamlMarshaler) writeReflectEntry(
+	buf *bytes.Buffer,
+	key string,
+	value reflect.Value,
+	depth int,
+	inline bool,
+	nesting int,
+) {
+	if !inline {
+		y.writeIndent(buf, depth)
+	}
+
+	y.writeScalar(buf, key)
+	buf.WriteByte(':')
+	y.writeReflectValue(buf, value, depth, false, nesting+1)
+}
+
+// isYamlSpecialType reports whether the values of the given type are not encoded by kind,
+// but have a dedicated representation.
+func isYamlSpecialType(t reflect.Type) bool {
+	return t == timeType || t == durationType || t == groupType ||
+		t.Implements(textMarshalerType) || t.Implements(errorType)
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"bytes"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Pho3b/tiny-logger/shared"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+type yamlAddress struct {
+	Street string `yaml:"street"`
+	Zip    int    `yaml:"zip,omitempty"`
+}
+
+type yamlUser struct {
+	ID       int
+	Name     string            `yaml:"name"`
+	Password string            `yaml:"-"`
+	Address  *yamlAddress      `yaml:"address"`
+	Tags     []string          `yaml:"tags"`
+	Labels   map[string]string `yaml:"labels,omitempty"`
+	Base     yamlAddress       `yaml:",inline"`
+	internal string
+}
+
+func marshalYamlExtras(keyVals ...any) string {
+	buf := &bytes.Buffer{}
+	m := NewYamlMarshaler()
+	m.MarshalFieldsInto(buf, keyVals...)
+
+	return buf.String()
+}
+
+func TestYamlMarshaler_Reflect_Struct(t *testing.T) {
+	user := yamlUser{
+		ID:       1,
+		Name:     "alice",
+		Password: "secret",
+		Address:  &yamlAddress{Street: "123 Go Lane"},
+		Tags:     []string{"admin", "dev ops"},
+		Base:     yamlAddress{Street: "Main", Zip: 10},
+		internal: "internal",
+	}
+
+	got := marshalYamlExtras("user", user)
+	want := "  user:\n    id: 1\n    name: alice\n    address:\n      street: \"123 Go Lane\"\n" +
+		"    tags:\n      - admin\n      - \"dev ops\"\n    street: Main\n    zip: 10\n"
+	assert.Equal(t, want, got)
+
+	var decoded map[string]yamlUser
+	assert.NoError(t, yaml.Unmarshal([]byte(got), &decoded))
+	user.Password, user.internal = "", ""
+	assert.Equal(t, user, decoded["user"])
+}
+
+func TestYamlMarshaler_Reflect_Sequences(t *testing.T) {
+	got := marshalYamlExtras(
+		"matrix", [][]int{{1, 2}, {3}},
+		"items", []any{map[string]any{"b": 2, "a": "x"}, shared.Group{"k", "v"}, "text\nlines"},
+		"empty", []string{},
+		"none", map[string]int{},
+		"nil", []int(nil),
+	)
+	want := "  matrix:\n    - - 1\n      - 2\n    - - 3\n" +
+		"  items:\n    - a: x\n      b: 2\n    - k: v\n    - |-\n      text\n      lines\n" +
+		"  empty: []\n  none: {}\n  nil: null\n"
+	assert.Equal(t, want, got)
+
+	var decoded map[string]any
+	assert.NoError(t, yaml.Unmarshal([]byte(got), &decoded))
+	assert.Equal(t, []any{[]any{1, 2}, []any{3}}, decoded["matrix"])
+	assert.Equal(
+		t,
+		[]any{map[string]any{"a": "x", "b": 2}, map[string]any{"k": "v"}, "text\nlines"},
+		decoded["items"],
+	)
+}
+
+func TestYamlMarshaler_Reflect_SpecialTypes(t *testing.T) {
+	ts := time.Date(2025, 6, 20, 8, 11, 6, 0, time.UTC)
+	got := marshalYamlExtras(
+		"at", ts,
+		"took", 1500*time.Millisecond,
+		"ip", net.ParseIP("10.0.0.1"),
+		"raw", []byte("hello"),
+		"nan", math.NaN(),
+		"inf", float32(math.Inf(-1)),
+		"ptr", &yamlAddress{Street: "Main"},
+	)
+	want := "  at: \"2025-06-20T08:11:06Z\"\n  took: 1.5s\n  ip: 10.0.0.1\n  raw: !!binary aGVsbG8=\n" +
+		"  nan: .nan\n  inf: -.inf\n  ptr:\n    street: Main\n"
+	assert.Equal(t, want, got)
+
+	var decoded map[string]any
+	assert.NoError(t, yaml.Unmarshal([]byte(got), &decoded))
+	assert.Equal(t, "hello", decoded["raw"])
+	assert.True(t, math.IsNaN(decoded["nan"].(float64)))
+}
+
+func TestYamlMarshaler_Reflect_Cycles(t *testing.T) {
+	type node struct {
+		Next *node
+	}
+
+	cyclic := &node{}
+	cyclic.Next = cyclic
+
+	var decoded map[string]any
+	assert.NoError(t, yaml.Unmarshal([]byte(marshalYamlExtras("node", cyclic, "fn", func() {})), &decoded))
+}
@@ -0,0 +1,20 @@
+package logs
+
+import (
+	"runtime"
+)
+
+// callerBaseSkip is the number of stack frames between runtime.Callers and the code calling a Logger logging
+// method: runtime.Callers itself, loggerConfigs.callerPC, Logger.log and the logging method (Debug, Info...).
+const callerBaseSkip = 4
+
+// callerPC returns the program counter of the code that called the Logger logging method,
+// skipping the additional frames set through AddCallerSkip. It returns 0 if the stack is not deep enough.
+func (c *loggerConfigs) callerPC() uintptr {
+	var pcs [1]uintptr
+	if runtime.Callers(callerBaseSkip+c.callerSkip, pcs[:]) < 1 {
+		return 0
+	}
+
+	return pcs[0]
+}
@@ -0,0 +1,156 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	s "github.com/Pho3b/tiny-logger/shared"
+	"github.com/stretchr/testify/assert"
+)
+
+// currentLocation returns the "dir/file:line" location of the line following the one it is called from.
+func currentLocation() string {
+	_, file, line, _ := runtime.Caller(1)
+
+	return filepath.Base(filepath.Dir(file)) + "/" + filepath.Base(file) + ":" + strconv.Itoa(line+1)
+}
+
+// wrappedInfo mimics a library wrapping the Logger logging methods.
+func wrappedInfo(logger *Logger, args ...any) {
+	logger.Info(args...)
+}
+
+func TestLogger_AddCaller(t *testing.T) {
+	var out, errOut bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).SetStdErrWriter(&errOut).AddCaller(true)
+
+	location := currentLocation()
+	logger.Debug("debug message")
+	assert.Equal(t, "DEBUG "+location+": debug message\n", out.String())
+
+	out.Reset()
+	location = currentLocation()
+	logger.Info("info message")
+	assert.Equal(t, "INFO "+location+": info message\n", out.String())
+
+	out.Reset()
+	location = currentLocation()
+	logger.Warn("warn message")
+	assert.Equal(t, "WARN "+location+": warn message\n", out.String())
+
+	location = currentLocation()
+	logger.Error("error message")
+	assert.Equal(t, "ERROR "+location+": error message\n", errOut.String())
+
+	out.Reset()
+	location = currentLocation()
+	logger.Infof("info %s", "formatted")
+	assert.Equal(t, "INFO "+location+": info formatted\n", out.String())
+
+	out.Reset()
+	logger.ShowLogLevel(false)
+	location = currentLocation()
+	logger.Info("info message")
+	assert.Equal(t, location+": info message\n", out.String())
+
+	out.Reset()
+	logger.AddCaller(false)
+	logger.Info("info message")
+	assert.Equal(t, "info message\n", out.String())
+}
+
+func TestLogger_AddCallerFunc(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).AddCallerFunc(true)
+
+	logger.Info("no caller")
+	assert.Equal(t, "INFO: no caller\n", out.String())
+
+	out.Reset()
+	logger.AddCaller(true)
+	location := currentLocation()
+	logger.Info("with function")
+	assert.Equal(t, "INFO "+location+" logs.TestLogger_AddCallerFunc: with function\n", out.String())
+
+	callerEnabled, funcEnabled := logger.GetCallerEnabled()
+	assert.True(t, callerEnabled)
+	assert.True(t, funcEnabled)
+}
+
+func TestLogger_AddCaller_Encoders(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).AddCaller(true).AddCallerFunc(true)
+
+	logger.SetEncoder(s.JsonEncoderType)
+	location := currentLocation()
+	logger.Info("json message", "key", 1)
+
+	var jsonLog s.JsonLog
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &jsonLog))
+	assert.Equal(t, location, jsonLog.Caller)
+	assert.Equal(t, "logs.TestLogger_AddCaller_Encoders", jsonLog.Function)
+
+	out.Reset()
+	logger.SetEncoder(s.YamlEncoderType)
+	location = currentLocation()
+	logger.Info("yaml message")
+	assert.Equal(
+		t,
+		"---\nlevel: INFO\ncaller: \""+location+"\"\nfunc: logs.TestLogger_AddCaller_Encoders\nmsg: yaml message\n\n",
+		out.String(),
+	)
+
+	out.Reset()
+	logger.SetEncoder(s.LogfmtEncoderType)
+	location = currentLocation()
+	logger.Info("logfmt message")
+	assert.Equal(
+		t,
+		"level=info caller="+location+" func=logs.TestLogger_AddCaller_Encoders msg=\"logfmt message\"\n",
+		out.String(),
+	)
+}
+
+func TestLogger_AddCaller_Destinations(t *testing.T) {
+	var out, file bytes.Buffer
+	logger := NewLogger().
+		SetStdOutWriter(&out).
+		AddCaller(true).
+		AddDestination(&file, ll.DebugLvlName, s.LogfmtEncoderType, false)
+
+	location := currentLocation()
+	logger.With("id", 1).Info("message")
+	assert.Equal(t, "INFO "+location+": message id=1\n", out.String())
+	assert.Equal(t, "level=info caller="+location+" msg=message id=1\n", file.String())
+}
+
+func TestLogger_AddCallerSkip(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).AddCaller(true).AddCallerSkip(1)
+
+	location := currentLocation()
+	wrappedInfo(logger, "wrapped message")
+	assert.Equal(t, "INFO "+location+": wrapped message\n", out.String())
+
+	out.Reset()
+	logger.AddCallerSkip(-5)
+	wrappedInfo(logger, "wrapped message")
+	assert.Contains(t, out.String(), "INFO logs/caller_test.go:")
+	assert.NotContains(t, out.String(), location)
+}
+
+func TestSlogHandler_AddCaller(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).AddCaller(true).AddCallerSkip(3)
+	slogger := slog.New(NewSlogHandler(logger))
+
+	location := currentLocation()
+	slogger.Info("slog message")
+	assert.Equal(t, "INFO "+location+": slog message\n", out.String())
+}
@@ -0,0 +1,122 @@
+package logs
+
+import (
+	"io"
+	"os"
+
+	"github.com/Pho3b/tiny-logger/internal/services"
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	"github.com/Pho3b/tiny-logger/logs/sinks"
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+// loggerConfigs is an immutable snapshot of the Logger settings.
+// The Logger setters never modify the snapshot in use: they update a copy of it and atomically swap it in,
+// so that the logging methods can read a consistent snapshot without any lock.
+type loggerConfigs struct {
+	dateEnabled       bool
+	timeEnabled       bool
+	colorsEnabled     bool
+	showLogLevel      bool
+	encoder           s.EncoderInterface
+	logLvl            ll.LogLevel
+	outFile           *os.File
+	output            io.Writer
+	stdOutWriter      io.Writer
+	stdErrWriter      io.Writer
+	dateTimeFormat    s.DateTimeFormat
+	printer           services.Printer
+	dateTimePrinter   *services.DateTimePrinter
+	destinations      []*Destination
+	destinationsLvl   int8
+	asyncWriter       *sinks.AsyncWriter
+	asyncOutputs      [3]io.Writer
+	fields            *s.BoundFields
+	jsonEscapeHTML    bool
+	callerEnabled     bool
+	callerFuncEnabled bool
+	callerSkip        int
+	stacktraceEnabled bool
+	stacktraceLvl     int8
+	stacktraceTrim    bool
+	fatalDumpEnabled  bool
+	name              string
+	lvlOverrides      *lvlOverrides
+	namedLvl          resolvedLvl
+	sampler           *Sampler
+	deduplicator      *Deduplicator
+	// base and overlay are the owner configs and the child overlay the configs of a child Logger derive from
+	base    *loggerConfigs
+	overlay *childOverlay
+}
+
+// clone returns a copy of the configs holding copies of the destinations, bound to the new configs.
+func (c *loggerConfigs) clone() *loggerConfigs {
+	clone := *c
+	clone.destinations = make([]*Destination, 0, len(c.destinations))
+
+	for _, d := range c.destinations {
+		destination := *d
+		destination.configs = &clone
+		clone.destinations = append(clone.destinations, &destination)
+	}
+
+	return &clone
+}
+
+// GetDateTimeEnabled returns the date and time settings.
+func (c *loggerConfigs) GetDateTimeEnabled() (dateEnabled bool, timeEnabled bool) {
+	return c.dateEnabled, c.timeEnabled
+}
+
+// GetColorsEnabled returns true if color output is enabled, false otherwise.
+func (c *loggerConfigs) GetColorsEnabled() bool {
+	return c.colorsEnabled
+}
+
+// GetShowLogLevel returns the showLogLevel value.
+func (c *loggerConfigs) GetShowLogLevel() bool {
+	return c.showLogLevel
+}
+
+// GetLogLvlName returns the log level name.
+func (c *loggerConfigs) GetLogLvlName() ll.LogLvlName {
+	return ll.LogLvlIntToName[c.logLvl.Lvl]
+}
+
+// GetLogLvlIntValue returns the log level as an int8 value.
+func (c *loggerConfigs) GetLogLvlIntValue() int8 {
+	return c.logLvl.Lvl
+}
+
+// GetEncoderType returns the Encoder type.
+func (c *loggerConfigs) GetEncoderType() s.EncoderType {
+	return c.encoder.GetType()
+}
+
+// GetOutputWriter returns the writer bound to the given output type.
+// A nil return value means the standard output or standard error should be used.
+func (c *loggerConfigs) GetOutputWriter(outType s.OutputType) io.Writer {
+	if c.asyncWriter != nil {
+		return c.asyncOutputs[outType]
+	}
+
+	switch outType {
+	case s.StdOutput:
+		return c.stdOutWriter
+	case s.StdErrOutput:
+		return c.stdErrWriter
+	default:
+		return c.output
+	}
+}
+
+// GetDateTimeFormat returns the DateTimeFormat.
+func (c *loggerConfigs) GetDateTimeFormat() s.DateTimeFormat {
+	return c.dateTimeFormat
+}
+
+// GetBoundFields returns the fields bound through Logger.With, or nil if there are none.
+func (c *loggerConfigs) GetBoundFields() *s.BoundFields {
+	return c.fields
+}
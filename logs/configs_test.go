@@ -0,0 +1,36 @@
+package logs
+
+import (
+	"bytes"
+	"testing"
+
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	s "github.com/Pho3b/tiny-logger/shared"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerConfigs_Clone(t *testing.T) {
+	var dest bytes.Buffer
+	c := NewLogger().AddDestination(&dest, ll.InfoLvlName, s.JsonEncoderType, false).configs.Load()
+
+	clone := c.clone()
+	clone.colorsEnabled = true
+	clone.destinations[0].colorsEnabled = true
+
+	assert.NotSame(t, c, clone)
+	assert.False(t, c.colorsEnabled)
+	assert.False(t, c.destinations[0].colorsEnabled)
+	assert.Same(t, c, c.destinations[0].configs)
+	assert.Same(t, clone, clone.destinations[0].configs)
+	assert.Same(t, c.destinations[0].encoder, clone.destinations[0].encoder)
+}
+
+func TestLogger_SettersSwapConfigs(t *testing.T) {
+	logger := NewLogger()
+	previous := logger.configs.Load()
+
+	logger.AddDateTime(true)
+	assert.NotSame(t, previous, logger.configs.Load())
+	assert.Equal(t, [2]bool{false, false}, [2]bool{previous.dateEnabled, previous.timeEnabled})
+	assert.Equal(t, [2]bool{true, true}, [2]bool{logger.configs.Load().dateEnabled, logger.configs.Load().timeEnabled})
+}
@@ -0,0 +1,358 @@
+package logs
+
+import (
+	"container/list"
+	"fmt"
+	"hash/maphash"
+	"strconv"
+	"sync"
+	"time"
+
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+const (
+	// defaultDedupWindow is the window used when DedupConfig.Window is not set.
+	defaultDedupWindow = time.Second
+	// defaultDedupCapacity is the capacity used when DedupConfig.Capacity is not set.
+	defaultDedupCapacity = 1024
+)
+
+// DedupConfig holds the configuration of a Deduplicator.
+type DedupConfig struct {
+	// Window is the time span, starting from the first entry of a kind, in which the identical entries are
+	// collapsed into it. Defaults to 1 second when not set.
+	Window time.Duration
+	// Capacity is the maximum number of entry fingerprints tracked at the same time: the least recently seen one
+	// is evicted, and its summary logged, when a new one does not fit. Defaults to 1024 when not set.
+	Capacity int
+}
+
+// dedupEntry tracks the entries with the same fingerprint logged within the current window.
+type dedupEntry struct {
+	fingerprint uint64
+	logger      *Logger
+	pc          uintptr
+	lvl         int8
+	lvlName     ll.LogLvlName
+	outType     s.OutputType
+	args        []any
+	repeated    uint64
+	windowEnd   int64
+}
+
+// summaryArgs returns the args of the entry with the message followed by the repetitions count.
+func (e *dedupEntry) summaryArgs() []any {
+	args := append([]any(nil), e.args...)
+	args[0] = messageString(args[0]) + " (repeated " + strconv.FormatUint(e.repeated, 10) + " times)"
+
+	return args
+}
+
+// log logs the summary of the entry through the current configs of the Logger it was last logged with,
+// resolving the Logger level against the call site of that entry.
+func (e *dedupEntry) log() {
+	c := e.logger.loadConfigs()
+	c.logWith(c.loggerLvlAt(e.pc), 0, "", time.Time{}, e.lvl, e.lvlName, e.outType, e.summaryArgs()...)
+}
+
+// Deduplicator collapses the identical entries, with the same level, message, extras and bound fields, logged
+// within a time window into the first one, logging a "(repeated N times)" summary once the window expires.
+// The entry fingerprints are kept in a bounded LRU cache, so that the memory usage stays capped.
+// The entries logged at PANIC and FATAL_ERROR levels are never collapsed.
+// A Deduplicator is safe for concurrent use and shared by the child loggers created through With.
+// Close must be called to log the pending summaries and to stop its background goroutine.
+type Deduplicator struct {
+	window    time.Duration
+	capacity  int
+	seed      maphash.Seed
+	mu        sync.Mutex
+	lru       *list.List
+	entries   map[uint64]*list.Element
+	doneCh    chan struct{}
+	closeOnce sync.Once
+	loopWg    sync.WaitGroup
+	now       func() time.Time
+}
+
+// Len returns the number of entry fingerprints currently tracked.
+func (d *Deduplicator) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.lru.Len()
+}
+
+// Flush logs the summaries of all the collapsed entries and forgets their fingerprints,
+// so that the next identical entries are logged again.
+func (d *Deduplicator) Flush() {
+	d.mu.Lock()
+	summaries := d.removeEntries(func(*dedupEntry) bool { return true })
+	d.mu.Unlock()
+
+	logSummaries(summaries)
+}
+
+// Close logs the summaries of all the collapsed entries and stops the background goroutine.
+func (d *Deduplicator) Close() error {
+	d.closeOnce.Do(func() {
+		close(d.doneCh)
+		d.loopWg.Wait()
+		d.Flush()
+	})
+
+	return nil
+}
+
+// isDuplicate returns true if an identical entry has already been logged within the window, counting it as
+// a repetition. Otherwise, the entry starts a new window and the summary of the previous one is logged, if any.
+// The given program counter is the call site the Logger level has been resolved against, if any.
+func (d *Deduplicator) isDuplicate(
+	l *Logger,
+	c *loggerConfigs,
+	pc uintptr,
+	lvl int8,
+	lvlName ll.LogLvlName,
+	outType s.OutputType,
+	args []any,
+) bool {
+	if lvl <= ll.FatalErrorLvl {
+		return false
+	}
+
+	fingerprint := d.fingerprint(c.fields, lvl, args)
+	now := d.now().UnixNano()
+
+	d.mu.Lock()
+
+	var summary *dedupEntry
+	if element, found := d.entries[fingerprint]; found {
+		entry := element.Value.(*dedupEntry)
+		d.lru.MoveToFront(element)
+
+		if now < entry.windowEnd {
+			entry.repeated++
+			d.mu.Unlock()
+
+			return true
+		}
+
+		if entry.repeated > 0 {
+			expired := *entry
+			summary = &expired
+		}
+
+		entry.logger = l
+		entry.pc = pc
+		entry.repeated = 0
+		entry.windowEnd = now + int64(d.window)
+	} else {
+		d.entries[fingerprint] = d.lru.PushFront(&dedupEntry{
+			fingerprint: fingerprint,
+			logger:      l,
+			pc:          pc,
+			lvl:         lvl,
+			lvlName:     lvlName,
+			outType:     outType,
+			args:        append([]any(nil), args...),
+			windowEnd:   now + int64(d.window),
+		})
+
+		if d.lru.Len() > d.capacity {
+			evicted := d.lru.Remove(d.lru.Back()).(*dedupEntry)
+			delete(d.entries, evicted.fingerprint)
+
+			if evicted.repeated > 0 {
+				summary = evicted
+			}
+		}
+	}
+
+	d.mu.Unlock()
+
+	if summary != nil {
+		summary.log()
+	}
+
+	return false
+}
+
+// fingerprint returns the hash of the given bound fields, level and args.
+func (d *Deduplicator) fingerprint(fields *s.BoundFields, lvl int8, args []any) uint64 {
+	var h maphash.Hash
+	h.SetSeed(d.seed)
+	_ = h.WriteByte(byte(lvl))
+
+	if fields != nil {
+		for _, keyVal := range fields.KeyVals() {
+			writeFingerprintArg(&h, keyVal)
+		}
+	}
+
+	_ = h.WriteByte(0)
+	for _, arg := range args {
+		writeFingerprintArg(&h, arg)
+	}
+
+	return h.Sum64()
+}
+
+// writeFingerprintArg writes the given arg into the hash, preceded by a tag of its kind and followed by a separator.
+// The common types are written without reflection, as the encoders do, falling back to fmt for the other ones.
+func writeFingerprintArg(h *maphash.Hash, arg any) {
+	var scratch [32]byte
+
+	switch v := arg.(type) {
+	case string:
+		_ = h.WriteByte('s')
+		_, _ = h.WriteString(v)
+	case []byte:
+		_ = h.WriteByte('b')
+		_, _ = h.Write(v)
+	case int:
+		_ = h.WriteByte('i')
+		_, _ = h.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int8:
+		_ = h.WriteByte('i')
+		_, _ = h.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int16:
+		_ = h.WriteByte('i')
+		_, _ = h.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int32:
+		_ = h.WriteByte('i')
+		_, _ = h.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case int64:
+		_ = h.WriteByte('i')
+		_, _ = h.Write(strconv.AppendInt(scratch[:0], v, 10))
+	case uint:
+		_ = h.WriteByte('u')
+		_, _ = h.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint8:
+		_ = h.WriteByte('u')
+		_, _ = h.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint16:
+		_ = h.WriteByte('u')
+		_, _ = h.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint32:
+		_ = h.WriteByte('u')
+		_, _ = h.Write(strconv.AppendUint(scratch[:0], uint64(v), 10))
+	case uint64:
+		_ = h.WriteByte('u')
+		_, _ = h.Write(strconv.AppendUint(scratch[:0], v, 10))
+	case float32:
+		_ = h.WriteByte('f')
+		_, _ = h.Write(strconv.AppendFloat(scratch[:0], float64(v), 'g', -1, 32))
+	case float64:
+		_ = h.WriteByte('f')
+		_, _ = h.Write(strconv.AppendFloat(scratch[:0], v, 'g', -1, 64))
+	case bool:
+		_ = h.WriteByte('t')
+		_, _ = h.Write(strconv.AppendBool(scratch[:0], v))
+	case error:
+		_ = h.WriteByte('e')
+		_, _ = h.WriteString(v.Error())
+	case fmt.Stringer:
+		_ = h.WriteByte('S')
+		_, _ = h.WriteString(v.String())
+	default:
+		_, _ = fmt.Fprintf(h, "%T:%v", v, v)
+	}
+
+	_ = h.WriteByte(0)
+}
+
+// removeEntries removes the entries satisfying the given condition, returning the ones with repetitions
+// to be summarized. It must be called holding the mutex.
+func (d *Deduplicator) removeEntries(remove func(entry *dedupEntry) bool) []*dedupEntry {
+	var summaries []*dedupEntry
+
+	for element := d.lru.Back(); element != nil; {
+		prev := element.Prev()
+		entry := element.Value.(*dedupEntry)
+
+		if remove(entry) {
+			d.lru.Remove(element)
+			delete(d.entries, entry.fingerprint)
+
+			if entry.repeated > 0 {
+				summaries = append(summaries, entry)
+			}
+		}
+
+		element = prev
+	}
+
+	return summaries
+}
+
+// loop is the background goroutine logging the summaries of the expired windows until the Deduplicator is closed.
+func (d *Deduplicator) loop() {
+	defer d.loopWg.Done()
+
+	ticker := time.NewTicker(d.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			now := d.now().UnixNano()
+
+			d.mu.Lock()
+			summaries := d.removeEntries(func(entry *dedupEntry) bool { return now >= entry.windowEnd })
+			d.mu.Unlock()
+
+			logSummaries(summaries)
+		case <-d.doneCh:
+			return
+		}
+	}
+}
+
+// logSummaries logs the summaries of the given entries.
+func logSummaries(entries []*dedupEntry) {
+	for _, entry := range entries {
+		entry.log()
+	}
+}
+
+// SetDeduplicator attaches the given Deduplicator to the Logger, or detaches the current one if nil.
+// The Deduplicator applies to the Logger destinations too, and it is shared by the child loggers created
+// through With. It runs before the Sampler, if any, so that the repetitions are counted exactly.
+func (l *Logger) SetDeduplicator(deduplicator *Deduplicator) *Logger {
+	return l.update(func(c *loggerConfigs) {
+		c.deduplicator = deduplicator
+	})
+}
+
+// GetDeduplicator returns the Deduplicator attached to the Logger, or nil if there is none.
+func (l *Logger) GetDeduplicator() *Deduplicator {
+	return l.loadConfigs().deduplicator
+}
+
+// NewDeduplicator creates and returns a new Deduplicator with the given configuration,
+// starting the background goroutine that logs the summaries of the expired windows.
+func NewDeduplicator(config DedupConfig) *Deduplicator {
+	if config.Window <= 0 {
+		config.Window = defaultDedupWindow
+	}
+
+	if config.Capacity <= 0 {
+		config.Capacity = defaultDedupCapacity
+	}
+
+	deduplicator := &Deduplicator{
+		window:   config.Window,
+		capacity: config.Capacity,
+		seed:     maphash.MakeSeed(),
+		lru:      list.New(),
+		entries:  make(map[uint64]*list.Element, config.Capacity),
+		doneCh:   make(chan struct{}),
+		now:      time.Now,
+	}
+
+	deduplicator.loopWg.Add(1)
+	go deduplicator.loop()
+
+	return deduplicator
+}
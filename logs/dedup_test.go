@@ -0,0 +1,231 @@
+package logs
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	s "github.com/Pho3b/tiny-logger/shared"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestDeduplicator(t *testing.T, config DedupConfig) (*Deduplicator, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 3, 11, 18, 35, 43, 0, time.UTC)}
+
+	deduplicator := NewDeduplicator(config)
+	deduplicator.now = clock.Now
+	t.Cleanup(func() { _ = deduplicator.Close() })
+
+	return deduplicator, clock
+}
+
+func TestLogger_SetDeduplicator(t *testing.T) {
+	var out bytes.Buffer
+	deduplicator, clock := newTestDeduplicator(t, DedupConfig{Window: time.Hour})
+	logger := NewLogger().SetStdOutWriter(&out).SetDeduplicator(deduplicator)
+	assert.Same(t, deduplicator, logger.GetDeduplicator())
+
+	for i := 0; i < 4; i++ {
+		logger.Info("db down")
+	}
+
+	logger.Info("db down", "retry", 1)
+	logger.Warn("db down")
+	assert.Equal(t, "INFO: db down\nINFO: db down retry 1\nWARN: db down\n", out.String())
+	assert.Equal(t, 3, deduplicator.Len())
+
+	// The summary is logged before the first identical entry after the window
+	out.Reset()
+	clock.Advance(time.Hour)
+	logger.Info("db down")
+	logger.Info("db down")
+	assert.Equal(t, "INFO: db down (repeated 3 times)\nINFO: db down\n", out.String())
+
+	out.Reset()
+	logger.SetDeduplicator(nil).Info("db down")
+	assert.Equal(t, "INFO: db down\n", out.String())
+}
+
+func TestLogger_SetDeduplicator_Flush(t *testing.T) {
+	var out, errOut bytes.Buffer
+	deduplicator, _ := newTestDeduplicator(t, DedupConfig{Window: time.Hour})
+	logger := NewLogger().SetStdOutWriter(&out).SetStdErrWriter(&errOut).SetDeduplicator(deduplicator)
+
+	logger.Error(errors.New("connection refused"))
+	logger.Error(errors.New("connection refused"))
+	logger.Info("single message")
+	assert.Equal(t, "INFO: single message\n", out.String())
+
+	deduplicator.Flush()
+	assert.Equal(t, "ERROR: connection refused\nERROR: connection refused (repeated 1 times)\n", errOut.String())
+	assert.Equal(t, "INFO: single message\n", out.String())
+	assert.Zero(t, deduplicator.Len())
+
+	// The summaries are logged on Close too, which can be called more than once
+	errOut.Reset()
+	logger.Error("closing")
+	logger.Error("closing")
+	assert.NoError(t, deduplicator.Close())
+	assert.NoError(t, deduplicator.Close())
+	assert.Equal(t, "ERROR: closing\nERROR: closing (repeated 1 times)\n", errOut.String())
+}
+
+func TestLogger_SetDeduplicator_Capacity(t *testing.T) {
+	var out bytes.Buffer
+	deduplicator, _ := newTestDeduplicator(t, DedupConfig{Window: time.Hour, Capacity: 2})
+	logger := NewLogger().SetStdOutWriter(&out).SetDeduplicator(deduplicator)
+
+	logger.Info("first")
+	logger.Info("first")
+	logger.Info("second")
+	logger.Info("first")
+	assert.Equal(t, "INFO: first\nINFO: second\n", out.String())
+
+	// The least recently seen entry is evicted, logging its summary
+	out.Reset()
+	logger.Info("third")
+	logger.Info("fourth")
+	assert.Equal(t, "INFO: third\nINFO: first (repeated 2 times)\nINFO: fourth\n", out.String())
+	assert.Equal(t, 2, deduplicator.Len())
+}
+
+func TestLogger_SetDeduplicator_Fingerprint(t *testing.T) {
+	var out bytes.Buffer
+	deduplicator, _ := newTestDeduplicator(t, DedupConfig{Window: time.Hour})
+	logger := NewLogger().SetStdOutWriter(&out).SetDeduplicator(deduplicator)
+
+	logger.With("id", 1).Info("message")
+	logger.With("id", 2).Info("message")
+	logger.With("id", 1).Info("message")
+	logger.Info("message", 1)
+	logger.Info("message", "1")
+	assert.Equal(t, "INFO: message id=1\nINFO: message id=2\nINFO: message 1\nINFO: message 1\n", out.String())
+
+	out.Reset()
+	logger.Info("values", int64(-3), uint8(4), 1.5, float32(2.5), true, errors.New("err"), time.Second, []byte("b"))
+	logger.Info("values", int64(-3), uint8(4), 1.5, float32(2.5), true, errors.New("err"), time.Second, []byte("b"))
+	logger.Info("values", -3, uint(4), 1.5, float32(2.5), false, "err", time.Second, "b")
+	logger.Info("other", struct{ ID int }{ID: 1})
+	logger.Info("other", struct{ ID int }{ID: 1})
+	logger.Info("other", struct{ ID int }{ID: 2})
+	assert.Equal(
+		t,
+		"INFO: values -3 4 1.5 2.5 true err 1s b\nINFO: values -3 4 1.5 2.5 false err 1s b\n"+
+			"INFO: other {1}\nINFO: other {2}\n",
+		out.String(),
+	)
+}
+
+func TestLogger_SetDeduplicator_CurrentConfigs(t *testing.T) {
+	var out, newOut bytes.Buffer
+	deduplicator, clock := newTestDeduplicator(t, DedupConfig{Window: time.Hour})
+	logger := NewLogger().SetStdOutWriter(&out).SetDeduplicator(deduplicator)
+	child := logger.With("id", 1)
+
+	child.Info("message")
+	child.Info("message")
+
+	// The summary is logged through the configuration the Logger has when the window expires
+	logger.SetStdOutWriter(&newOut).SetEncoder(s.JsonEncoderType)
+	clock.Advance(time.Hour)
+	deduplicator.Flush()
+	assert.Equal(t, "INFO: message id=1\n", out.String())
+	assert.Contains(t, newOut.String(), `"msg":"message (repeated 1 times)"`)
+	assert.Contains(t, newOut.String(), `"id":1`)
+
+	// The summary is not logged if the Logger level does not allow it anymore
+	newOut.Reset()
+	child.Info("message")
+	child.Info("message")
+	logger.SetLogLvl(ll.WarnLvlName)
+	deduplicator.Flush()
+	assert.Contains(t, newOut.String(), `"msg":"message"`)
+	assert.NotContains(t, newOut.String(), "repeated")
+}
+
+func TestLogger_SetDeduplicator_FatalLevels(t *testing.T) {
+	var out bytes.Buffer
+	deduplicator, _ := newTestDeduplicator(t, DedupConfig{Window: time.Hour})
+	logger := NewLogger().SetStdErrWriter(&out).SetDeduplicator(deduplicator)
+
+	for i := 0; i < 2; i++ {
+		assert.Panics(t, func() { logger.Panic("panic message") })
+	}
+
+	assert.Equal(t, "PANIC: panic message\nPANIC: panic message\n", out.String())
+	assert.Zero(t, deduplicator.Len())
+}
+
+func TestLogger_SetDeduplicator_JsonEncoder(t *testing.T) {
+	var errOut bytes.Buffer
+	deduplicator, _ := newTestDeduplicator(t, DedupConfig{Window: time.Hour})
+	logger := NewLogger().SetStdErrWriter(&errOut).SetEncoder(s.JsonEncoderType).SetDeduplicator(deduplicator)
+
+	for i := 0; i < 4; i++ {
+		logger.Error("db down", "host", "db-1")
+	}
+
+	deduplicator.Flush()
+	assert.Equal(
+		t,
+		`{"level":"ERROR","msg":"db down","extras":{"host":"db-1"}}`+"\n"+
+			`{"level":"ERROR","msg":"db down (repeated 3 times)","extras":{"host":"db-1"}}`+"\n",
+		errOut.String(),
+	)
+}
+
+func TestSlogHandler_Deduplicator(t *testing.T) {
+	var out bytes.Buffer
+	deduplicator, _ := newTestDeduplicator(t, DedupConfig{Window: time.Hour})
+	slogger := slog.New(NewSlogHandler(NewLogger().SetStdOutWriter(&out).SetDeduplicator(deduplicator)))
+
+	slogger.Info("slog message", "id", 1)
+	slogger.Info("slog message", "id", 1)
+	slogger.Info("slog message", "id", 2)
+	deduplicator.Flush()
+	assert.Equal(t, "INFO: slog message id 1\nINFO: slog message id 2\nINFO: slog message (repeated 1 times) id 1\n", out.String())
+}
+
+func TestDeduplicator_Loop(t *testing.T) {
+	var out syncBuffer
+	deduplicator := NewDeduplicator(DedupConfig{Window: 10 * time.Millisecond})
+	defer deduplicator.Close()
+
+	logger := NewLogger().SetStdOutWriter(&out).SetDeduplicator(deduplicator)
+	logger.Info("looped message")
+	logger.Info("looped message")
+
+	// The background goroutine logs the summary once the window expires
+	assert.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "INFO: looped message (repeated 1 times)\n")
+	}, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool { return deduplicator.Len() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestDeduplicator_Concurrency(t *testing.T) {
+	var out syncBuffer
+	var wg sync.WaitGroup
+
+	deduplicator, _ := newTestDeduplicator(t, DedupConfig{Window: time.Hour})
+	logger := NewLogger().SetStdOutWriter(&out).SetDeduplicator(deduplicator)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				logger.Info("concurrent message")
+			}
+		}()
+	}
+
+	wg.Wait()
+	deduplicator.Flush()
+	assert.Equal(t, "INFO: concurrent message\nINFO: concurrent message (repeated 7999 times)\n", out.String())
+}
@@ -0,0 +1,73 @@
+package logs
+
+import (
+	"io"
+
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+// Destination is an additional output of a Logger with its own writer, minimum log level, encoder and
+// colors setting. Every other setting is inherited from the Logger the destination belongs to.
+type Destination struct {
+	configs       *loggerConfigs
+	writer        io.Writer
+	logLvl        int8
+	encoder       s.EncoderInterface
+	colorsEnabled bool
+	asyncWriter   io.Writer
+}
+
+// GetWriter returns the writer the destination logs are written to.
+func (d *Destination) GetWriter() io.Writer {
+	return d.writer
+}
+
+// GetDateTimeEnabled returns the date and time settings of the owning Logger.
+func (d *Destination) GetDateTimeEnabled() (dateEnabled bool, timeEnabled bool) {
+	return d.configs.GetDateTimeEnabled()
+}
+
+// GetColorsEnabled returns true if color output is enabled for the destination, false otherwise.
+func (d *Destination) GetColorsEnabled() bool {
+	return d.colorsEnabled
+}
+
+// GetShowLogLevel returns the showLogLevel value of the owning Logger.
+func (d *Destination) GetShowLogLevel() bool {
+	return d.configs.GetShowLogLevel()
+}
+
+// GetLogLvlName returns the destination minimum log level name.
+func (d *Destination) GetLogLvlName() ll.LogLvlName {
+	return ll.LogLvlIntToName[d.logLvl]
+}
+
+// GetLogLvlIntValue returns the destination minimum log level as an int8 value.
+func (d *Destination) GetLogLvlIntValue() int8 {
+	return d.logLvl
+}
+
+// GetEncoderType returns the destination Encoder type.
+func (d *Destination) GetEncoderType() s.EncoderType {
+	return d.encoder.GetType()
+}
+
+// GetOutputWriter returns the destination writer, regardless of the given output type.
+func (d *Destination) GetOutputWriter(_ s.OutputType) io.Writer {
+	if d.asyncWriter != nil {
+		return d.asyncWriter
+	}
+
+	return d.writer
+}
+
+// GetDateTimeFormat returns the DateTimeFormat of the owning Logger.
+func (d *Destination) GetDateTimeFormat() s.DateTimeFormat {
+	return d.configs.GetDateTimeFormat()
+}
+
+// GetBoundFields returns the fields bound to the owning Logger.
+func (d *Destination) GetBoundFields() *s.BoundFields {
+	return d.configs.GetBoundFields()
+}
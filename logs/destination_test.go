@@ -0,0 +1,197 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Pho3b/tiny-logger/logs/colors"
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	"github.com/Pho3b/tiny-logger/logs/sinks"
+	"github.com/Pho3b/tiny-logger/shared"
+	"github.com/Pho3b/tiny-logger/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_AddDestination(t *testing.T) {
+	var terminal, file bytes.Buffer
+	logger := NewLogger().
+		SetLogLvl(ll.InfoLvlName).
+		EnableColors(true).
+		SetStdOutWriter(&terminal).
+		AddDestination(&file, ll.DebugLvlName, shared.JsonEncoderType, false)
+
+	logger.Debug("debug message")
+	logger.Info("info message")
+
+	assert.NotContains(t, terminal.String(), "debug message")
+	assert.Contains(t, terminal.String(), colors.Cyan.String()+"INFO: "+colors.Reset.String()+"info message")
+
+	lines := strings.Split(strings.TrimSpace(file.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	var entry shared.JsonLog
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "DEBUG", entry.Level)
+	assert.Equal(t, "debug message", entry.Message)
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "INFO", entry.Level)
+	assert.Equal(t, "info message", entry.Message)
+}
+
+func TestLogger_AddDestination_ErrorsKeepGoingToStderr(t *testing.T) {
+	var file bytes.Buffer
+	logger := NewLogger().AddDestination(&file, ll.ErrorLvlName, shared.DefaultEncoderType, false)
+
+	output := test.CaptureErrorOutput(func() { logger.Error("error message") })
+	assert.Equal(t, "ERROR: error message\n", output)
+	assert.Equal(t, "ERROR: error message\n", file.String())
+
+	file.Reset()
+	logger.Warn("warn message")
+	assert.Empty(t, file.String())
+}
+
+func TestLogger_AddDestination_Nil(t *testing.T) {
+	logger := NewLogger()
+	warnOut := test.CaptureOutput(func() {
+		logger.AddDestination(nil, ll.DebugLvlName, shared.DefaultEncoderType, false)
+	})
+
+	assert.Equal(t, "WARN: the given destination writer is nil, skipping destination\n", warnOut)
+	assert.Empty(t, logger.GetDestinations())
+}
+
+func TestLogger_AddDestination_Syslog(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	syslogWriter, err := sinks.NewSyslogWriter(sinks.SyslogConfig{Network: "udp", Address: listener.LocalAddr().String()})
+	assert.NoError(t, err)
+	defer syslogWriter.Close()
+
+	var out bytes.Buffer
+	logger := NewLogger().
+		SetStdOutWriter(&out).
+		AddDestination(syslogWriter, ll.InfoLvlName, shared.SyslogEncoderType, false).
+		With("id", 7)
+
+	logger.Debug("debug message")
+	logger.Info("info message", "user", "alice")
+
+	buf := make([]byte, 1024)
+	_ = listener.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := listener.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^<14>1 \S+ \S+ \S+ \d+ - \[fields@32473 id="7" user="alice"\] info message$`, string(buf[:n]))
+	assert.Equal(t, "DEBUG: debug message id=7\nINFO: info message id=7 user alice\n", out.String())
+}
+
+func TestLogger_AddDestination_Network(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	networkWriter, err := sinks.NewNetworkWriter(sinks.NetworkConfig{Network: "tcp", Address: listener.Addr().String()})
+	assert.NoError(t, err)
+
+	logger := NewLogger().
+		SetStdOutWriter(io.Discard).
+		AddDestination(networkWriter, ll.InfoLvlName, shared.JsonEncoderType, false)
+
+	logger.Debug("debug message")
+	logger.Info("info message", "user", "alice")
+	logger.Warn("warn message")
+	assert.NoError(t, networkWriter.Close())
+
+	conn, err := listener.Accept()
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	received, err := io.ReadAll(conn)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"{\"level\":\"INFO\",\"msg\":\"info message\",\"extras\":{\"user\":\"alice\"}}\n"+
+			"{\"level\":\"WARN\",\"msg\":\"warn message\"}\n",
+		string(received),
+	)
+}
+
+func TestLogger_AddDestination_HTTP(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	httpWriter, err := sinks.NewHTTPWriter(sinks.HTTPConfig{
+		URL:    server.URL + "/loki/api/v1/push",
+		Format: sinks.LokiFormat{Labels: map[string]string{"app": "api"}, LabelKeys: []string{"tenant"}},
+	})
+	assert.NoError(t, err)
+
+	logger := NewLogger().
+		SetStdOutWriter(io.Discard).
+		AddDestination(httpWriter, ll.InfoLvlName, shared.JsonEncoderType, false).
+		With("tenant", "acme")
+
+	logger.Debug("debug message")
+	logger.Info("info message")
+	assert.NoError(t, httpWriter.Close())
+
+	var request struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &request))
+	assert.Len(t, request.Streams, 1)
+	assert.Equal(t, map[string]string{"app": "api", "level": "info", "tenant": "acme"}, request.Streams[0].Stream)
+	assert.Len(t, request.Streams[0].Values, 1)
+	assert.Equal(t, `{"level":"INFO","msg":"info message","extras":{"tenant":"acme"}}`, request.Streams[0].Values[0][1])
+}
+
+func TestLogger_ClearDestinations(t *testing.T) {
+	var file bytes.Buffer
+	logger := NewLogger().
+		SetLogLvl(ll.ErrorLvlName).
+		AddDestination(&file, ll.DebugLvlName, shared.YamlEncoderType, false)
+	assert.True(t, logger.isLvlEnabled(ll.DebugLvl))
+	assert.Len(t, logger.GetDestinations(), 1)
+
+	logger.ClearDestinations()
+	assert.False(t, logger.isLvlEnabled(ll.DebugLvl))
+	assert.Empty(t, logger.GetDestinations())
+
+	logger.Debug("debug message")
+	assert.Empty(t, file.String())
+}
+
+func TestDestination_Configs(t *testing.T) {
+	var file bytes.Buffer
+	logger := NewLogger().AddDateTime(true).ShowLogLevel(false).SetDateTimeFormat(shared.US).
+		AddDestination(&file, ll.WarnLvlName, shared.YamlEncoderType, true)
+	d := logger.GetDestinations()[0]
+
+	dateEnabled, timeEnabled := d.GetDateTimeEnabled()
+	assert.True(t, dateEnabled)
+	assert.True(t, timeEnabled)
+	assert.True(t, d.GetColorsEnabled())
+	assert.False(t, d.GetShowLogLevel())
+	assert.Equal(t, ll.WarnLvlName, d.GetLogLvlName())
+	assert.Equal(t, ll.WarnLvl, d.GetLogLvlIntValue())
+	assert.Equal(t, shared.YamlEncoderType, d.GetEncoderType())
+	assert.Equal(t, shared.US, d.GetDateTimeFormat())
+	assert.Equal(t, &file, d.GetWriter())
+	assert.Equal(t, &file, d.GetOutputWriter(shared.StdErrOutput))
+}
@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"strconv"
 	"sync"
+	"time"
 
+	"github.com/Pho3b/tiny-logger/internal/services"
+	c "github.com/Pho3b/tiny-logger/logs/colors"
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
 	s "github.com/Pho3b/tiny-logger/shared"
 )
 
@@ -19,6 +23,83 @@ type baseEncoder struct {
 	bufferSyncPool sync.Pool
 }
 
+// BaseEncoder exposes the buffer pooling, value casting, date/time and printing helpers used by the built-in
+// encoders, so that custom encoders can embed it and behave like them.
+// It must be initialized through NewBaseEncoder.
+type BaseEncoder struct {
+	baseEncoder
+	dateTimePrinter *services.DateTimePrinter
+	printer         services.Printer
+}
+
+// GetBuffer returns a bytes buffer from the pool, creating a new one if the pool is empty.
+func (b *BaseEncoder) GetBuffer() *bytes.Buffer {
+	return b.getBuffer()
+}
+
+// PutBuffer resets the given bytes buffer and puts it back to the pool.
+func (b *BaseEncoder) PutBuffer(buf *bytes.Buffer) {
+	b.putBuffer(buf)
+}
+
+// CastToString returns the given argument as a string, using the slow fmt.Sprint only for unknown types.
+func (b *BaseEncoder) CastToString(arg any) string {
+	return b.castToString(arg)
+}
+
+// CastAndConcatenateInto writes all the given arguments cast to string and concatenated by a white space
+// into the given buffer.
+func (b *BaseEncoder) CastAndConcatenateInto(buf *bytes.Buffer, args ...any) {
+	b.castAndConcatenateInto(buf, args...)
+}
+
+// RetrieveDateTime returns the cached date, time and unix timestamp strings matching the given logger settings.
+// Disabled elements are returned as empty strings, while only the timestamp is returned for shared.UnixTimestamp.
+func (b *BaseEncoder) RetrieveDateTime(logger s.LoggerConfigsInterface) (dateStr, timeStr, unixTs string) {
+	dEnabled, tEnabled := logger.GetDateTimeEnabled()
+
+	return b.dateTimePrinter.RetrieveDateTimeAt(b.retrieveEntryTime(logger), logger.GetDateTimeFormat(), dEnabled, tEnabled)
+}
+
+// RetrieveLogLvlColor returns the color of the given log level, or an empty color if the logger colors are disabled.
+func (b *BaseEncoder) RetrieveLogLvlColor(logger s.LoggerConfigsInterface, logLvlName ll.LogLvlName) c.Color {
+	return b.printer.RetrieveColorsFromLogLevel(
+		logger.GetColorsEnabled(),
+		ll.RetrieveLogLvlIntFromName(logLvlName),
+	)[0]
+}
+
+// RetrieveCaller returns the pre-formatted "file:line" location and the function name of the entry being logged.
+// Empty strings are returned when the caller annotation is disabled, while the function name is returned only
+// when the function annotation is enabled too.
+func (b *BaseEncoder) RetrieveCaller(logger s.LoggerConfigsInterface) (location, function string) {
+	return b.retrieveCaller(logger)
+}
+
+// RetrieveStacktrace returns the stack trace captured for the entry being logged, or an empty string if none
+// has been captured.
+func (b *BaseEncoder) RetrieveStacktrace(logger s.LoggerConfigsInterface) string {
+	return b.retrieveStacktrace(logger)
+}
+
+// PrintLog writes the given buffer to the logger writer of the given output type.
+func (b *BaseEncoder) PrintLog(logger s.LoggerConfigsInterface, outType s.OutputType, buf *bytes.Buffer) {
+	b.printer.PrintLog(outType, buf, logger.GetOutputWriter(outType))
+}
+
+// NewBaseEncoder initializes and returns a new BaseEncoder reporting the given encoder type.
+func NewBaseEncoder(encoderType s.EncoderType) *BaseEncoder {
+	encoder := &BaseEncoder{dateTimePrinter: services.GetDateTimePrinter(), printer: services.NewPrinter()}
+	encoder.encoderType = encoderType
+	encoder.bufferSyncPool = sync.Pool{
+		New: func() any {
+			return new(bytes.Buffer)
+		},
+	}
+
+	return encoder
+}
+
 // castAndConcatenateInto writes all the given arguments cast to string and concatenated by a white space
 // into the given buffer.
 // The function uses the slower fmt.Sprint only for unknown types
@@ -124,6 +205,57 @@ func (b *baseEncoder) castToString(arg any) string {
 	}
 }
 
+// retrieveCaller returns the caller location and function name carried by the given logger configs, if any.
+// The function name is returned only if its annotation is enabled.
+func (b *baseEncoder) retrieveCaller(logger s.LoggerConfigsInterface) (location, function string) {
+	provider, ok := logger.(s.CallerProviderInterface)
+	if !ok {
+		return "", ""
+	}
+
+	caller, showFunction := provider.GetCaller()
+	if caller == nil {
+		return "", ""
+	}
+
+	if showFunction {
+		function = caller.Function
+	}
+
+	return caller.Location, function
+}
+
+// retrieveEntryTime returns the time carried by the given logger configs, or the zero time
+// if the entry is logged at the current time.
+func (b *baseEncoder) retrieveEntryTime(logger s.LoggerConfigsInterface) time.Time {
+	if provider, ok := logger.(s.TimeProviderInterface); ok {
+		return provider.GetTime()
+	}
+
+	return time.Time{}
+}
+
+// retrieveStacktrace returns the stack trace carried by the given logger configs, if any.
+func (b *baseEncoder) retrieveStacktrace(logger s.LoggerConfigsInterface) string {
+	if provider, ok := logger.(s.StacktraceProviderInterface); ok {
+		return provider.GetStacktrace()
+	}
+
+	return ""
+}
+
+// withMsgError returns the given extras preceded by the "error" key and the given message argument if it is an error,
+// so that the structured encoders can render its wrapped chain besides its message.
+func (b *baseEncoder) withMsgError(msgArg any, extras []any) []any {
+	err, ok := msgArg.(error)
+	if !ok {
+		return extras
+	}
+
+	withError := make([]any, 0, len(extras)+2)
+	return append(append(withError, "error", err), extras...)
+}
+
 // getBuffer returns a new bytes buffer from the pool.
 // If the pool is empty, a new buffer is created.
 func (b *baseEncoder) getBuffer() *bytes.Buffer {
@@ -51,6 +51,9 @@ func TestBuildMsg(t *testing.T) {
 
 	result = encoder.castToString(struct{ test string }{"test"})
 	assert.Equal(t, "{test}", result)
+
+	result = encoder.castToString(s.Group{"user", "alice", "id"})
+	assert.Equal(t, "{user=alice id=<nil>}", result)
 }
 
 func TestBuildMsgWithCastAndConcatenateInto(t *testing.T) {
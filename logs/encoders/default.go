@@ -2,7 +2,10 @@ package encoders
 
 import (
 	"bytes"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/Pho3b/tiny-logger/internal/services"
 	c "github.com/Pho3b/tiny-logger/logs/colors"
@@ -25,8 +28,14 @@ func (d *DefaultEncoder) Log(
 	args ...any,
 ) {
 	dEnabled, tEnabled := logger.GetDateTimeEnabled()
+	caller, function := d.retrieveCaller(logger)
 	msgBuffer := d.getBuffer()
 
+	var fields []byte
+	if boundFields := logger.GetBoundFields(); boundFields != nil && len(boundFields.KeyVals()) > 0 {
+		fields = boundFields.Encoded(d)
+	}
+
 	d.composeMsgInto(
 		msgBuffer,
 		logLvlName,
@@ -35,11 +44,21 @@ func (d *DefaultEncoder) Log(
 		logger.GetColorsEnabled(),
 		logger.GetShowLogLevel(),
 		logger.GetDateTimeFormat(),
+		d.retrieveEntryTime(logger),
+		caller,
+		function,
+		fields,
 		args...,
 	)
 
+	// The stack trace is written as a multi-line block following the entry line
+	if stacktrace := d.retrieveStacktrace(logger); stacktrace != "" {
+		msgBuffer.WriteByte('\n')
+		msgBuffer.WriteString(stacktrace)
+	}
+
 	msgBuffer.WriteByte('\n')
-	d.printer.PrintLog(outType, msgBuffer, logger.GetLogFile())
+	d.printer.PrintLog(outType, msgBuffer, logger.GetOutputWriter(outType))
 	d.putBuffer(msgBuffer)
 }
 
@@ -58,17 +77,23 @@ func (d *DefaultEncoder) Color(logger s.LoggerConfigsInterface, color c.Color, a
 			false,
 			false,
 			logger.GetDateTimeFormat(),
+			time.Time{},
+			"",
+			"",
+			nil,
 			args...,
 		)
 
 		msgBuffer.WriteString(c.Reset.String())
 		msgBuffer.WriteByte('\n')
-		d.printer.PrintLog(s.StdOutput, msgBuffer, logger.GetLogFile())
+		d.printer.PrintLog(s.StdOutput, msgBuffer, logger.GetOutputWriter(s.StdOutput))
 		d.putBuffer(msgBuffer)
 	}
 }
 
 // composeMsgInto formats and writes the given 'msg' into the given buffer.
+// The given pre-encoded bound fields, if any, are written between the message and the other args,
+// like the encoders writing the bound fields before the extras of the call.
 func (d *DefaultEncoder) composeMsgInto(
 	buf *bytes.Buffer,
 	logLevel ll.LogLvlName,
@@ -77,34 +102,65 @@ func (d *DefaultEncoder) composeMsgInto(
 	headerColorEnabled bool,
 	showLogLevel bool,
 	dateTimeFormat s.DateTimeFormat,
+	entryTime time.Time,
+	caller string,
+	function string,
+	fields []byte,
 	args ...any,
 ) {
-	buf.Grow(len(args)*averageWordLen + defaultCharOverhead)
+	buf.Grow(len(args)*averageWordLen + len(fields) + defaultCharOverhead)
 
 	isDateOrTimeEnabled := dateEnabled || timeEnabled
 	colors := d.printer.RetrieveColorsFromLogLevel(headerColorEnabled, ll.LogLvlNameToInt[logLevel])
 	buf.WriteString(string(colors[0]))
+	headerStart := buf.Len()
 
 	if showLogLevel {
 		buf.WriteString(logLevel.String())
+	}
 
-		if isDateOrTimeEnabled {
+	if isDateOrTimeEnabled {
+		if buf.Len() > headerStart {
 			buf.WriteByte(' ')
 		}
-	}
 
-	if isDateOrTimeEnabled {
-		dateStr, timeStr, unixTs := d.DateTimePrinter.RetrieveDateTime(dateTimeFormat, dateEnabled, timeEnabled)
+		dateStr, timeStr, unixTs := d.DateTimePrinter.RetrieveDateTimeAt(entryTime, dateTimeFormat, dateEnabled, timeEnabled)
 		d.addFormattedDateTime(buf, dateStr, timeStr, unixTs)
 	}
 
-	if showLogLevel || isDateOrTimeEnabled {
+	if caller != "" {
+		if buf.Len() > headerStart {
+			buf.WriteByte(' ')
+		}
+
+		buf.WriteString(caller)
+
+		if function != "" {
+			buf.WriteByte(' ')
+			buf.WriteString(function)
+		}
+	}
+
+	if showLogLevel || isDateOrTimeEnabled || caller != "" {
 		buf.WriteByte(':')
 		buf.WriteByte(' ')
 	}
 
 	buf.WriteString(string(colors[1]))
-	d.castAndConcatenateInto(buf, args...)
+
+	if len(fields) == 0 || len(args) == 0 {
+		d.castAndConcatenateInto(buf, args...)
+		return
+	}
+
+	d.castAndConcatenateInto(buf, args[0])
+	buf.WriteByte(' ')
+	buf.Write(fields)
+
+	if len(args) > 1 {
+		buf.WriteByte(' ')
+		d.castAndConcatenateInto(buf, args[1:]...)
+	}
 }
 
 // addFormattedDateTime formats and adds the date and time strings enclosed in square brackets to the given buffer.
@@ -132,6 +188,34 @@ func (d *DefaultEncoder) addFormattedDateTime(buf *bytes.Buffer, dateStr, timeSt
 	buf.WriteByte(']')
 }
 
+// EncodeFields writes the given key/value pairs into the buffer as space separated key=value pairs.
+// Values containing white spaces, quotes or '=' characters are quoted.
+func (d *DefaultEncoder) EncodeFields(buf *bytes.Buffer, keyVals ...any) {
+	keyValsLen := len(keyVals)
+
+	for i := 0; i < keyValsLen; i += 2 {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+
+		buf.WriteString(d.castToString(keyVals[i]))
+		buf.WriteByte('=')
+
+		if i+1 >= keyValsLen {
+			buf.WriteString("<nil>")
+			continue
+		}
+
+		value := d.castToString(keyVals[i+1])
+		if strings.ContainsAny(value, " \t\n\"=") {
+			buf.Write(strconv.AppendQuote(buf.AvailableBuffer(), value))
+			continue
+		}
+
+		buf.WriteString(value)
+	}
+}
+
 // NewDefaultEncoder initializes and returns a new DefaultEncoder instance.
 func NewDefaultEncoder(
 	printer services.Printer,
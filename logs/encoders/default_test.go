@@ -168,3 +168,13 @@ func TestDefaultEncoder_Color(t *testing.T) {
 
 	os.Stdout = originalStdOut
 }
+
+func TestDefaultEncoder_BoundFields(t *testing.T) {
+	encoder := NewDefaultEncoder(services.NewPrinter(), services.GetDateTimePrinter())
+	loggerConfig := &test.LoggerConfigMock{ShowLogLevel: true, Fields: s.NewBoundFields("user", "alice", "note", "two words", "id")}
+
+	output := test.CaptureOutput(func() {
+		encoder.Log(loggerConfig, ll.InfoLvlName, s.StdOutput, "Test msg", 3)
+	})
+	assert.Equal(t, "INFO: Test msg user=alice note=\"two words\" id=<nil> 3\n", output)
+}
@@ -0,0 +1,215 @@
+package encoders
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Pho3b/tiny-logger/internal/services"
+	c "github.com/Pho3b/tiny-logger/logs/colors"
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+// journaldFieldNameMaxLen is the maximum length of a journal field name.
+const journaldFieldNameMaxLen = 64
+
+// JournaldConfig holds the JournaldEncoder settings.
+type JournaldConfig struct {
+	// SyslogIdentifier is the program name the entries are shown with by journalctl.
+	// Defaults to the executable name.
+	SyslogIdentifier string
+}
+
+// JournaldEncoder encodes the entries in the systemd-journald native protocol format, as a list of journal fields:
+// MESSAGE, PRIORITY (the syslog severity of the log level), SYSLOG_IDENTIFIER, CODE_FILE, CODE_LINE and CODE_FUNC
+// when the caller annotation is enabled, STACKTRACE when a stack trace is captured, followed by the bound fields and
+// the extras. The extras keys are upper-cased, with the characters not allowed by journald replaced by an underscore.
+// Every entry is meant to be sent as a single datagram: see sinks.JournaldWriter.
+type JournaldEncoder struct {
+	baseEncoder
+	// header holds the pre-encoded SYSLOG_IDENTIFIER field
+	header  []byte
+	printer services.Printer
+}
+
+// Log formats and prints a log message to the given output type.
+// The Logger date/time and log level visibility settings are ignored, since the journal adds its own timestamps
+// and the PRIORITY field.
+func (j *JournaldEncoder) Log(
+	logger s.LoggerConfigsInterface,
+	logLvlName ll.LogLvlName,
+	outType s.OutputType,
+	args ...any,
+) {
+	caller, showFunction := j.retrieveCallerInfo(logger)
+	msgBuffer := j.getBuffer()
+
+	j.composeMsgInto(
+		msgBuffer,
+		LogLvlSyslogSeverity(logLvlName),
+		caller,
+		showFunction,
+		j.retrieveStacktrace(logger),
+		j.encodedFields(logger),
+		j.castToString(args[0]),
+		j.withMsgError(args[0], args[1:])...,
+	)
+
+	j.printer.PrintLog(outType, msgBuffer, logger.GetOutputWriter(outType))
+	j.putBuffer(msgBuffer)
+}
+
+// Color logs the given message at the INFO level, since the journal entries do not support colors.
+func (j *JournaldEncoder) Color(logger s.LoggerConfigsInterface, _ c.Color, args ...any) {
+	if len(args) > 0 {
+		j.Log(logger, ll.InfoLvlName, s.StdOutput, args...)
+	}
+}
+
+// composeMsgInto formats and writes the given 'msg' into the given buffer.
+func (j *JournaldEncoder) composeMsgInto(
+	buf *bytes.Buffer,
+	severity Severity,
+	caller *s.Caller,
+	showFunction bool,
+	stacktrace string,
+	fields []byte,
+	msg string,
+	extras ...any,
+) {
+	buf.Grow((averageWordLen * len(extras)) + len(msg) + len(fields) + len(j.header) + 40)
+
+	writeJournaldFieldInto(buf, "MESSAGE", msg)
+	buf.WriteString("PRIORITY=")
+	buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uint64(severity), 10))
+	buf.WriteByte('\n')
+	buf.Write(j.header)
+
+	if caller != nil {
+		writeJournaldFieldInto(buf, "CODE_FILE", caller.File)
+		buf.WriteString("CODE_LINE=")
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(caller.Line), 10))
+		buf.WriteByte('\n')
+
+		if showFunction && caller.Function != "" {
+			writeJournaldFieldInto(buf, "CODE_FUNC", caller.Function)
+		}
+	}
+
+	if stacktrace != "" {
+		writeJournaldFieldInto(buf, "STACKTRACE", stacktrace)
+	}
+
+	buf.Write(fields)
+	j.EncodeFields(buf, extras...)
+}
+
+// EncodeFields writes the given key/value pairs into the buffer as journal fields.
+// A missing value for the last key is written as an empty string.
+func (j *JournaldEncoder) EncodeFields(buf *bytes.Buffer, keyVals ...any) {
+	for i := 0; i < len(keyVals); i += 2 {
+		var value string
+		if i+1 < len(keyVals) {
+			value = j.castToString(keyVals[i+1])
+		}
+
+		writeJournaldFieldNameInto(buf, j.castToString(keyVals[i]))
+		writeJournaldValueInto(buf, value)
+	}
+}
+
+// retrieveCallerInfo returns the caller carried by the given logger configs, or nil if there is none,
+// and whether its function name must be written.
+func (j *JournaldEncoder) retrieveCallerInfo(logger s.LoggerConfigsInterface) (*s.Caller, bool) {
+	if provider, ok := logger.(s.CallerProviderInterface); ok {
+		return provider.GetCaller()
+	}
+
+	return nil, false
+}
+
+// encodedFields returns the pre-encoded fields bound to the given logger, or nil if there are none.
+func (j *JournaldEncoder) encodedFields(logger s.LoggerConfigsInterface) []byte {
+	if fields := logger.GetBoundFields(); fields != nil {
+		return fields.Encoded(j)
+	}
+
+	return nil
+}
+
+// writeJournaldFieldInto writes the given journal field into the buffer.
+func writeJournaldFieldInto(buf *bytes.Buffer, name string, value string) {
+	buf.WriteString(name)
+	writeJournaldValueInto(buf, value)
+}
+
+// writeJournaldValueInto writes the given field value into the buffer, following its name: as a "=value" line or,
+// if the value contains a newline, as a newline followed by the value size as a 64-bit little endian integer
+// and the value.
+func writeJournaldValueInto(buf *bytes.Buffer, value string) {
+	if strings.IndexByte(value, '\n') < 0 {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+
+		return
+	}
+
+	buf.WriteByte('\n')
+	buf.Write(binary.LittleEndian.AppendUint64(buf.AvailableBuffer(), uint64(len(value))))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// writeJournaldFieldNameInto writes the given key into the buffer as a valid journal field name: at most 64
+// upper-case letters, digits and underscores, not starting with an underscore, reserved to the trusted fields,
+// nor with a digit.
+func writeJournaldFieldNameInto(buf *bytes.Buffer, key string) {
+	key = strings.TrimLeft(key, "_")
+	maxLen := journaldFieldNameMaxLen
+
+	if key == "" || (key[0] >= '0' && key[0] <= '9') {
+		buf.WriteString("FIELD_")
+		maxLen -= len("FIELD_")
+	}
+
+	if len(key) > maxLen {
+		key = key[:maxLen]
+	}
+
+	for i := 0; i < len(key); i++ {
+		switch ch := key[i]; {
+		case ch >= 'a' && ch <= 'z':
+			buf.WriteByte(ch - 'a' + 'A')
+		case (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') || ch == '_':
+			buf.WriteByte(ch)
+		default:
+			buf.WriteByte('_')
+		}
+	}
+}
+
+// NewJournaldEncoder initializes and returns a new JournaldEncoder with the given configuration.
+func NewJournaldEncoder(config JournaldConfig) *JournaldEncoder {
+	if config.SyslogIdentifier == "" {
+		config.SyslogIdentifier = filepath.Base(os.Args[0])
+	}
+
+	var header bytes.Buffer
+	writeJournaldFieldInto(&header, "SYSLOG_IDENTIFIER", config.SyslogIdentifier)
+
+	encoder := &JournaldEncoder{header: header.Bytes(), printer: services.NewPrinter()}
+	encoder.encoderType = s.JournaldEncoderType
+	encoder.bufferSyncPool = sync.Pool{
+		New: func() any {
+			return new(bytes.Buffer)
+		},
+	}
+
+	return encoder
+}
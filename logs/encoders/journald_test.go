@@ -0,0 +1,113 @@
+package encoders
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Pho3b/tiny-logger/logs/colors"
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	"github.com/Pho3b/tiny-logger/shared"
+	"github.com/Pho3b/tiny-logger/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// journaldConfigMock is a LoggerConfigMock carrying a caller and a stack trace.
+type journaldConfigMock struct {
+	test.LoggerConfigMock
+	caller       *shared.Caller
+	showFunction bool
+	stacktrace   string
+}
+
+func (m *journaldConfigMock) GetCaller() (*shared.Caller, bool) {
+	return m.caller, m.showFunction
+}
+
+func (m *journaldConfigMock) GetStacktrace() string {
+	return m.stacktrace
+}
+
+func TestJournaldEncoder_Log(t *testing.T) {
+	var out bytes.Buffer
+	encoder := NewJournaldEncoder(JournaldConfig{SyslogIdentifier: "api"})
+	loggerConfig := &test.LoggerConfigMock{Output: &out, Fields: shared.NewBoundFields("request_id", 42)}
+
+	encoder.Log(loggerConfig, ll.WarnLvlName, shared.FileOutput, "slow query", "ms", 350, "db.Host", "db-1")
+	assert.Equal(
+		t,
+		"MESSAGE=slow query\nPRIORITY=4\nSYSLOG_IDENTIFIER=api\nREQUEST_ID=42\nMS=350\nDB_HOST=db-1\n",
+		out.String(),
+	)
+
+	out.Reset()
+	encoder.Log(&test.LoggerConfigMock{Output: &out}, ll.ErrorLvlName, shared.FileOutput, errors.New("db down"))
+	assert.Equal(t, "MESSAGE=db down\nPRIORITY=3\nSYSLOG_IDENTIFIER=api\nERROR=db down\n", out.String())
+	assert.Equal(t, shared.JournaldEncoderType, encoder.GetType())
+}
+
+func TestJournaldEncoder_CallerAndStacktrace(t *testing.T) {
+	var out bytes.Buffer
+	encoder := NewJournaldEncoder(JournaldConfig{SyslogIdentifier: "api"})
+	loggerConfig := &journaldConfigMock{
+		LoggerConfigMock: test.LoggerConfigMock{Output: &out},
+		caller:           &shared.Caller{File: "logs/logger.go", Line: 42, Function: "logs.(*Logger).Info"},
+		showFunction:     true,
+		stacktrace:       "goroutine 1 [running]:\nmain.main()",
+	}
+
+	encoder.Log(loggerConfig, ll.ErrorLvlName, shared.FileOutput, "failed")
+	assert.Equal(
+		t,
+		"MESSAGE=failed\nPRIORITY=3\nSYSLOG_IDENTIFIER=api\nCODE_FILE=logs/logger.go\nCODE_LINE=42\n"+
+			"CODE_FUNC=logs.(*Logger).Info\nSTACKTRACE\n\x22\x00\x00\x00\x00\x00\x00\x00goroutine 1 [running]:\nmain.main()\n",
+		out.String(),
+	)
+
+	out.Reset()
+	loggerConfig.showFunction = false
+	loggerConfig.stacktrace = ""
+	encoder.Log(loggerConfig, ll.InfoLvlName, shared.FileOutput, "done")
+	assert.Equal(
+		t,
+		"MESSAGE=done\nPRIORITY=6\nSYSLOG_IDENTIFIER=api\nCODE_FILE=logs/logger.go\nCODE_LINE=42\n",
+		out.String(),
+	)
+}
+
+func TestJournaldEncoder_MultilineValues(t *testing.T) {
+	var out bytes.Buffer
+	encoder := NewJournaldEncoder(JournaldConfig{SyslogIdentifier: "api"})
+
+	encoder.Log(&test.LoggerConfigMock{Output: &out}, ll.DebugLvlName, shared.FileOutput, "first\nsecond", "query", "a\nb")
+	assert.Equal(
+		t,
+		"MESSAGE\n\x0c\x00\x00\x00\x00\x00\x00\x00first\nsecond\nPRIORITY=7\nSYSLOG_IDENTIFIER=api\n"+
+			"QUERY\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n",
+		out.String(),
+	)
+}
+
+func TestJournaldEncoder_Color(t *testing.T) {
+	encoder := NewJournaldEncoder(JournaldConfig{SyslogIdentifier: "api"})
+
+	output := test.CaptureOutput(func() { encoder.Color(&test.LoggerConfigMock{}, colors.Cyan, "colored msg") })
+	assert.Equal(t, "MESSAGE=colored msg\nPRIORITY=6\nSYSLOG_IDENTIFIER=api\n", output)
+}
+
+func TestWriteJournaldFieldNameInto(t *testing.T) {
+	for key, expected := range map[string]string{
+		"user":                  "USER",
+		"_SYSTEMD_UNIT":         "SYSTEMD_UNIT",
+		"http.status-code":      "HTTP_STATUS_CODE",
+		"2fa":                   "FIELD_2FA",
+		"":                      "FIELD_",
+		"__":                    "FIELD_",
+		strings.Repeat("k", 70): strings.Repeat("K", 64),
+	} {
+		var buf bytes.Buffer
+		writeJournaldFieldNameInto(&buf, key)
+		assert.Equal(t, expected, buf.String(), key)
+	}
+}
@@ -3,6 +3,7 @@ package encoders
 import (
 	"bytes"
 	"sync"
+	"time"
 
 	"github.com/Pho3b/tiny-logger/internal/services"
 	c "github.com/Pho3b/tiny-logger/logs/colors"
@@ -26,6 +27,8 @@ func (j *JSONEncoder) Log(
 	args ...any,
 ) {
 	dEnabled, tEnabled := logger.GetDateTimeEnabled()
+	caller, function := j.retrieveCaller(logger)
+	stacktrace := j.retrieveStacktrace(logger)
 	msgBuffer := j.getBuffer()
 
 	j.composeMsgInto(
@@ -36,12 +39,17 @@ func (j *JSONEncoder) Log(
 		tEnabled,
 		logger.GetShowLogLevel(),
 		logger.GetDateTimeFormat(),
+		j.retrieveEntryTime(logger),
+		caller,
+		function,
+		stacktrace,
+		j.encodedFields(logger),
 		j.castToString(args[0]),
-		args[1:]...,
+		j.withMsgError(args[0], args[1:])...,
 	)
 
 	msgBuffer.WriteByte('\n')
-	j.printer.PrintLog(outType, msgBuffer, logger.GetLogFile())
+	j.printer.PrintLog(outType, msgBuffer, logger.GetOutputWriter(outType))
 	j.putBuffer(msgBuffer)
 }
 
@@ -60,13 +68,18 @@ func (j *JSONEncoder) Color(logger s.LoggerConfigsInterface, color c.Color, args
 			tEnabled,
 			false,
 			logger.GetDateTimeFormat(),
+			time.Time{},
+			"",
+			"",
+			"",
+			nil,
 			j.castToString(args[0]),
 			args[1:]...,
 		)
 
 		msgBuffer.WriteString(c.Reset.String())
 		msgBuffer.WriteByte('\n')
-		j.printer.PrintLog(s.StdOutput, msgBuffer, logger.GetLogFile())
+		j.printer.PrintLog(s.StdOutput, msgBuffer, logger.GetOutputWriter(s.StdOutput))
 		j.putBuffer(msgBuffer)
 	}
 }
@@ -80,11 +93,16 @@ func (j *JSONEncoder) composeMsgInto(
 	timeEnabled bool,
 	showLogLevel bool,
 	dateTimeFormat s.DateTimeFormat,
+	entryTime time.Time,
+	caller string,
+	function string,
+	stacktrace string,
+	fields []byte,
 	msg string,
 	extras ...any,
 ) {
 	buf.Grow((averageWordLen * len(extras)) + len(msg) + 60)
-	dateStr, timeStr, unixTs := j.DateTimePrinter.RetrieveDateTime(dateTimeFormat, dateEnabled, timeEnabled)
+	dateStr, timeStr, unixTs := j.DateTimePrinter.RetrieveDateTimeAt(entryTime, dateTimeFormat, dateEnabled, timeEnabled)
 
 	if !showLogLevel {
 		logLevel = ""
@@ -93,16 +111,41 @@ func (j *JSONEncoder) composeMsgInto(
 	jsonMarshaler.MarshalInto(
 		buf,
 		services.JsonLogEntry{
-			Level:   logLevel.String(),
-			Date:    dateStr,
-			Time:    timeStr,
-			UnixTS:  unixTs,
-			Message: msg,
-			Extras:  extras,
+			Level:      logLevel.String(),
+			Date:       dateStr,
+			Time:       timeStr,
+			UnixTS:     unixTs,
+			Caller:     caller,
+			Function:   function,
+			Message:    msg,
+			Extras:     extras,
+			Stacktrace: stacktrace,
+			Fields:     fields,
 		},
 	)
 }
 
+// SetEscapeHTML enables or disables the escaping of '<', '>' and '&' inside the JSON strings.
+func (j *JSONEncoder) SetEscapeHTML(enable bool) *JSONEncoder {
+	j.jsonMarshaler.SetEscapeHTML(enable)
+
+	return j
+}
+
+// EncodeFields writes the given key/value pairs into the buffer in the format used for the entry extras.
+func (j *JSONEncoder) EncodeFields(buf *bytes.Buffer, keyVals ...any) {
+	j.jsonMarshaler.MarshalFieldsInto(buf, keyVals...)
+}
+
+// encodedFields returns the pre-encoded fields bound to the given logger, or nil if there are none.
+func (j *JSONEncoder) encodedFields(logger s.LoggerConfigsInterface) []byte {
+	if fields := logger.GetBoundFields(); fields != nil {
+		return fields.Encoded(j)
+	}
+
+	return nil
+}
+
 // NewJSONEncoder initializes and returns a new JSONEncoder instance.
 func NewJSONEncoder(
 	printer services.Printer,
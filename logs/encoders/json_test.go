@@ -2,6 +2,8 @@ package encoders
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"testing"
@@ -284,3 +286,36 @@ func TestJSONEncoder_ValidJSONOutput(t *testing.T) {
 
 	os.Stdout = originalStdOut
 }
+
+func TestJSONEncoder_BoundFields(t *testing.T) {
+	encoder := NewJSONEncoder(services.NewPrinter(), services.NewJsonMarshaler(), services.GetDateTimePrinter())
+	loggerConfig := &test.LoggerConfigMock{ShowLogLevel: true, Fields: shared.NewBoundFields("user", "alice", "id", 3)}
+
+	output := test.CaptureOutput(func() {
+		encoder.Log(loggerConfig, ll.InfoLvlName, shared.StdOutput, "Test msg", "ip", "192.168.1.1")
+	})
+	assert.Equal(t, "{\"level\":\"INFO\",\"msg\":\"Test msg\",\"extras\":{\"user\":\"alice\",\"id\":3,\"ip\":\"192.168.1.1\"}}\n", output)
+
+	output = test.CaptureOutput(func() {
+		encoder.Log(loggerConfig, ll.InfoLvlName, shared.StdOutput, "Test msg")
+	})
+	entry := decodeLogEntry(t, output)
+	assert.Equal(t, "alice", entry.Extras["user"])
+	assert.Equal(t, float64(3), entry.Extras["id"])
+}
+
+func TestJSONEncoder_ErrorMessage(t *testing.T) {
+	encoder := NewJSONEncoder(services.NewPrinter(), services.NewJsonMarshaler(), services.GetDateTimePrinter())
+	loggerConfig := &test.LoggerConfigMock{ShowLogLevel: true}
+	err := fmt.Errorf("saving user: %w", errors.New("disk full"))
+
+	output := test.CaptureOutput(func() {
+		encoder.Log(loggerConfig, ll.ErrorLvlName, shared.StdOutput, err, "id", 3)
+	})
+	assert.Equal(
+		t,
+		`{"level":"ERROR","msg":"saving user: disk full","extras":{"error":{"msg":"saving user: disk full",`+
+			`"type":"*fmt.wrapError","cause":{"msg":"disk full","type":"*errors.errorString"}},"id":3}}`+"\n",
+		output,
+	)
+}
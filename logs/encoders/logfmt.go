@@ -0,0 +1,157 @@
+package encoders
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/Pho3b/tiny-logger/internal/services"
+	c "github.com/Pho3b/tiny-logger/logs/colors"
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+type LogfmtEncoder struct {
+	baseEncoder
+	DateTimePrinter *services.DateTimePrinter
+	logfmtMarshaler services.LogfmtMarshaler
+	printer         services.Printer
+}
+
+// Log formats and prints a log message to the given output type.
+// Internally used by all the encoder Log methods.
+func (l *LogfmtEncoder) Log(
+	logger s.LoggerConfigsInterface,
+	logLvlName ll.LogLvlName,
+	outType s.OutputType,
+	args ...any,
+) {
+	dEnabled, tEnabled := logger.GetDateTimeEnabled()
+	caller, function := l.retrieveCaller(logger)
+	stacktrace := l.retrieveStacktrace(logger)
+	msgBuffer := l.getBuffer()
+
+	l.composeMsgInto(
+		msgBuffer,
+		l.logfmtMarshaler,
+		logLvlName,
+		dEnabled,
+		tEnabled,
+		logger.GetShowLogLevel(),
+		logger.GetDateTimeFormat(),
+		l.retrieveEntryTime(logger),
+		caller,
+		function,
+		stacktrace,
+		l.encodedFields(logger),
+		l.castToString(args[0]),
+		args[1:]...,
+	)
+
+	msgBuffer.WriteByte('\n')
+	l.printer.PrintLog(outType, msgBuffer, logger.GetOutputWriter(outType))
+	l.putBuffer(msgBuffer)
+}
+
+// Color formats and prints a colored Log message using the specified color.
+func (l *LogfmtEncoder) Color(logger s.LoggerConfigsInterface, color c.Color, args ...any) {
+	if len(args) > 0 {
+		dEnabled, tEnabled := logger.GetDateTimeEnabled()
+		msgBuffer := l.getBuffer()
+		msgBuffer.WriteString(color.String())
+
+		l.composeMsgInto(
+			msgBuffer,
+			l.logfmtMarshaler,
+			ll.InfoLvlName,
+			dEnabled,
+			tEnabled,
+			false,
+			logger.GetDateTimeFormat(),
+			time.Time{},
+			"",
+			"",
+			"",
+			nil,
+			l.castToString(args[0]),
+			args[1:]...,
+		)
+
+		msgBuffer.WriteString(c.Reset.String())
+		msgBuffer.WriteByte('\n')
+		l.printer.PrintLog(s.StdOutput, msgBuffer, logger.GetOutputWriter(s.StdOutput))
+		l.putBuffer(msgBuffer)
+	}
+}
+
+// composeMsgInto formats and writes the given 'msg' into the given buffer.
+func (l *LogfmtEncoder) composeMsgInto(
+	buf *bytes.Buffer,
+	logfmtMarshaler services.LogfmtMarshaler,
+	logLevel ll.LogLvlName,
+	dateEnabled bool,
+	timeEnabled bool,
+	showLogLevel bool,
+	dateTimeFormat s.DateTimeFormat,
+	entryTime time.Time,
+	caller string,
+	function string,
+	stacktrace string,
+	fields []byte,
+	msg string,
+	extras ...any,
+) {
+	buf.Grow((averageWordLen * len(extras)) + len(msg) + 60)
+	dateStr, timeStr, unixTs := l.DateTimePrinter.RetrieveDateTimeAt(entryTime, dateTimeFormat, dateEnabled, timeEnabled)
+
+	if !showLogLevel {
+		logLevel = ""
+	}
+
+	logfmtMarshaler.MarshalInto(
+		buf,
+		services.LogfmtLogEntry{
+			Level:      logLevel.String(),
+			Date:       dateStr,
+			Time:       timeStr,
+			UnixTS:     unixTs,
+			Caller:     caller,
+			Function:   function,
+			Message:    msg,
+			Extras:     extras,
+			Stacktrace: stacktrace,
+			Fields:     fields,
+		},
+	)
+}
+
+// EncodeFields writes the given key/value pairs into the buffer in the format used for the entry extras.
+func (l *LogfmtEncoder) EncodeFields(buf *bytes.Buffer, keyVals ...any) {
+	l.logfmtMarshaler.MarshalFieldsInto(buf, keyVals...)
+}
+
+// encodedFields returns the pre-encoded fields bound to the given logger, or nil if there are none.
+func (l *LogfmtEncoder) encodedFields(logger s.LoggerConfigsInterface) []byte {
+	if fields := logger.GetBoundFields(); fields != nil {
+		return fields.Encoded(l)
+	}
+
+	return nil
+}
+
+// NewLogfmtEncoder initializes and returns a new LogfmtEncoder instance.
+func NewLogfmtEncoder(
+	printer services.Printer,
+	logfmtMarshaler services.LogfmtMarshaler,
+	dateTimePrinter *services.DateTimePrinter,
+) *LogfmtEncoder {
+	encoder := &LogfmtEncoder{DateTimePrinter: dateTimePrinter, logfmtMarshaler: logfmtMarshaler, printer: printer}
+	encoder.encoderType = s.LogfmtEncoderType
+	encoder.bufferSyncPool = sync.Pool{
+		New: func() any {
+			return new(bytes.Buffer)
+		},
+	}
+
+	return encoder
+}
@@ -0,0 +1,71 @@
+package encoders
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Pho3b/tiny-logger/internal/services"
+	"github.com/Pho3b/tiny-logger/logs/colors"
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	"github.com/Pho3b/tiny-logger/shared"
+	"github.com/Pho3b/tiny-logger/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLogfmtEncoder() *LogfmtEncoder {
+	return NewLogfmtEncoder(services.NewPrinter(), services.NewLogfmtMarshaler(), services.GetDateTimePrinter())
+}
+
+func TestLogfmtEncoder_Log(t *testing.T) {
+	encoder := newTestLogfmtEncoder()
+	loggerConfig := &test.LoggerConfigMock{ShowLogLevel: true}
+
+	output := test.CaptureOutput(func() {
+		encoder.Log(loggerConfig, ll.InfoLvlName, shared.StdOutput, "user logged in", "user", "alice", "ip", "10.0.0.1")
+	})
+	assert.Equal(t, "level=info msg=\"user logged in\" user=alice ip=10.0.0.1\n", output)
+
+	output = test.CaptureErrorOutput(func() {
+		encoder.Log(loggerConfig, ll.ErrorLvlName, shared.StdErrOutput, "failed", "err", "connection refused", "retry")
+	})
+	assert.Equal(t, "level=error msg=failed err=\"connection refused\" retry=null\n", output)
+}
+
+func TestLogfmtEncoder_DateTime(t *testing.T) {
+	encoder := newTestLogfmtEncoder()
+	loggerConfig := &test.LoggerConfigMock{DateEnabled: true, TimeEnabled: true, ShowLogLevel: true}
+
+	output := test.CaptureOutput(func() {
+		encoder.Log(loggerConfig, ll.WarnLvlName, shared.StdOutput, "Test msg")
+	})
+	assert.Regexp(t, regexp.MustCompile(`^level=warn datetime="\d{2}/\d{2}/\d{4} \d{2}:\d{2}:\d{2}" msg="Test msg"\n$`), output)
+
+	loggerConfig = &test.LoggerConfigMock{DateEnabled: true, TimeEnabled: true, DateTimeFormat: shared.UnixTimestamp}
+	output = test.CaptureOutput(func() {
+		encoder.Log(loggerConfig, ll.WarnLvlName, shared.StdOutput, "Test msg")
+	})
+	assert.Regexp(t, regexp.MustCompile(`^ts=\d+ msg="Test msg"\n$`), output)
+}
+
+func TestLogfmtEncoder_Color(t *testing.T) {
+	encoder := newTestLogfmtEncoder()
+	loggerConfig := &test.LoggerConfigMock{DateEnabled: true}
+
+	output := test.CaptureOutput(func() { encoder.Color(loggerConfig, colors.Cyan, "colored msg", "id", 3) })
+	assert.Contains(t, output, colors.Cyan.String())
+	assert.Contains(t, output, time.Now().Format("02/01/2006"))
+	assert.Contains(t, output, `msg="colored msg" id=3`)
+	assert.Contains(t, output, colors.Reset.String())
+}
+
+func TestLogfmtEncoder_BoundFields(t *testing.T) {
+	encoder := newTestLogfmtEncoder()
+	loggerConfig := &test.LoggerConfigMock{ShowLogLevel: true, Fields: shared.NewBoundFields("user", "alice smith")}
+
+	output := test.CaptureOutput(func() {
+		encoder.Log(loggerConfig, ll.InfoLvlName, shared.StdOutput, "Test msg", "id", 3)
+	})
+	assert.Equal(t, "level=info msg=\"Test msg\" user=\"alice smith\" id=3\n", output)
+	assert.Equal(t, shared.LogfmtEncoderType, encoder.GetType())
+}
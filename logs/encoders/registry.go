@@ -0,0 +1,74 @@
+package encoders
+
+import (
+	"errors"
+	"sync"
+
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+// EncoderFactory returns a new instance of a custom encoder.
+type EncoderFactory func() s.EncoderInterface
+
+var (
+	// ErrBuiltInEncoderType is returned when registering a custom encoder under a built-in encoder type.
+	ErrBuiltInEncoderType = errors.New("tiny-logger: built-in encoder types cannot be registered")
+	// ErrNilEncoderFactory is returned when registering a nil encoder factory.
+	ErrNilEncoderFactory = errors.New("tiny-logger: encoder factory is nil")
+
+	registryMu sync.RWMutex
+	registry   = map[s.EncoderType]EncoderFactory{}
+)
+
+// Register makes a custom encoder available under the given type, so that it can be selected
+// through Logger.SetEncoder and Logger.AddDestination like the built-in ones.
+// Registering the same type twice replaces the previous factory.
+func Register(encoderType s.EncoderType, factory EncoderFactory) error {
+	if factory == nil {
+		return ErrNilEncoderFactory
+	}
+
+	if IsBuiltIn(encoderType) {
+		return ErrBuiltInEncoderType
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[encoderType] = factory
+
+	return nil
+}
+
+// Unregister removes the custom encoder registered under the given type, if any.
+func Unregister(encoderType s.EncoderType) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	delete(registry, encoderType)
+}
+
+// NewRegistered returns a new instance of the custom encoder registered under the given type,
+// or nil if no encoder has been registered under it.
+func NewRegistered(encoderType s.EncoderType) s.EncoderInterface {
+	registryMu.RLock()
+	factory, found := registry[encoderType]
+	registryMu.RUnlock()
+
+	if !found {
+		return nil
+	}
+
+	return factory()
+}
+
+// IsBuiltIn returns true if the given encoder type is one of the built-in ones.
+func IsBuiltIn(encoderType s.EncoderType) bool {
+	switch encoderType {
+	case s.DefaultEncoderType, s.JsonEncoderType, s.YamlEncoderType, s.LogfmtEncoderType,
+		s.SyslogEncoderType, s.JournaldEncoderType:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+package encoders
+
+import (
+	"bytes"
+	"testing"
+
+	c "github.com/Pho3b/tiny-logger/logs/colors"
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	"github.com/Pho3b/tiny-logger/shared"
+	"github.com/Pho3b/tiny-logger/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const pipeEncoderType shared.EncoderType = "pipe"
+
+// pipeEncoder is a custom encoder writing the log entries as pipe separated values.
+type pipeEncoder struct {
+	*BaseEncoder
+}
+
+func (p *pipeEncoder) Log(logger shared.LoggerConfigsInterface, lvl ll.LogLvlName, outType shared.OutputType, args ...any) {
+	buf := p.GetBuffer()
+	dateStr, timeStr, _ := p.RetrieveDateTime(logger)
+
+	buf.WriteString(dateStr + "|" + timeStr + "|" + lvl.String() + "|")
+	for i, arg := range args {
+		if i > 0 {
+			buf.WriteByte('|')
+		}
+
+		buf.WriteString(p.CastToString(arg))
+	}
+
+	buf.WriteByte('\n')
+	p.PrintLog(logger, outType, buf)
+	p.PutBuffer(buf)
+}
+
+func (p *pipeEncoder) Color(logger shared.LoggerConfigsInterface, color c.Color, args ...any) {
+	buf := p.GetBuffer()
+	buf.WriteString(color.String())
+	p.CastAndConcatenateInto(buf, args...)
+	buf.WriteString(c.Reset.String() + "\n")
+	p.PrintLog(logger, shared.StdOutput, buf)
+	p.PutBuffer(buf)
+}
+
+func newPipeEncoder() shared.EncoderInterface {
+	return &pipeEncoder{BaseEncoder: NewBaseEncoder(pipeEncoderType)}
+}
+
+func TestRegister(t *testing.T) {
+	defer Unregister(pipeEncoderType)
+
+	assert.Nil(t, NewRegistered(pipeEncoderType))
+	assert.ErrorIs(t, Register(pipeEncoderType, nil), ErrNilEncoderFactory)
+	assert.ErrorIs(t, Register(shared.JsonEncoderType, newPipeEncoder), ErrBuiltInEncoderType)
+	assert.NoError(t, Register(pipeEncoderType, newPipeEncoder))
+
+	encoder := NewRegistered(pipeEncoderType)
+	assert.NotNil(t, encoder)
+	assert.Equal(t, pipeEncoderType, encoder.GetType())
+	assert.NotSame(t, encoder, NewRegistered(pipeEncoderType))
+
+	Unregister(pipeEncoderType)
+	assert.Nil(t, NewRegistered(pipeEncoderType))
+}
+
+func TestIsBuiltIn(t *testing.T) {
+	assert.True(t, IsBuiltIn(shared.DefaultEncoderType))
+	assert.True(t, IsBuiltIn(shared.JsonEncoderType))
+	assert.True(t, IsBuiltIn(shared.YamlEncoderType))
+	assert.True(t, IsBuiltIn(shared.LogfmtEncoderType))
+	assert.False(t, IsBuiltIn(pipeEncoderType))
+}
+
+func TestBaseEncoder_Helpers(t *testing.T) {
+	var out bytes.Buffer
+	encoder := newPipeEncoder()
+	loggerConfig := &test.LoggerConfigMock{Output: &out}
+
+	encoder.Log(loggerConfig, ll.WarnLvlName, shared.FileOutput, "disk usage", 93.5, true)
+	assert.Equal(t, "||WARN|disk usage|93.5|true\n", out.String())
+
+	output := test.CaptureOutput(func() { encoder.Color(loggerConfig, c.Blue, "colored", 1) })
+	assert.Equal(t, c.Blue.String()+"colored 1"+c.Reset.String()+"\n", output)
+
+	base := NewBaseEncoder(pipeEncoderType)
+	assert.Equal(t, c.Color(""), base.RetrieveLogLvlColor(loggerConfig, ll.ErrorLvlName))
+	assert.Equal(t, c.Red, base.RetrieveLogLvlColor(&test.LoggerConfigMock{ColorsEnabled: true}, ll.ErrorLvlName))
+
+	dateStr, timeStr, unixTs := base.RetrieveDateTime(&test.LoggerConfigMock{DateEnabled: true, TimeEnabled: true})
+	assert.NotEmpty(t, dateStr)
+	assert.NotEmpty(t, timeStr)
+	assert.Empty(t, unixTs)
+}
@@ -0,0 +1,380 @@
+package encoders
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Pho3b/tiny-logger/internal/services"
+	c "github.com/Pho3b/tiny-logger/logs/colors"
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+const (
+	// syslogNilValue is the RFC 5424 NILVALUE, written in place of the empty header fields.
+	syslogNilValue = "-"
+	// defaultSyslogSDID is the structured data ID used when SyslogConfig.SDID is not set.
+	// 32473 is the private enterprise number reserved for documentation by RFC 5612.
+	defaultSyslogSDID = "fields@32473"
+	// rfc5424TimeFormat is the RFC 3339 time format, with microseconds, used by the RFC 5424 entries.
+	rfc5424TimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+// SyslogFormat is the Enum representing the syslog protocol versions the SyslogEncoder can frame the entries as.
+type SyslogFormat int8
+
+const (
+	RFC5424Format SyslogFormat = 0
+	RFC3164Format SyslogFormat = 1
+)
+
+// Facility is the Enum representing the syslog facilities, identifying the kind of program logging an entry.
+type Facility uint8
+
+const (
+	KernFacility     Facility = 0
+	UserFacility     Facility = 1
+	MailFacility     Facility = 2
+	DaemonFacility   Facility = 3
+	AuthFacility     Facility = 4
+	SyslogFacility   Facility = 5
+	LprFacility      Facility = 6
+	NewsFacility     Facility = 7
+	UucpFacility     Facility = 8
+	CronFacility     Facility = 9
+	AuthPrivFacility Facility = 10
+	FtpFacility      Facility = 11
+	Local0Facility   Facility = 16
+	Local1Facility   Facility = 17
+	Local2Facility   Facility = 18
+	Local3Facility   Facility = 19
+	Local4Facility   Facility = 20
+	Local5Facility   Facility = 21
+	Local6Facility   Facility = 22
+	Local7Facility   Facility = 23
+)
+
+// Severity is the Enum representing the syslog severities.
+type Severity uint8
+
+const (
+	EmergencySeverity Severity = 0
+	AlertSeverity     Severity = 1
+	CriticalSeverity  Severity = 2
+	ErrorSeverity     Severity = 3
+	WarningSeverity   Severity = 4
+	NoticeSeverity    Severity = 5
+	InfoSeverity      Severity = 6
+	DebugSeverity     Severity = 7
+)
+
+// SyslogConfig holds the SyslogEncoder settings. Zero values fall back to the documented defaults.
+type SyslogConfig struct {
+	// Format is the protocol version the entries are framed as. Defaults to RFC5424Format.
+	Format SyslogFormat
+	// Facility is the facility of the entries. Defaults to UserFacility when nil, since KernFacility is reserved
+	// to the kernel messages.
+	Facility *Facility
+	// Hostname is the name of the host the entries come from. Defaults to the one reported by the kernel.
+	// Set it to "-" to leave it to the syslog daemon: it is written as the RFC 5424 NILVALUE and it is omitted
+	// from the RFC 3164 entries, as expected by the daemons listening on the local socket.
+	Hostname string
+	// AppName is the name of the application logging the entries. Defaults to the executable name.
+	AppName string
+	// ProcID is the process identifier of the entries. Defaults to the process ID.
+	ProcID string
+	// MsgID is the type of the entries, used by RFC 5424 only. Defaults to "-".
+	MsgID string
+	// SDID is the ID of the structured data element the extras and the bound fields are written into,
+	// used by RFC 5424 only. Defaults to "fields@32473".
+	SDID string
+}
+
+// SyslogEncoder frames the entries as RFC 5424 or RFC 3164 syslog messages, mapping the log levels to the syslog
+// severities. The extras, the bound fields and the caller are written as RFC 5424 structured data, or appended to
+// the message as key="value" pairs by RFC 3164.
+// Entries are not newline terminated, since their framing is up to the transport: see sinks.SyslogWriter.
+type SyslogEncoder struct {
+	baseEncoder
+	format   SyslogFormat
+	facility Facility
+	// header holds the pre-formatted header fields following the timestamp
+	header  string
+	sdID    string
+	printer services.Printer
+	now     func() time.Time
+}
+
+// Log formats and prints a log message to the given output type.
+// The Logger date/time and log level visibility settings are ignored, since they are part of the syslog header.
+func (sl *SyslogEncoder) Log(
+	logger s.LoggerConfigsInterface,
+	logLvlName ll.LogLvlName,
+	outType s.OutputType,
+	args ...any,
+) {
+	caller, function := sl.retrieveCaller(logger)
+	stacktrace := sl.retrieveStacktrace(logger)
+	msgBuffer := sl.getBuffer()
+
+	timestamp := sl.retrieveEntryTime(logger)
+	if timestamp.IsZero() {
+		timestamp = sl.now()
+	}
+
+	sl.composeMsgInto(
+		msgBuffer,
+		timestamp,
+		LogLvlSyslogSeverity(logLvlName),
+		caller,
+		function,
+		stacktrace,
+		sl.encodedFields(logger),
+		sl.castToString(args[0]),
+		args[1:]...,
+	)
+
+	sl.printer.PrintLog(outType, msgBuffer, logger.GetOutputWriter(outType))
+	sl.putBuffer(msgBuffer)
+}
+
+// Color logs the given message at the INFO level, since the syslog messages do not support colors.
+func (sl *SyslogEncoder) Color(logger s.LoggerConfigsInterface, _ c.Color, args ...any) {
+	if len(args) > 0 {
+		sl.Log(logger, ll.InfoLvlName, s.StdOutput, args...)
+	}
+}
+
+// composeMsgInto formats and writes the given 'msg' into the given buffer.
+func (sl *SyslogEncoder) composeMsgInto(
+	buf *bytes.Buffer,
+	now time.Time,
+	severity Severity,
+	caller string,
+	function string,
+	stacktrace string,
+	fields []byte,
+	msg string,
+	extras ...any,
+) {
+	buf.Grow((averageWordLen * len(extras)) + len(msg) + len(fields) + len(sl.header) + 60)
+
+	buf.WriteByte('<')
+	buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uint64(sl.facility)*8+uint64(severity), 10))
+	buf.WriteByte('>')
+
+	if sl.format == RFC3164Format {
+		buf.Write(now.AppendFormat(buf.AvailableBuffer(), time.Stamp))
+		buf.WriteString(sl.header)
+		buf.WriteString(msg)
+		sl.writeParamsInto(buf, caller, function, fields, extras)
+	} else {
+		buf.WriteString("1 ")
+		buf.Write(now.AppendFormat(buf.AvailableBuffer(), rfc5424TimeFormat))
+		buf.WriteString(sl.header)
+
+		if caller == "" && len(fields) == 0 && len(extras) == 0 {
+			buf.WriteString(syslogNilValue)
+		} else {
+			buf.WriteByte('[')
+			buf.WriteString(sl.sdID)
+			sl.writeParamsInto(buf, caller, function, fields, extras)
+			buf.WriteByte(']')
+		}
+
+		if msg != "" || stacktrace != "" {
+			buf.WriteByte(' ')
+			buf.WriteString(msg)
+		}
+	}
+
+	if stacktrace != "" {
+		buf.WriteByte('\n')
+		buf.WriteString(stacktrace)
+	}
+}
+
+// writeParamsInto writes the caller, the given pre-encoded fields and the extras into the buffer
+// as space separated key="value" pairs.
+func (sl *SyslogEncoder) writeParamsInto(buf *bytes.Buffer, caller, function string, fields []byte, extras []any) {
+	if caller != "" {
+		sl.EncodeFields(buf, "caller", caller)
+	}
+
+	if function != "" {
+		sl.EncodeFields(buf, "func", function)
+	}
+
+	buf.Write(fields)
+	sl.EncodeFields(buf, extras...)
+}
+
+// EncodeFields writes the given key/value pairs into the buffer as RFC 5424 structured data parameters,
+// each one preceded by a white space. A missing value for the last key is written as an empty string.
+func (sl *SyslogEncoder) EncodeFields(buf *bytes.Buffer, keyVals ...any) {
+	for i := 0; i < len(keyVals); i += 2 {
+		buf.WriteByte(' ')
+		writeSyslogParamName(buf, sl.castToString(keyVals[i]))
+		buf.WriteString(`="`)
+
+		if i+1 < len(keyVals) {
+			writeSyslogParamValue(buf, sl.castToString(keyVals[i+1]))
+		}
+
+		buf.WriteByte('"')
+	}
+}
+
+// encodedFields returns the pre-encoded fields bound to the given logger, or nil if there are none.
+func (sl *SyslogEncoder) encodedFields(logger s.LoggerConfigsInterface) []byte {
+	if fields := logger.GetBoundFields(); fields != nil {
+		return fields.Encoded(sl)
+	}
+
+	return nil
+}
+
+// writeSyslogParamName writes the given name into the buffer as a valid RFC 5424 SD-NAME:
+// at most 32 printable ASCII characters, replacing '=', ']', '"' and the white spaces with an underscore.
+func writeSyslogParamName(buf *bytes.Buffer, name string) {
+	if name == "" {
+		buf.WriteByte('_')
+		return
+	}
+
+	if len(name) > 32 {
+		name = name[:32]
+	}
+
+	for i := 0; i < len(name); i++ {
+		if ch := name[i]; ch <= ' ' || ch > '~' || ch == '=' || ch == ']' || ch == '"' {
+			buf.WriteByte('_')
+		} else {
+			buf.WriteByte(ch)
+		}
+	}
+}
+
+// writeSyslogParamValue writes the given value into the buffer, escaping '"', '\' and ']' as required by RFC 5424.
+func writeSyslogParamValue(buf *bytes.Buffer, value string) {
+	for i := 0; i < len(value); i++ {
+		if ch := value[i]; ch == '"' || ch == '\\' || ch == ']' {
+			buf.WriteByte('\\')
+		}
+
+		buf.WriteByte(value[i])
+	}
+}
+
+// syslogHeaderField returns the given header field value as printable ASCII characters, truncated to maxLen,
+// or the RFC 5424 NILVALUE if it is empty.
+func syslogHeaderField(value string, maxLen int) string {
+	if value == "" {
+		return syslogNilValue
+	}
+
+	if len(value) > maxLen {
+		value = value[:maxLen]
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+
+		return r
+	}, value)
+}
+
+// LogLvlSyslogSeverity returns the syslog severity of the given log level.
+// The custom log levels are mapped according to their value: the ones more severe than PANIC are emergencies,
+// the ones between WARN and INFO are notices, while the other ones share the severity of the closest built-in
+// level they are more severe than, down to the debug entries.
+func LogLvlSyslogSeverity(logLvlName ll.LogLvlName) Severity {
+	lvl, found := ll.LogLvlNameToInt[logLvlName]
+	if !found {
+		return DebugSeverity
+	}
+
+	switch {
+	case lvl < ll.PanicLvl:
+		return EmergencySeverity
+	case lvl == ll.PanicLvl:
+		return AlertSeverity
+	case lvl <= ll.FatalErrorLvl:
+		return CriticalSeverity
+	case lvl <= ll.ErrorLvl:
+		return ErrorSeverity
+	case lvl <= ll.WarnLvl:
+		return WarningSeverity
+	case lvl < ll.InfoLvl:
+		return NoticeSeverity
+	case lvl == ll.InfoLvl:
+		return InfoSeverity
+	default:
+		return DebugSeverity
+	}
+}
+
+// NewSyslogEncoder initializes and returns a new SyslogEncoder with the given configuration.
+func NewSyslogEncoder(config SyslogConfig) *SyslogEncoder {
+	facility := UserFacility
+	if config.Facility != nil {
+		facility = *config.Facility
+	}
+
+	if config.Hostname == "" {
+		config.Hostname, _ = os.Hostname()
+	}
+
+	if config.AppName == "" {
+		config.AppName = filepath.Base(os.Args[0])
+	}
+
+	if config.ProcID == "" {
+		config.ProcID = strconv.Itoa(os.Getpid())
+	}
+
+	if config.SDID == "" {
+		config.SDID = defaultSyslogSDID
+	}
+
+	hostname := syslogHeaderField(config.Hostname, 255)
+	appName := syslogHeaderField(config.AppName, 48)
+	procID := syslogHeaderField(config.ProcID, 128)
+
+	var header string
+	if config.Format == RFC3164Format {
+		header = " " + appName + "[" + procID + "]: "
+		if hostname != syslogNilValue {
+			header = " " + hostname + header
+		}
+	} else {
+		header = " " + hostname + " " + appName + " " + procID + " " + syslogHeaderField(config.MsgID, 32) + " "
+	}
+
+	var sdID bytes.Buffer
+	writeSyslogParamName(&sdID, config.SDID)
+
+	encoder := &SyslogEncoder{
+		format:   config.Format,
+		facility: facility,
+		header:   header,
+		sdID:     sdID.String(),
+		printer:  services.NewPrinter(),
+		now:      time.Now,
+	}
+	encoder.encoderType = s.SyslogEncoderType
+	encoder.bufferSyncPool = sync.Pool{
+		New: func() any {
+			return new(bytes.Buffer)
+		},
+	}
+
+	return encoder
+}
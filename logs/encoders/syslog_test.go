@@ -0,0 +1,141 @@
+package encoders
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Pho3b/tiny-logger/logs/colors"
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	"github.com/Pho3b/tiny-logger/shared"
+	"github.com/Pho3b/tiny-logger/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSyslogEncoder(config SyslogConfig) *SyslogEncoder {
+	encoder := NewSyslogEncoder(config)
+	encoder.now = func() time.Time { return time.Date(2024, 3, 11, 18, 35, 43, 123456000, time.UTC) }
+
+	return encoder
+}
+
+func TestSyslogEncoder_Log_RFC5424(t *testing.T) {
+	var out bytes.Buffer
+	facility := Local0Facility
+	encoder := newTestSyslogEncoder(SyslogConfig{Facility: &facility, Hostname: "web-1", AppName: "api", ProcID: "42"})
+	loggerConfig := &test.LoggerConfigMock{Output: &out}
+
+	encoder.Log(loggerConfig, ll.InfoLvlName, shared.FileOutput, "user logged in")
+	assert.Equal(t, "<134>1 2024-03-11T18:35:43.123456Z web-1 api 42 - - user logged in", out.String())
+
+	out.Reset()
+	encoder.Log(loggerConfig, ll.ErrorLvlName, shared.FileOutput, errors.New("db down"), "host", "db-1", "retry", 3)
+	assert.Equal(
+		t,
+		`<131>1 2024-03-11T18:35:43.123456Z web-1 api 42 - [fields@32473 host="db-1" retry="3"] db down`,
+		out.String(),
+	)
+}
+
+func TestSyslogEncoder_Facility(t *testing.T) {
+	var out bytes.Buffer
+	loggerConfig := &test.LoggerConfigMock{Output: &out}
+
+	encoder := newTestSyslogEncoder(SyslogConfig{Hostname: "-", AppName: "api", ProcID: "42"})
+	encoder.Log(loggerConfig, ll.WarnLvlName, shared.FileOutput, "default facility")
+	assert.Equal(t, "<12>1 2024-03-11T18:35:43.123456Z - api 42 - - default facility", out.String())
+
+	// The kernel facility can be selected explicitly
+	out.Reset()
+	facility := KernFacility
+	encoder = newTestSyslogEncoder(SyslogConfig{Facility: &facility, Hostname: "-", AppName: "api", ProcID: "42"})
+	encoder.Log(loggerConfig, ll.WarnLvlName, shared.FileOutput, "kernel facility")
+	assert.Equal(t, "<4>1 2024-03-11T18:35:43.123456Z - api 42 - - kernel facility", out.String())
+}
+
+func TestSyslogEncoder_Log_RFC3164(t *testing.T) {
+	var out bytes.Buffer
+	encoder := newTestSyslogEncoder(SyslogConfig{Format: RFC3164Format, Hostname: "web-1", AppName: "api", ProcID: "42"})
+	loggerConfig := &test.LoggerConfigMock{Output: &out, Fields: shared.NewBoundFields("id", 7)}
+
+	encoder.Log(loggerConfig, ll.WarnLvlName, shared.FileOutput, "slow query", "ms", 350)
+	assert.Equal(t, `<12>Mar 11 18:35:43 web-1 api[42]: slow query id="7" ms="350"`, out.String())
+
+	// The hostname is omitted when it is left to the syslog daemon
+	out.Reset()
+	encoder = newTestSyslogEncoder(SyslogConfig{Format: RFC3164Format, Hostname: "-", AppName: "api", ProcID: "42"})
+	encoder.Log(loggerConfig, ll.DebugLvlName, shared.FileOutput, "cache miss")
+	assert.Equal(t, `<15>Mar 11 18:35:43 api[42]: cache miss id="7"`, out.String())
+}
+
+func TestSyslogEncoder_StructuredData(t *testing.T) {
+	var out bytes.Buffer
+	encoder := newTestSyslogEncoder(SyslogConfig{Hostname: "-", AppName: "api", ProcID: "-", MsgID: "AUTH", SDID: "app@1"})
+	loggerConfig := &test.LoggerConfigMock{Output: &out, Fields: shared.NewBoundFields("req id", "a=b")}
+
+	encoder.Log(
+		loggerConfig,
+		ll.InfoLvlName,
+		shared.FileOutput,
+		"login",
+		`quote"key`, `say "hi" [a\b]`,
+		"group", shared.Group{"k", "v"},
+		"missing",
+	)
+	assert.Equal(
+		t,
+		`<14>1 2024-03-11T18:35:43.123456Z - api - AUTH [app@1 req_id="a=b" quote_key="say \"hi\" [a\\b\]" `+
+			`group="{k=v}" missing=""] login`,
+		out.String(),
+	)
+}
+
+func TestSyslogEncoder_Defaults(t *testing.T) {
+	var out bytes.Buffer
+	hostname, _ := os.Hostname()
+	encoder := newTestSyslogEncoder(SyslogConfig{AppName: "my app"})
+
+	encoder.Log(&test.LoggerConfigMock{Output: &out}, ll.InfoLvlName, shared.FileOutput, "")
+	assert.Equal(
+		t,
+		"<14>1 2024-03-11T18:35:43.123456Z "+hostname+" my_app "+strconv.Itoa(os.Getpid())+" - -",
+		out.String(),
+	)
+	assert.Equal(t, shared.SyslogEncoderType, encoder.GetType())
+}
+
+func TestSyslogEncoder_Color(t *testing.T) {
+	encoder := newTestSyslogEncoder(SyslogConfig{Hostname: "web-1", AppName: "api", ProcID: "42"})
+
+	output := test.CaptureOutput(func() { encoder.Color(&test.LoggerConfigMock{}, colors.Cyan, "colored msg") })
+	assert.Equal(t, "<14>1 2024-03-11T18:35:43.123456Z web-1 api 42 - - colored msg", output)
+}
+
+func TestLogLvlSyslogSeverity(t *testing.T) {
+	assert.Equal(t, AlertSeverity, LogLvlSyslogSeverity(ll.PanicLvlName))
+	assert.Equal(t, CriticalSeverity, LogLvlSyslogSeverity(ll.FatalErrorLvlName))
+	assert.Equal(t, ErrorSeverity, LogLvlSyslogSeverity(ll.ErrorLvlName))
+	assert.Equal(t, WarningSeverity, LogLvlSyslogSeverity(ll.WarnLvlName))
+	assert.Equal(t, InfoSeverity, LogLvlSyslogSeverity(ll.InfoLvlName))
+	assert.Equal(t, DebugSeverity, LogLvlSyslogSeverity(ll.DebugLvlName))
+	assert.Equal(t, DebugSeverity, LogLvlSyslogSeverity(ll.TraceLvlName))
+	assert.Equal(t, DebugSeverity, LogLvlSyslogSeverity("UNKNOWN"))
+
+	customLvls := map[ll.LogLvlName]int8{
+		"SYSLOG_TEST_EMERGENCY": ll.PanicLvl - 5,
+		"SYSLOG_TEST_SEVERE":    ll.FatalErrorLvl - 5,
+		"SYSLOG_TEST_NOTICE":    ll.WarnLvl + 5,
+		"SYSLOG_TEST_VERBOSE":   ll.InfoLvl + 5,
+	}
+	for name, lvl := range customLvls {
+		assert.NoError(t, ll.RegisterLogLvl(name, lvl, colors.Blue))
+	}
+
+	assert.Equal(t, EmergencySeverity, LogLvlSyslogSeverity("SYSLOG_TEST_EMERGENCY"))
+	assert.Equal(t, CriticalSeverity, LogLvlSyslogSeverity("SYSLOG_TEST_SEVERE"))
+	assert.Equal(t, NoticeSeverity, LogLvlSyslogSeverity("SYSLOG_TEST_NOTICE"))
+	assert.Equal(t, DebugSeverity, LogLvlSyslogSeverity("SYSLOG_TEST_VERBOSE"))
+}
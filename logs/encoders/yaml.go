@@ -3,6 +3,7 @@ package encoders
 import (
 	"bytes"
 	"sync"
+	"time"
 
 	"github.com/Pho3b/tiny-logger/internal/services"
 	c "github.com/Pho3b/tiny-logger/logs/colors"
@@ -26,8 +27,12 @@ func (y *YAMLEncoder) Log(
 	args ...any,
 ) {
 	dEnabled, tEnabled := logger.GetDateTimeEnabled()
+	caller, function := y.retrieveCaller(logger)
+	stacktrace := y.retrieveStacktrace(logger)
 	msgBuffer := y.getBuffer()
 
+	// Every entry is a separate document, so that the whole output parses as a multi-document YAML stream
+	msgBuffer.WriteString("---\n")
 	y.composeMsgInto(
 		msgBuffer,
 		y.yamlMarshaler,
@@ -36,12 +41,17 @@ func (y *YAMLEncoder) Log(
 		tEnabled,
 		logger.GetShowLogLevel(),
 		logger.GetDateTimeFormat(),
+		y.retrieveEntryTime(logger),
+		caller,
+		function,
+		stacktrace,
+		y.encodedFields(logger),
 		y.castToString(args[0]),
-		args[1:]...,
+		y.withMsgError(args[0], args[1:])...,
 	)
 
 	msgBuffer.WriteByte('\n')
-	y.printer.PrintLog(outType, msgBuffer, logger.GetLogFile())
+	y.printer.PrintLog(outType, msgBuffer, logger.GetOutputWriter(outType))
 	y.putBuffer(msgBuffer)
 }
 
@@ -60,13 +70,18 @@ func (y *YAMLEncoder) Color(logger s.LoggerConfigsInterface, color c.Color, args
 			tEnabled,
 			false,
 			logger.GetDateTimeFormat(),
+			time.Time{},
+			"",
+			"",
+			"",
+			nil,
 			y.castToString(args[0]),
 			args[1:]...,
 		)
 
 		msgBuffer.WriteString(c.Reset.String())
 		msgBuffer.WriteByte('\n')
-		y.printer.PrintLog(s.StdOutput, msgBuffer, logger.GetLogFile())
+		y.printer.PrintLog(s.StdOutput, msgBuffer, logger.GetOutputWriter(s.StdOutput))
 		y.putBuffer(msgBuffer)
 	}
 }
@@ -80,11 +95,16 @@ func (y *YAMLEncoder) composeMsgInto(
 	timeEnabled bool,
 	showLogLevel bool,
 	dateTimeFormat s.DateTimeFormat,
+	entryTime time.Time,
+	caller string,
+	function string,
+	stacktrace string,
+	fields []byte,
 	msg string,
 	extras ...any,
 ) {
 	buf.Grow((averageWordLen * len(extras)) + len(msg) + 60)
-	date, time, unixTs := y.DateTimePrinter.RetrieveDateTime(dateTimeFormat, dateEnabled, timeEnabled)
+	date, time, unixTs := y.DateTimePrinter.RetrieveDateTimeAt(entryTime, dateTimeFormat, dateEnabled, timeEnabled)
 
 	if !showLogLevel {
 		logLevel = ""
@@ -93,16 +113,34 @@ func (y *YAMLEncoder) composeMsgInto(
 	yamlMarshaler.MarshalInto(
 		buf,
 		services.YamlLogEntry{
-			Level:   logLevel.String(),
-			Date:    date,
-			Time:    time,
-			UnixTS:  unixTs,
-			Message: msg,
-			Extras:  extras,
+			Level:      logLevel.String(),
+			Date:       date,
+			Time:       time,
+			UnixTS:     unixTs,
+			Caller:     caller,
+			Function:   function,
+			Message:    msg,
+			Extras:     extras,
+			Stacktrace: stacktrace,
+			Fields:     fields,
 		},
 	)
 }
 
+// EncodeFields writes the given key/value pairs into the buffer in the format used for the entry extras.
+func (y *YAMLEncoder) EncodeFields(buf *bytes.Buffer, keyVals ...any) {
+	y.yamlMarshaler.MarshalFieldsInto(buf, keyVals...)
+}
+
+// encodedFields returns the pre-encoded fields bound to the given logger, or nil if there are none.
+func (y *YAMLEncoder) encodedFields(logger s.LoggerConfigsInterface) []byte {
+	if fields := logger.GetBoundFields(); fields != nil {
+		return fields.Encoded(y)
+	}
+
+	return nil
+}
+
 // NewYAMLEncoder initializes and returns a new YAMLEncoder instance.
 func NewYAMLEncoder(
 	printer services.Printer,
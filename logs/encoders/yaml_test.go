@@ -1,8 +1,12 @@
 package encoders
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"strings"
 	"testing"
 	"time"
 
@@ -282,3 +286,61 @@ func TestYAMLEncoder_ValidYAMLOutput(t *testing.T) {
 
 	os.Stdout = originalStdOut
 }
+
+func TestYAMLEncoder_BoundFields(t *testing.T) {
+	encoder := NewYAMLEncoder(services.NewPrinter(), services.NewYamlMarshaler(), services.GetDateTimePrinter())
+	loggerConfig := &test.LoggerConfigMock{ShowLogLevel: true, Fields: shared.NewBoundFields("user", "alice")}
+
+	output := test.CaptureOutput(func() {
+		encoder.Log(loggerConfig, ll.InfoLvlName, shared.StdOutput, "Test msg", "id", 3)
+	})
+	assert.Equal(t, "---\nlevel: INFO\nmsg: Test msg\nextras:\n  user: alice\n  id: 3\n\n", output)
+}
+
+func TestYAMLEncoder_MultiDocumentStream(t *testing.T) {
+	encoder := NewYAMLEncoder(services.NewPrinter(), services.NewYamlMarshaler(), services.GetDateTimePrinter())
+	loggerConfig := &test.LoggerConfigMock{ShowLogLevel: true}
+
+	output := test.CaptureOutput(func() {
+		encoder.Log(loggerConfig, ll.InfoLvlName, shared.StdOutput, "first: entry", "quote", `"q"`)
+		encoder.Log(loggerConfig, ll.ErrorLvlName, shared.StdOutput, "multi\nline", "stack", "a\nb\n")
+	})
+
+	var entries []shared.YamlLog
+	decoder := yaml.NewDecoder(strings.NewReader(output))
+
+	for {
+		var entry shared.YamlLog
+		if err := decoder.Decode(&entry); err != nil {
+			assert.ErrorIs(t, err, io.EOF)
+			break
+		}
+
+		entries = append(entries, entry)
+	}
+
+	assert.Equal(
+		t,
+		[]shared.YamlLog{
+			{Level: "INFO", Message: "first: entry", Extras: map[string]any{"quote": `"q"`}},
+			{Level: "ERROR", Message: "multi\nline", Extras: map[string]any{"stack": "a\nb\n"}},
+		},
+		entries,
+	)
+}
+
+func TestYAMLEncoder_ErrorMessage(t *testing.T) {
+	encoder := NewYAMLEncoder(services.NewPrinter(), services.NewYamlMarshaler(), services.GetDateTimePrinter())
+	loggerConfig := &test.LoggerConfigMock{ShowLogLevel: true}
+	err := fmt.Errorf("saving user: %w", errors.New("disk full"))
+
+	output := test.CaptureOutput(func() {
+		encoder.Log(loggerConfig, ll.ErrorLvlName, shared.StdOutput, err)
+	})
+	assert.Equal(
+		t,
+		"---\nlevel: ERROR\nmsg: \"saving user: disk full\"\nextras:\n  error:\n    msg: \"saving user: disk full\"\n"+
+			"    type: \"*fmt.wrapError\"\n    cause:\n      msg: \"disk full\"\n      type: \"*errors.errorString\"\n\n",
+		output,
+	)
+}
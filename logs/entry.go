@@ -0,0 +1,53 @@
+package logs
+
+import (
+	"time"
+
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+// entryConfigs wraps the configs of a Logger, or of a Destination, adding the caller, the stack trace and the time
+// of the entry being logged. It is created once per entry, so that concurrent log calls never share them.
+type entryConfigs struct {
+	s.LoggerConfigsInterface
+	caller       *s.Caller
+	showFunction bool
+	stacktrace   string
+	time         time.Time
+}
+
+// GetCaller returns the caller of the entry being logged and whether its function name should be shown.
+func (e *entryConfigs) GetCaller() (*s.Caller, bool) {
+	return e.caller, e.showFunction
+}
+
+// GetStacktrace returns the stack trace captured for the entry being logged.
+func (e *entryConfigs) GetStacktrace() string {
+	return e.stacktrace
+}
+
+// GetTime returns the time of the entry being logged, or the zero time if it is logged at the current time.
+func (e *entryConfigs) GetTime() time.Time {
+	return e.time
+}
+
+// withEntryInfo wraps the given configs with the given caller, stack trace and entry time,
+// returning them as they are if there is nothing to add.
+func (c *loggerConfigs) withEntryInfo(
+	configs s.LoggerConfigsInterface,
+	caller *s.Caller,
+	stacktrace string,
+	entryTime time.Time,
+) s.LoggerConfigsInterface {
+	if caller == nil && stacktrace == "" && entryTime.IsZero() {
+		return configs
+	}
+
+	return &entryConfigs{
+		LoggerConfigsInterface: configs,
+		caller:                 caller,
+		showFunction:           c.callerFuncEnabled,
+		stacktrace:             stacktrace,
+		time:                   entryTime,
+	}
+}
@@ -0,0 +1,144 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+)
+
+// maxLevelRequestBodySize is the maximum size of the request bodies accepted by the LevelHandler.
+const maxLevelRequestBodySize = 1 << 10
+
+// levelRequest is the body of the LevelHandler PUT and POST requests.
+type levelRequest struct {
+	Level ll.LogLvlName `json:"level"`
+	// TTL is an optional time.Duration string ("30s", "5m"...) after which the previous level is restored.
+	TTL string `json:"ttl,omitempty"`
+}
+
+// levelResponse is the body of the LevelHandler responses.
+type levelResponse struct {
+	Level    ll.LogLvlName `json:"level,omitempty"`
+	RevertAt *time.Time    `json:"revert_at,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// LevelHandler is an http.Handler exposing the log level of a Logger, so that it can be changed at runtime:
+//   - GET returns the current level as {"level":"INFO"}
+//   - PUT and POST set the level given as {"level":"DEBUG"}. An optional "ttl" duration ({"ttl":"5m"})
+//     restores the previous level once expired, unless the level is changed again through the handler meanwhile.
+//
+// Both built-in and custom levels registered through log_level.RegisterLogLvl are accepted, case-insensitively.
+type LevelHandler struct {
+	logger      *Logger
+	mu          sync.Mutex
+	revertTimer *time.Timer
+	revertAt    time.Time
+}
+
+// ServeHTTP serves the GET, PUT and POST requests, replying 405 to any other method.
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.writeResponse(w, http.StatusOK, h.currentLevel())
+	case http.MethodPut, http.MethodPost:
+		h.setLevel(w, r)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		h.writeResponse(w, http.StatusMethodNotAllowed, levelResponse{Error: "method not allowed"})
+	}
+}
+
+// setLevel sets the level given in the request body, scheduling its revert if a TTL is given.
+func (h *LevelHandler) setLevel(w http.ResponseWriter, r *http.Request) {
+	var req levelRequest
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxLevelRequestBodySize))
+	if err := decoder.Decode(&req); err != nil {
+		h.writeResponse(w, http.StatusBadRequest, levelResponse{Error: "invalid request body: " + err.Error()})
+		return
+	}
+
+	lvlName := ll.LogLvlName(strings.ToUpper(strings.TrimSpace(string(req.Level))))
+	if _, found := ll.LogLvlNameToInt[lvlName]; !found {
+		h.writeResponse(w, http.StatusBadRequest, levelResponse{Error: fmt.Sprintf("unknown log level %q", req.Level)})
+		return
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			h.writeResponse(w, http.StatusBadRequest, levelResponse{Error: fmt.Sprintf("invalid ttl %q", req.TTL)})
+			return
+		}
+	}
+
+	h.mu.Lock()
+	h.stopRevert()
+	previous := h.logger.GetLogLvlName()
+	h.logger.SetLogLvl(lvlName)
+
+	if ttl > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(ttl, func() { h.revert(timer, previous) })
+		h.revertTimer = timer
+		h.revertAt = time.Now().Add(ttl)
+	}
+	h.mu.Unlock()
+
+	h.writeResponse(w, http.StatusOK, h.currentLevel())
+}
+
+// revert restores the given level, unless the given timer has been replaced by a newer level change.
+func (h *LevelHandler) revert(timer *time.Timer, lvlName ll.LogLvlName) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.revertTimer != timer {
+		return
+	}
+
+	h.logger.SetLogLvl(lvlName)
+	h.revertTimer = nil
+}
+
+// stopRevert cancels the pending revert, if any. It must be called holding the mutex.
+func (h *LevelHandler) stopRevert() {
+	if h.revertTimer != nil {
+		h.revertTimer.Stop()
+		h.revertTimer = nil
+	}
+}
+
+// currentLevel returns the response describing the current level and its pending revert, if any.
+func (h *LevelHandler) currentLevel() levelResponse {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	resp := levelResponse{Level: h.logger.GetLogLvlName()}
+	if h.revertTimer != nil {
+		revertAt := h.revertAt
+		resp.RevertAt = &revertAt
+	}
+
+	return resp
+}
+
+// writeResponse writes the given response as JSON with the given status code.
+func (h *LevelHandler) writeResponse(w http.ResponseWriter, statusCode int, resp levelResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// NewLevelHandler creates and returns a new LevelHandler exposing the log level of the given Logger.
+func NewLevelHandler(logger *Logger) *LevelHandler {
+	return &LevelHandler{logger: logger}
+}
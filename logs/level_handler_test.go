@@ -0,0 +1,105 @@
+package logs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveLevelRequest(handler http.Handler, method string, body string) (*httptest.ResponseRecorder, levelResponse) {
+	var resp levelResponse
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, "/log/level", strings.NewReader(body)))
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+
+	return rec, resp
+}
+
+func TestLevelHandler_Get(t *testing.T) {
+	handler := NewLevelHandler(NewLogger().SetLogLvl(ll.WarnLvlName))
+
+	rec, _ := serveLevelRequest(handler, http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `{"level":"WARN"}`+"\n", rec.Body.String())
+}
+
+func TestLevelHandler_Set(t *testing.T) {
+	logger := NewLogger().SetLogLvl(ll.InfoLvlName)
+	handler := NewLevelHandler(logger)
+
+	rec, resp := serveLevelRequest(handler, http.MethodPut, `{"level":"DEBUG"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ll.DebugLvlName, resp.Level)
+	assert.Nil(t, resp.RevertAt)
+	assert.Equal(t, ll.DebugLvlName, logger.GetLogLvlName())
+
+	rec, resp = serveLevelRequest(handler, http.MethodPost, `{"level":" error "}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ll.ErrorLvlName, resp.Level)
+	assert.Equal(t, ll.ErrorLvlName, logger.GetLogLvlName())
+}
+
+func TestLevelHandler_InvalidRequests(t *testing.T) {
+	logger := NewLogger().SetLogLvl(ll.InfoLvlName)
+	handler := NewLevelHandler(logger)
+
+	for _, body := range []string{
+		`{"level":"VERBOSE"}`,
+		`{"level":"DEBUG","ttl":"soon"}`,
+		`{"level":"DEBUG","ttl":"-1m"}`,
+		`not json`,
+		`{"level":"` + strings.Repeat("A", maxLevelRequestBodySize) + `"}`,
+	} {
+		rec, resp := serveLevelRequest(handler, http.MethodPut, body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		assert.NotEmpty(t, resp.Error, body)
+	}
+
+	rec, resp := serveLevelRequest(handler, http.MethodDelete, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, PUT, POST", rec.Header().Get("Allow"))
+	assert.Equal(t, "method not allowed", resp.Error)
+	assert.Equal(t, ll.InfoLvlName, logger.GetLogLvlName())
+}
+
+func TestLevelHandler_TTL(t *testing.T) {
+	logger := NewLogger().SetLogLvl(ll.InfoLvlName)
+	handler := NewLevelHandler(logger)
+
+	start := time.Now()
+	rec, resp := serveLevelRequest(handler, http.MethodPut, `{"level":"DEBUG","ttl":"50ms"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ll.DebugLvlName, resp.Level)
+	assert.NotNil(t, resp.RevertAt)
+	assert.WithinDuration(t, start.Add(50*time.Millisecond), *resp.RevertAt, time.Second)
+
+	_, resp = serveLevelRequest(handler, http.MethodGet, "")
+	assert.NotNil(t, resp.RevertAt)
+
+	assert.Eventually(t, func() bool {
+		return logger.GetLogLvlName() == ll.InfoLvlName
+	}, 2*time.Second, 5*time.Millisecond)
+
+	_, resp = serveLevelRequest(handler, http.MethodGet, "")
+	assert.Equal(t, ll.InfoLvlName, resp.Level)
+	assert.Nil(t, resp.RevertAt)
+}
+
+func TestLevelHandler_TTLCanceledByNewLevel(t *testing.T) {
+	logger := NewLogger().SetLogLvl(ll.InfoLvlName)
+	handler := NewLevelHandler(logger)
+
+	serveLevelRequest(handler, http.MethodPut, `{"level":"DEBUG","ttl":"20ms"}`)
+	serveLevelRequest(handler, http.MethodPut, `{"level":"WARN"}`)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, ll.WarnLvlName, logger.GetLogLvlName())
+}
@@ -1,8 +1,12 @@
 package log_level
 
 import (
+	"errors"
 	"os"
 	"strings"
+	"sync"
+
+	c "github.com/Pho3b/tiny-logger/logs/colors"
 )
 
 // LogLvlName is the Enum representing the possible Log Levels.
@@ -13,42 +17,104 @@ type LogLvlName string
 const DefaultEnvLogLvlVar = "TINY_LOGGER_LVL"
 
 const (
+	PanicLvlName      LogLvlName = "PANIC"
 	FatalErrorLvlName LogLvlName = "FATAL_ERROR"
 	ErrorLvlName      LogLvlName = "ERROR"
 	WarnLvlName       LogLvlName = "WARN"
 	InfoLvlName       LogLvlName = "INFO"
 	DebugLvlName      LogLvlName = "DEBUG"
+	TraceLvlName      LogLvlName = "TRACE"
 )
 
 func (l LogLvlName) String() string {
 	return string(l)
 }
 
-// Log Level INT8 Constants
+// Log Level INT8 Constants.
+// Lower values are more severe: a logger logs the entries whose level value is lower than or equal to its own.
+// The values are spaced out by 10, so that custom levels can be ordered between the built-in ones.
 const (
-	FatalErrorLvl = int8(-1)
+	PanicLvl      = int8(-20)
+	FatalErrorLvl = int8(-10)
 	ErrorLvl      = int8(0)
-	WarnLvl       = int8(1)
-	InfoLvl       = int8(2)
-	DebugLvl      = int8(3)
+	WarnLvl       = int8(10)
+	InfoLvl       = int8(20)
+	DebugLvl      = int8(30)
+	TraceLvl      = int8(40)
 )
 
 // LogLvlIntToName represents the log level INT to STRING map
 var LogLvlIntToName = map[int8]LogLvlName{
+	PanicLvl:      PanicLvlName,
 	FatalErrorLvl: FatalErrorLvlName,
 	ErrorLvl:      ErrorLvlName,
 	WarnLvl:       WarnLvlName,
 	InfoLvl:       InfoLvlName,
 	DebugLvl:      DebugLvlName,
+	TraceLvl:      TraceLvlName,
 }
 
 // LogLvlNameToInt represents the log level STRING to INT map
 var LogLvlNameToInt = map[LogLvlName]int8{
+	PanicLvlName:      PanicLvl,
 	FatalErrorLvlName: FatalErrorLvl,
 	ErrorLvlName:      ErrorLvl,
 	WarnLvlName:       WarnLvl,
 	InfoLvlName:       InfoLvl,
 	DebugLvlName:      DebugLvl,
+	TraceLvlName:      TraceLvl,
+}
+
+var (
+	ErrEmptyLogLvlName       = errors.New("tiny-logger: the log level name cannot be empty")
+	ErrLogLvlNameRegistered  = errors.New("tiny-logger: a log level with the given name is already registered")
+	ErrLogLvlValueRegistered = errors.New("tiny-logger: a log level with the given value is already registered")
+)
+
+var (
+	registerMu sync.Mutex
+	// customLvlColors maps the custom log levels to the color of their header
+	customLvlColors = map[int8]c.Color{}
+)
+
+// RegisterLogLvl registers a custom log level with the given name, value and header color, so that it can be used
+// like the built-in ones (e.g. through Logger.Log or Logger.SetLogLvl). The value sets the level severity
+// and must not be used by other levels: the built-in ones use the multiples of 10 from PanicLvl (-20) to
+// TraceLvl (40), so that e.g. a NOTICE level with value 15 is ordered between WARN and INFO.
+// The levels more severe than FATAL_ERROR are always written, whatever the Logger level.
+// Names should be upper case to be retrievable from the ENV variables.
+//
+// NOTE: Custom levels must be registered before logging with them, typically in an init function,
+// since the log level maps are read without locks.
+func RegisterLogLvl(name LogLvlName, lvl int8, color c.Color) error {
+	if name == "" {
+		return ErrEmptyLogLvlName
+	}
+
+	registerMu.Lock()
+	defer registerMu.Unlock()
+
+	if _, found := LogLvlNameToInt[name]; found {
+		return ErrLogLvlNameRegistered
+	}
+
+	if _, found := LogLvlIntToName[lvl]; found {
+		return ErrLogLvlValueRegistered
+	}
+
+	LogLvlNameToInt[name] = lvl
+	LogLvlIntToName[lvl] = name
+	customLvlColors[lvl] = color
+
+	return nil
+}
+
+// RetrieveCustomLogLvlColor returns the header color of the given custom log level,
+// or false if no custom log level is registered with the given value.
+func RetrieveCustomLogLvlColor(lvl int8) (c.Color, bool) {
+	color, found := customLvlColors[lvl]
+
+	return color, found
 }
 
 type LogLevel struct {
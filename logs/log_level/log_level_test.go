@@ -4,6 +4,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"os"
 	"testing"
+
+	c "github.com/Pho3b/tiny-logger/logs/colors"
 )
 
 func TestRetrieveLogLvlFromEnv(t *testing.T) {
@@ -17,35 +19,75 @@ func TestRetrieveLogLvlFromEnv(t *testing.T) {
 
 func TestLogLevel_LvlName(t *testing.T) {
 	logLvl := LogLevel{
-		Lvl:         2,
+		Lvl:         20,
 		EnvVariable: "test-env-var",
 	}
 	assert.Equal(t, InfoLvlName, logLvl.LvlName())
 
-	logLvl.Lvl = 3
+	logLvl.Lvl = 30
 	assert.Equal(t, DebugLvlName, logLvl.LvlName())
 }
 
 func TestLogLvlIntValue(t *testing.T) {
 	logLvl := LogLevel{
-		Lvl:         2,
+		Lvl:         20,
 		EnvVariable: "test-env-var",
 	}
 	assert.Equal(t, InfoLvl, logLvl.LvlIntValue())
 
-	logLvl.Lvl = 3
+	logLvl.Lvl = 30
 	assert.Equal(t, DebugLvl, logLvl.LvlIntValue())
 }
 
 func TestLogLvlName_String(t *testing.T) {
-	logLvl := LogLevel{Lvl: 3}
+	logLvl := LogLevel{Lvl: 30}
 	assert.Equal(t, DebugLvlName.String(), logLvl.LvlName().String())
-	logLvl.Lvl = 2
+	logLvl.Lvl = 20
 	assert.Equal(t, InfoLvlName, logLvl.LvlName())
-	logLvl.Lvl = 1
+	logLvl.Lvl = 10
 	assert.Equal(t, WarnLvlName.String(), logLvl.LvlName().String())
 	logLvl.Lvl = 0
 	assert.Equal(t, ErrorLvlName, logLvl.LvlName())
-	logLvl.Lvl = -1
+	logLvl.Lvl = -10
 	assert.Equal(t, FatalErrorLvlName.String(), logLvl.LvlName().String())
 }
+
+func TestTraceAndPanicLvls(t *testing.T) {
+	assert.Equal(t, TraceLvl, RetrieveLogLvlIntFromName(TraceLvlName))
+	assert.Equal(t, PanicLvl, RetrieveLogLvlIntFromName(PanicLvlName))
+	assert.Greater(t, TraceLvl, DebugLvl)
+	assert.Less(t, PanicLvl, ErrorLvl)
+
+	logLvl := LogLevel{Lvl: TraceLvl}
+	assert.Equal(t, TraceLvlName, logLvl.LvlName())
+	logLvl.Lvl = PanicLvl
+	assert.Equal(t, PanicLvlName, logLvl.LvlName())
+}
+
+func TestRegisterLogLvl(t *testing.T) {
+	const auditLvlName, auditLvl = LogLvlName("AUDIT"), int8(-15)
+	defer func() {
+		delete(LogLvlNameToInt, auditLvlName)
+		delete(LogLvlIntToName, auditLvl)
+		delete(customLvlColors, auditLvl)
+	}()
+
+	assert.NoError(t, RegisterLogLvl(auditLvlName, auditLvl, c.BrightBlue))
+	assert.Equal(t, auditLvl, RetrieveLogLvlIntFromName(auditLvlName))
+	assert.Equal(t, auditLvlName, LogLvlIntToName[auditLvl])
+
+	color, found := RetrieveCustomLogLvlColor(auditLvl)
+	assert.True(t, found)
+	assert.Equal(t, c.BrightBlue, color)
+
+	_ = os.Setenv("MY_INSTANCE_LOGS_LVL_AUDIT", "audit")
+	assert.Equal(t, auditLvl, RetrieveLogLvlFromEnv("MY_INSTANCE_LOGS_LVL_AUDIT"))
+
+	assert.ErrorIs(t, RegisterLogLvl("", -11, c.Red), ErrEmptyLogLvlName)
+	assert.ErrorIs(t, RegisterLogLvl(auditLvlName, -11, c.Red), ErrLogLvlNameRegistered)
+	assert.ErrorIs(t, RegisterLogLvl(InfoLvlName, -11, c.Red), ErrLogLvlNameRegistered)
+	assert.ErrorIs(t, RegisterLogLvl("NOTICE", InfoLvl, c.Red), ErrLogLvlValueRegistered)
+
+	_, found = RetrieveCustomLogLvlColor(InfoLvl)
+	assert.False(t, found)
+}
@@ -0,0 +1,84 @@
+package log_level
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// DefaultEnvLvlOverridesVar is the default ENV variable that any new logger will try to
+// retrieve the log level overrides from by default.
+const DefaultEnvLvlOverridesVar = "TINY_LOGGER_LVL_OVERRIDES"
+
+var ErrInvalidLvlOverride = errors.New("tiny-logger: invalid log level override")
+
+// LvlOverride sets the log level of the packages, or of the named loggers, matching its pattern.
+type LvlOverride struct {
+	Pattern string
+	Lvl     int8
+}
+
+// ParseLvlOverrides parses a comma separated list of "pattern=level" overrides, like "payments/*=debug,db=warn".
+// Level names are case-insensitive and can be either built-in or registered through RegisterLogLvl, while the
+// patterns follow the path.Match syntax. An empty spec returns no overrides.
+func ParseLvlOverrides(spec string) ([]LvlOverride, error) {
+	var overrides []LvlOverride
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		pattern, lvlName, found := strings.Cut(entry, "=")
+		pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+		if !found || pattern == "" {
+			return nil, fmt.Errorf("%w: %q, expected \"pattern=level\"", ErrInvalidLvlOverride, entry)
+		}
+
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: %q, %v", ErrInvalidLvlOverride, entry, err)
+		}
+
+		lvl, found := LogLvlNameToInt[LogLvlName(strings.ToUpper(strings.TrimSpace(lvlName)))]
+		if !found {
+			return nil, fmt.Errorf("%w: %q, unknown log level %q", ErrInvalidLvlOverride, entry, lvlName)
+		}
+
+		overrides = append(overrides, LvlOverride{Pattern: pattern, Lvl: lvl})
+	}
+
+	return overrides, nil
+}
+
+// MatchLvlOverrides returns the level of the first override whose pattern matches the given slash separated
+// name (a package path or a logger name), or false if none matches.
+// A pattern matches when it matches the trailing path elements of the name, as many as its own elements:
+// "payments/*" matches the "github.com/acme/app/payments/stripe" package and "db" matches any "db" package.
+func MatchLvlOverrides(overrides []LvlOverride, name string) (int8, bool) {
+	for _, override := range overrides {
+		elements := strings.Count(override.Pattern, "/") + 1
+
+		if matched, _ := path.Match(override.Pattern, trailingElements(name, elements)); matched {
+			return override.Lvl, true
+		}
+	}
+
+	return 0, false
+}
+
+// trailingElements returns the last 'n' slash separated elements of the given name,
+// or the whole name if it has fewer elements.
+func trailingElements(name string, n int) string {
+	i := len(name)
+
+	for ; n > 0; n-- {
+		i = strings.LastIndexByte(name[:i], '/')
+		if i < 0 {
+			return name
+		}
+	}
+
+	return name[i+1:]
+}
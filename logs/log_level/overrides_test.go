@@ -0,0 +1,62 @@
+package log_level
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLvlOverrides(t *testing.T) {
+	overrides, err := ParseLvlOverrides(" payments/*=debug , db=WARN,/internal/cache/=Trace,")
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]LvlOverride{{"payments/*", DebugLvl}, {"db", WarnLvl}, {"internal/cache", TraceLvl}},
+		overrides,
+	)
+
+	overrides, err = ParseLvlOverrides("")
+	assert.NoError(t, err)
+	assert.Nil(t, overrides)
+
+	for _, spec := range []string{"payments", "=debug", "db=verbose", "db[=debug", "db=debug,payments"} {
+		overrides, err = ParseLvlOverrides(spec)
+		assert.ErrorIs(t, err, ErrInvalidLvlOverride, spec)
+		assert.Nil(t, overrides, spec)
+	}
+}
+
+func TestMatchLvlOverrides(t *testing.T) {
+	overrides := []LvlOverride{
+		{"payments/*", DebugLvl},
+		{"db", WarnLvl},
+		{"github.com/acme/app", ErrorLvl},
+		{"app", InfoLvl},
+	}
+
+	for name, expected := range map[string]int8{
+		"github.com/acme/app/payments/stripe": DebugLvl,
+		"payments/stripe":                     DebugLvl,
+		"github.com/acme/app/db":              WarnLvl,
+		"db":                                  WarnLvl,
+		"github.com/acme/app":                 ErrorLvl,
+		"gitlab.com/other/app":                InfoLvl,
+	} {
+		lvl, found := MatchLvlOverrides(overrides, name)
+		assert.True(t, found, name)
+		assert.Equal(t, expected, lvl, name)
+	}
+
+	for _, name := range []string{"github.com/acme/app/payments", "github.com/acme/app/dbx", "", "main"} {
+		_, found := MatchLvlOverrides(overrides, name)
+		assert.False(t, found, name)
+	}
+}
+
+func TestTrailingElements(t *testing.T) {
+	assert.Equal(t, "c", trailingElements("a/b/c", 1))
+	assert.Equal(t, "b/c", trailingElements("a/b/c", 2))
+	assert.Equal(t, "a/b/c", trailingElements("a/b/c", 3))
+	assert.Equal(t, "a/b/c", trailingElements("a/b/c", 5))
+	assert.Equal(t, "main", trailingElements("main", 2))
+}
@@ -1,53 +1,76 @@
 package logs
 
 import (
+	"fmt"
+	"io"
+	"math"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/Pho3b/tiny-logger/internal/services"
 	"github.com/Pho3b/tiny-logger/logs/colors"
 	"github.com/Pho3b/tiny-logger/logs/encoders"
 	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	"github.com/Pho3b/tiny-logger/logs/sinks"
 	s "github.com/Pho3b/tiny-logger/shared"
 )
 
+// Logger is safe for concurrent use, setters included: every log call reads an immutable snapshot of the
+// settings, which the setters replace atomically. Entries already being logged keep using the previous snapshot.
+//
+// The child loggers returned by With and Named share the configuration of the Logger they derive from: the changes
+// applied through any of them reach the whole family, while the bound fields, the name and the caller skip
+// are specific to each child.
 type Logger struct {
-	dateEnabled     bool
-	timeEnabled     bool
-	colorsEnabled   bool
-	showLogLevel    bool
-	encoder         s.EncoderInterface
-	logLvl          ll.LogLevel
-	outFile         *os.File
-	dateTimeFormat  s.DateTimeFormat
-	printer         services.Printer
-	dateTimePrinter *services.DateTimePrinter
+	configs  atomic.Pointer[loggerConfigs]
+	updateMu sync.Mutex
+	// owner is the Logger holding the configs shared by a child Logger, nil for the loggers created by NewLogger
+	owner   *Logger
+	overlay atomic.Pointer[childOverlay]
+}
+
+// childOverlay holds the settings a child Logger applies on top of the configs it shares with its owner.
+type childOverlay struct {
+	fields     *s.BoundFields
+	name       string
+	callerSkip int
+}
+
+// Trace logs a trace-level message if the logger's log level allows it.
+func (l *Logger) Trace(args ...any) {
+	if l.isLvlEnabled(ll.TraceLvl) && len(args) > 0 {
+		l.log(ll.TraceLvl, ll.TraceLvlName, s.StdOutput, args...)
+	}
 }
 
 // Debug logs a debug-level message if the logger's log level allows it.
 func (l *Logger) Debug(args ...any) {
-	if l.logLvl.Lvl >= ll.DebugLvl && len(args) > 0 {
-		l.encoder.Log(l, ll.DebugLvlName, l.checkOutFile(s.StdOutput), args...)
+	if l.isLvlEnabled(ll.DebugLvl) && len(args) > 0 {
+		l.log(ll.DebugLvl, ll.DebugLvlName, s.StdOutput, args...)
 	}
 }
 
 // Info logs an informational-level message if the logger's log level allows it.
 func (l *Logger) Info(args ...any) {
-	if l.logLvl.Lvl >= ll.InfoLvl && len(args) > 0 {
-		l.encoder.Log(l, ll.InfoLvlName, l.checkOutFile(s.StdOutput), args...)
+	if l.isLvlEnabled(ll.InfoLvl) && len(args) > 0 {
+		l.log(ll.InfoLvl, ll.InfoLvlName, s.StdOutput, args...)
 	}
 }
 
 // Warn logs a warning-level message if the logger's log level allows it.
 func (l *Logger) Warn(args ...any) {
-	if l.logLvl.Lvl >= ll.WarnLvl && len(args) > 0 {
-		l.encoder.Log(l, ll.WarnLvlName, l.checkOutFile(s.StdOutput), args...)
+	if l.isLvlEnabled(ll.WarnLvl) && len(args) > 0 {
+		l.log(ll.WarnLvl, ll.WarnLvlName, s.StdOutput, args...)
 	}
 }
 
 // Error logs an error-level message if the logger's log level allows it.
 func (l *Logger) Error(args ...any) {
-	if l.logLvl.Lvl >= ll.ErrorLvl && len(args) > 0 && !l.areAllNil(args...) {
-		l.encoder.Log(l, ll.ErrorLvlName, l.checkOutFile(s.StdErrOutput), args...)
+	if l.isLvlEnabled(ll.ErrorLvl) && len(args) > 0 && !l.areAllNil(args...) {
+		l.log(ll.ErrorLvl, ll.ErrorLvlName, s.StdErrOutput, args...)
 	}
 }
 
@@ -55,32 +78,153 @@ func (l *Logger) Error(args ...any) {
 // otherwise the method does nothing.
 func (l *Logger) FatalError(args ...any) {
 	if len(args) > 0 && !l.areAllNil(args...) {
-		l.encoder.Log(l, ll.FatalErrorLvlName, l.checkOutFile(s.StdErrOutput), args...)
+		l.log(ll.FatalErrorLvl, ll.FatalErrorLvlName, s.StdErrOutput, args...)
+		l.Flush()
 		os.Exit(1)
 	}
 }
 
+// Debugf formats the given args according to the format specifier and logs the result as a debug-level message,
+// only if the logger's log level allows it.
+func (l *Logger) Debugf(format string, args ...any) {
+	if l.isLvlEnabled(ll.DebugLvl) {
+		l.log(ll.DebugLvl, ll.DebugLvlName, s.StdOutput, fmt.Sprintf(format, args...))
+	}
+}
+
+// Infof formats the given args according to the format specifier and logs the result as an informational-level
+// message, only if the logger's log level allows it.
+func (l *Logger) Infof(format string, args ...any) {
+	if l.isLvlEnabled(ll.InfoLvl) {
+		l.log(ll.InfoLvl, ll.InfoLvlName, s.StdOutput, fmt.Sprintf(format, args...))
+	}
+}
+
+// Warnf formats the given args according to the format specifier and logs the result as a warning-level message,
+// only if the logger's log level allows it.
+func (l *Logger) Warnf(format string, args ...any) {
+	if l.isLvlEnabled(ll.WarnLvl) {
+		l.log(ll.WarnLvl, ll.WarnLvlName, s.StdOutput, fmt.Sprintf(format, args...))
+	}
+}
+
+// Errorf formats the given args according to the format specifier and logs the result as an error-level message,
+// only if the logger's log level allows it.
+func (l *Logger) Errorf(format string, args ...any) {
+	if l.isLvlEnabled(ll.ErrorLvl) {
+		l.log(ll.ErrorLvl, ll.ErrorLvlName, s.StdErrOutput, fmt.Sprintf(format, args...))
+	}
+}
+
+// Panicf formats the given args according to the format specifier, logs the result as a panic message
+// and then panics with it.
+func (l *Logger) Panicf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	l.log(ll.PanicLvl, ll.PanicLvlName, s.StdErrOutput, msg)
+	l.Flush()
+	panic(msg)
+}
+
+// FatalErrorf formats the given args according to the format specifier, logs the result as a fatal error message
+// and terminates the application.
+func (l *Logger) FatalErrorf(format string, args ...any) {
+	l.log(ll.FatalErrorLvl, ll.FatalErrorLvlName, s.StdErrOutput, fmt.Sprintf(format, args...))
+	l.Flush()
+	os.Exit(1)
+}
+
+// Panic logs a panic message and then panics with the given args concatenated by a white space, only if any given
+// args is not nil, otherwise the method does nothing.
+func (l *Logger) Panic(args ...any) {
+	if len(args) > 0 && !l.areAllNil(args...) {
+		l.log(ll.PanicLvl, ll.PanicLvlName, s.StdErrOutput, args...)
+		l.Flush()
+		panic(concatenateArgs(args...))
+	}
+}
+
+// Log logs the given args at the given log level, either a built-in or a custom one registered through
+// log_level.RegisterLogLvl, if the logger's log level allows it. Unknown level names fall back to DebugLvlName.
+// Like through their dedicated methods, PANIC entries panic and FATAL_ERROR entries terminate the application,
+// while the entries of the custom levels more severe than FATAL_ERROR are always written, as theirs.
+func (l *Logger) Log(logLvlName ll.LogLvlName, args ...any) {
+	lvl := ll.RetrieveLogLvlIntFromName(logLvlName)
+
+	if len(args) == 0 || (!isForcedLvl(lvl) && !l.isLvlEnabled(lvl)) ||
+		(lvl <= ll.ErrorLvl && l.areAllNil(args...)) {
+		return
+	}
+
+	l.log(lvl, ll.LogLvlIntToName[lvl], lvlOutputType(lvl), args...)
+
+	switch lvl {
+	case ll.PanicLvl:
+		l.Flush()
+		panic(concatenateArgs(args...))
+	case ll.FatalErrorLvl:
+		l.Flush()
+		os.Exit(1)
+	}
+}
+
+// Tracef formats the given args according to the format specifier and logs the result as a trace-level message,
+// only if the logger's log level allows it.
+func (l *Logger) Tracef(format string, args ...any) {
+	if l.isLvlEnabled(ll.TraceLvl) {
+		l.log(ll.TraceLvl, ll.TraceLvlName, s.StdOutput, fmt.Sprintf(format, args...))
+	}
+}
+
 // Color formats and prints a colored log message using the specified color.
 func (l *Logger) Color(color colors.Color, args ...any) {
-	l.encoder.Color(l, color, args...)
+	c := l.loadConfigs()
+	c.encoder.Color(c, color, args...)
+}
+
+// With returns a child Logger that prepends the given key/value pairs to the extras of every entry it logs.
+// The child shares the Logger configuration, while the pairs are encoded only once per encoder.
+// The configuration belongs to the parent Logger: the setters called on the child, other than AddCallerSkip,
+// are skipped with a warning, so that a child never reconfigures its parent and siblings.
+func (l *Logger) With(keyVals ...any) *Logger {
+	return l.newChild(func(o *childOverlay) {
+		o.fields = o.fields.With(keyVals...)
+	})
+}
+
+// Named returns a child Logger like With does, named after the given name. The names of nested child loggers are
+// joined by a slash ("payments/stripe"), so that the log level overrides can target them like packages.
+func (l *Logger) Named(name string) *Logger {
+	return l.newChild(func(o *childOverlay) {
+		o.name = strings.Trim(o.name+"/"+name, "/")
+	})
+}
+
+// GetName returns the name of the Logger set through Named, or an empty string if it is not named.
+func (l *Logger) GetName() string {
+	return l.loadConfigs().name
+}
+
+// GetBoundFields returns the fields bound to the Logger through With, or nil if there are none.
+func (l *Logger) GetBoundFields() *s.BoundFields {
+	return l.loadConfigs().GetBoundFields()
 }
 
 // GetLogLvlName returns the current log level name as a string.
 func (l *Logger) GetLogLvlName() ll.LogLvlName {
-	return ll.LogLvlIntToName[l.logLvl.Lvl]
+	return l.loadConfigs().GetLogLvlName()
 }
 
 // GetLogLvlIntValue returns the current log level as an int8 value.
 func (l *Logger) GetLogLvlIntValue() int8 {
-	return l.logLvl.Lvl
+	return l.loadConfigs().GetLogLvlIntValue()
 }
 
 // SetLogLvl sets the log level of the logger based on a provided log level name.
 // If the provided name is invalid, it defaults to DebugLvlName.
 func (l *Logger) SetLogLvl(logLvlName ll.LogLvlName) *Logger {
-	l.logLvl.Lvl = ll.RetrieveLogLvlIntFromName(logLvlName)
-
-	return l
+	return l.update(func(c *loggerConfigs) {
+		c.logLvl.Lvl = ll.RetrieveLogLvlIntFromName(logLvlName)
+	})
 }
 
 // SetLogLvlEnvVariable sets the log level based on an environment variable. If the variable is not found,
@@ -88,86 +232,306 @@ func (l *Logger) SetLogLvl(logLvlName ll.LogLvlName) *Logger {
 //
 // NOTE: The environment variable value must be a valid ll.LogLvlName string.
 func (l *Logger) SetLogLvlEnvVariable(envVariableName string) *Logger {
-	l.logLvl.EnvVariable = envVariableName
-	l.logLvl.Lvl = ll.RetrieveLogLvlFromEnv(l.logLvl.EnvVariable)
+	return l.update(func(c *loggerConfigs) {
+		c.logLvl.EnvVariable = envVariableName
+		c.logLvl.Lvl = ll.RetrieveLogLvlFromEnv(c.logLvl.EnvVariable)
+	})
+}
 
-	return l
+// GetLvlOverrides returns the log level overrides set through SetLvlOverrides, or an empty string if there are none.
+func (l *Logger) GetLvlOverrides() string {
+	if o := l.loadConfigs().lvlOverrides; o != nil {
+		return o.spec
+	}
+
+	return ""
+}
+
+// SetLvlOverrides sets vmodule-style log level overrides as a comma separated list of "pattern=level" entries,
+// like "payments/*=debug,db=warn". Each entry replaces the log level of the Logger for the entries logged from the
+// packages matching its pattern, or by the child loggers whose Named name matches it; the first matching entry
+// wins. The patterns follow the path.Match syntax and match the trailing elements of the package import path.
+// The level resolved for each call site is cached, and the destinations keep their own log level.
+// An empty spec removes the overrides, while an invalid one is logged as a warning and the method does nothing.
+func (l *Logger) SetLvlOverrides(spec string) *Logger {
+	overrides, err := newLvlOverrides(spec)
+	if err != nil {
+		l.Warn("the given log level overrides are invalid, skipping overrides change:", err)
+		return l
+	}
+
+	return l.update(func(c *loggerConfigs) {
+		c.lvlOverrides = overrides
+		c.resolveNamedLvl()
+	})
+}
+
+// SetLvlOverridesEnvVariable sets the log level overrides from the given environment variable,
+// removing them if the variable is not found. See SetLvlOverrides for the overrides syntax.
+func (l *Logger) SetLvlOverridesEnvVariable(envVariableName string) *Logger {
+	return l.SetLvlOverrides(os.Getenv(envVariableName))
 }
 
 // GetColorsEnabled returns true if color output is enabled, false otherwise.
 func (l *Logger) GetColorsEnabled() bool {
-	return l.colorsEnabled
+	return l.loadConfigs().GetColorsEnabled()
 }
 
 // EnableColors enables or disables color output in the logger based on the given parameter.
 // Colors apply only on the header elements [Data, Time, Log Level]
 func (l *Logger) EnableColors(enable bool) *Logger {
-	l.colorsEnabled = enable
-
-	return l
+	return l.update(func(c *loggerConfigs) {
+		c.colorsEnabled = enable
+	})
 }
 
 // GetShowLogLevel returns the showLogLevel value of the logger.
 func (l *Logger) GetShowLogLevel() bool {
-	return l.showLogLevel
+	return l.loadConfigs().GetShowLogLevel()
 }
 
 // ShowLogLevel enables/disables the log level visibility of the logger.
 func (l *Logger) ShowLogLevel(enable bool) *Logger {
-	l.showLogLevel = enable
-
-	return l
+	return l.update(func(c *loggerConfigs) {
+		c.showLogLevel = enable
+	})
 }
 
 // GetDateTimeEnabled returns the current date and time settings of the logger.
 func (l *Logger) GetDateTimeEnabled() (dateEnabled bool, timeEnabled bool) {
-	return l.dateEnabled, l.timeEnabled
+	return l.loadConfigs().GetDateTimeEnabled()
 }
 
 // AddDateTime enables or disables both date and time in log output.
 func (l *Logger) AddDateTime(addDateTime bool) *Logger {
-	l.dateEnabled = addDateTime
-	l.timeEnabled = addDateTime
-
-	return l
+	return l.update(func(c *loggerConfigs) {
+		c.dateEnabled = addDateTime
+		c.timeEnabled = addDateTime
+	})
 }
 
 // AddDate enables or disables the date in log output based on the provided parameter.
 func (l *Logger) AddDate(addDate bool) *Logger {
-	l.dateEnabled = addDate
-
-	return l
+	return l.update(func(c *loggerConfigs) {
+		c.dateEnabled = addDate
+	})
 }
 
 // AddTime enables or disables time in log output based on the provided parameter.
 func (l *Logger) AddTime(addTime bool) *Logger {
-	l.timeEnabled = addTime
+	return l.update(func(c *loggerConfigs) {
+		c.timeEnabled = addTime
+	})
+}
 
-	return l
+// GetCallerEnabled returns the current caller annotation settings of the logger.
+func (l *Logger) GetCallerEnabled() (callerEnabled bool, funcEnabled bool) {
+	c := l.loadConfigs()
+
+	return c.callerEnabled, c.callerFuncEnabled
+}
+
+// AddCaller enables or disables the annotation of the "file:line" location each entry is logged from.
+// The location is rendered as a "caller" field by the JSON, YAML and logfmt encoders, and as a header element
+// by the default encoder.
+func (l *Logger) AddCaller(addCaller bool) *Logger {
+	return l.update(func(c *loggerConfigs) {
+		c.callerEnabled = addCaller
+	})
+}
+
+// AddCallerFunc enables or disables the annotation of the function name each entry is logged from,
+// rendered as a "func" field or next to the caller location in the default encoder header.
+// It takes effect only while the caller annotation is enabled through AddCaller.
+func (l *Logger) AddCallerFunc(addCallerFunc bool) *Logger {
+	return l.update(func(c *loggerConfigs) {
+		c.callerFuncEnabled = addCallerFunc
+	})
+}
+
+// AddCallerSkip increases the number of stack frames skipped when retrieving the caller, so that libraries
+// wrapping the Logger can report the location of their own callers. Negative values decrease it, down to 0.
+// The skip does not apply to the entries logged through the SlogHandler, which carry their own caller.
+// On a child Logger, the skip applies only to the child and to the loggers derived from it.
+func (l *Logger) AddCallerSkip(skip int) *Logger {
+	if l.owner != nil {
+		l.updateMu.Lock()
+		defer l.updateMu.Unlock()
+
+		overlay := *l.overlay.Load()
+		overlay.callerSkip += skip
+		l.overlay.Store(&overlay)
+
+		return l
+	}
+
+	return l.update(func(c *loggerConfigs) {
+		c.callerSkip = max(c.callerSkip+skip, 0)
+	})
+}
+
+// GetStacktraceLvlName returns the log level from which the stack traces are captured,
+// or an empty string if the stack traces are disabled.
+func (l *Logger) GetStacktraceLvlName() ll.LogLvlName {
+	c := l.loadConfigs()
+	if !c.stacktraceEnabled {
+		return ""
+	}
+
+	return ll.LogLvlIntToName[c.stacktraceLvl]
+}
+
+// AddStacktrace enables the capture of the stack trace for every entry logged at the given level or at a more
+// severe one. The trace is rendered as a multi-line block by the default encoder, as a "stacktrace" field by the
+// JSON and logfmt encoders and as a block scalar by the YAML encoder.
+// If the provided name is invalid, it defaults to DebugLvlName.
+func (l *Logger) AddStacktrace(logLvlName ll.LogLvlName) *Logger {
+	return l.update(func(c *loggerConfigs) {
+		c.stacktraceEnabled = true
+		c.stacktraceLvl = ll.RetrieveLogLvlIntFromName(logLvlName)
+	})
+}
+
+// DisableStacktrace disables the capture of the stack traces enabled through AddStacktrace.
+func (l *Logger) DisableStacktrace() *Logger {
+	return l.update(func(c *loggerConfigs) {
+		c.stacktraceEnabled = false
+	})
+}
+
+// TrimStacktrace enables or disables the removal of the runtime and testing package frames from the stack traces.
+func (l *Logger) TrimStacktrace(trim bool) *Logger {
+	return l.update(func(c *loggerConfigs) {
+		c.stacktraceTrim = trim
+	})
+}
+
+// DumpGoroutinesOnFatal enables or disables the dump of the stack traces of all the goroutines on FatalError.
+// The dump is rendered in place of the entry stack trace, even if the stack traces are disabled.
+func (l *Logger) DumpGoroutinesOnFatal(enable bool) *Logger {
+	return l.update(func(c *loggerConfigs) {
+		c.fatalDumpEnabled = enable
+	})
 }
 
 // GetEncoderType returns the currently set Encoder type.
 func (l *Logger) GetEncoderType() s.EncoderType {
-	return l.encoder.GetType()
+	return l.loadConfigs().GetEncoderType()
 }
 
 // SetEncoder sets the Encoder that will be used to print logs.
+// Besides the built-in types, any custom encoder type registered through encoders.Register can be used.
 func (l *Logger) SetEncoder(encoderType s.EncoderType) *Logger {
-	switch encoderType {
-	case s.DefaultEncoderType:
-		l.encoder = encoders.NewDefaultEncoder(l.printer, l.dateTimePrinter)
-	case s.JsonEncoderType:
-		l.encoder = encoders.NewJSONEncoder(l.printer, services.NewJsonMarshaler(), l.dateTimePrinter)
-	case s.YamlEncoderType:
-		l.encoder = encoders.NewYAMLEncoder(l.printer, services.NewYamlMarshaler(), l.dateTimePrinter)
+	return l.update(func(c *loggerConfigs) {
+		if encoder := c.newEncoder(encoderType); encoder != nil {
+			c.encoder = encoder
+		}
+	})
+}
+
+// SetCustomEncoder sets the given Encoder instance as the one that will be used to print logs.
+// The instance is shared by the child loggers created through With, so it must be safe for concurrent use.
+// If the given encoder is nil, a warning is logged and the method does nothing.
+func (l *Logger) SetCustomEncoder(encoder s.EncoderInterface) *Logger {
+	if encoder == nil {
+		l.Warn("the given encoder is nil, skipping encoder change")
+		return l
 	}
 
-	return l
+	return l.update(func(c *loggerConfigs) {
+		c.encoder = encoder
+	})
+}
+
+// EscapeJsonHTML enables or disables the escaping of '<', '>' and '&' inside the strings written by the
+// JSON encoders of the Logger and its destinations, making the entries safe to be embedded in HTML.
+// It should be set before binding fields through With, since the bound fields are encoded only once.
+func (l *Logger) EscapeJsonHTML(enable bool) *Logger {
+	return l.update(func(c *loggerConfigs) {
+		c.jsonEscapeHTML = enable
+
+		// The JSON encoders in use may be shared with concurrent log calls, so they are replaced, not modified
+		if _, ok := c.encoder.(*encoders.JSONEncoder); ok {
+			c.encoder = c.newEncoder(s.JsonEncoderType)
+		}
+
+		for _, d := range c.destinations {
+			if _, ok := d.encoder.(*encoders.JSONEncoder); ok {
+				d.encoder = c.newEncoder(s.JsonEncoderType)
+			}
+		}
+	})
+}
+
+// AddDestination adds a destination every log entry is also written to, with its own minimum log level,
+// encoder type and colors setting. The remaining settings (date, time, log level visibility...) are
+// inherited from the Logger. Unknown encoder types fall back to the default encoder.
+// If the given writer is nil, a warning is logged and the method does nothing.
+func (l *Logger) AddDestination(
+	w io.Writer,
+	logLvlName ll.LogLvlName,
+	encoderType s.EncoderType,
+	colorsEnabled bool,
+) *Logger {
+	c := l.loadConfigs()
+
+	encoder := c.newEncoder(encoderType)
+	if encoder == nil {
+		encoder = c.newEncoder(s.DefaultEncoderType)
+	}
+
+	return l.AddDestinationWithEncoder(w, logLvlName, encoder, colorsEnabled)
+}
+
+// AddDestinationWithEncoder adds a destination like AddDestination does, but using the given Encoder instance.
+// If the given writer or encoder is nil, a warning is logged and the method does nothing.
+func (l *Logger) AddDestinationWithEncoder(
+	w io.Writer,
+	logLvlName ll.LogLvlName,
+	encoder s.EncoderInterface,
+	colorsEnabled bool,
+) *Logger {
+	if w == nil {
+		l.Warn("the given destination writer is nil, skipping destination")
+		return l
+	}
+
+	if encoder == nil {
+		l.Warn("the given destination encoder is nil, skipping destination")
+		return l
+	}
+
+	return l.update(func(c *loggerConfigs) {
+		destination := &Destination{
+			configs:       c,
+			writer:        w,
+			logLvl:        ll.RetrieveLogLvlIntFromName(logLvlName),
+			encoder:       encoder,
+			colorsEnabled: colorsEnabled,
+		}
+
+		c.destinations = append(c.destinations, destination)
+		c.destinationsLvl = max(c.destinationsLvl, destination.logLvl)
+		c.refreshAsyncOutputs()
+	})
+}
+
+// GetDestinations returns the destinations added to the Logger through AddDestination.
+func (l *Logger) GetDestinations() []*Destination {
+	return l.loadConfigs().destinations
+}
+
+// ClearDestinations removes all the destinations added to the Logger through AddDestination.
+func (l *Logger) ClearDestinations() *Logger {
+	return l.update(func(c *loggerConfigs) {
+		c.destinations = nil
+		c.destinationsLvl = math.MinInt8
+	})
 }
 
 // GetLogFile returns the current log file. If no file is set, it returns nil.
 func (l *Logger) GetLogFile() *os.File {
-	return l.outFile
+	return l.loadConfigs().outFile
 }
 
 // SetLogFile sets the given os.File as the current Logger output log file.
@@ -178,38 +542,353 @@ func (l *Logger) SetLogFile(file *os.File) *Logger {
 		return l
 	}
 
-	l.outFile = file
-	return l
+	return l.SetOutput(file)
 }
 
-// CloseLogFile closes the current log file if it exists. If no file is set, a warning is logged
+// CloseLogFile closes the current output if it implements io.Closer. If no output is set, a warning is logged
+// and the method does nothing.
+// The output is detached from the Logger before being closed, so that no new entry is written to it.
+// The output is owned by the Logger the child loggers derive from: on a child, a warning is logged
 // and the method does nothing.
 func (l *Logger) CloseLogFile() error {
-	if l.outFile == nil {
+	if l.owner != nil {
+		l.Warn("the log file is owned by the parent Logger, skipping close")
+		return nil
+	}
+
+	var output io.Writer
+
+	l.update(func(c *loggerConfigs) {
+		output = c.output
+		c.output = nil
+		c.outFile = nil
+		c.refreshAsyncOutputs()
+	})
+
+	if output == nil {
 		l.Warn("no log file opened, skipping close")
 		return nil
 	}
 
-	if err := l.outFile.Close(); err != nil {
-		return err
+	l.Flush()
+
+	if closer, ok := output.(io.Closer); ok {
+		return closer.Close()
 	}
 
-	l.outFile = nil
 	return nil
 }
 
+// GetOutput returns the writer all the logs are currently redirected to. If no output is set, it returns nil.
+func (l *Logger) GetOutput() io.Writer {
+	return l.loadConfigs().output
+}
+
+// SetOutput redirects all the Logger output to the given io.Writer (a bytes.Buffer, a net.Conn, a pipe...).
+// If the given writer is nil, a warning is logged and the method does nothing.
+func (l *Logger) SetOutput(w io.Writer) *Logger {
+	if w == nil {
+		l.Warn("the given output writer is nil, skipping logs redirection")
+		return l
+	}
+
+	return l.update(func(c *loggerConfigs) {
+		c.output = w
+		c.outFile, _ = w.(*os.File)
+		c.refreshAsyncOutputs()
+	})
+}
+
+// SetStdOutWriter sets the writer used in place of os.Stdout when no output is set.
+// A nil writer restores os.Stdout.
+func (l *Logger) SetStdOutWriter(w io.Writer) *Logger {
+	return l.update(func(c *loggerConfigs) {
+		c.stdOutWriter = w
+		c.refreshAsyncOutputs()
+	})
+}
+
+// SetStdErrWriter sets the writer used in place of os.Stderr when no output is set.
+// A nil writer restores os.Stderr.
+func (l *Logger) SetStdErrWriter(w io.Writer) *Logger {
+	return l.update(func(c *loggerConfigs) {
+		c.stdErrWriter = w
+		c.refreshAsyncOutputs()
+	})
+}
+
+// GetOutputWriter returns the writer bound to the given output type.
+// A nil return value means the standard output or standard error should be used.
+func (l *Logger) GetOutputWriter(outType s.OutputType) io.Writer {
+	return l.loadConfigs().GetOutputWriter(outType)
+}
+
+// SetAsync enables the asynchronous logging mode: encoded log entries are pushed into a bounded ring buffer
+// and written by a background goroutine, following the given overflow policy when the buffer is full.
+// If the async mode is already enabled, the pending entries are flushed before applying the new config.
+// The AsyncWriter is owned by the Logger the child loggers derive from: on a child, a warning is logged
+// and the method does nothing.
+func (l *Logger) SetAsync(config sinks.AsyncConfig) *Logger {
+	if l.owner != nil {
+		l.Warn("the async mode is owned by the parent Logger, skipping async mode change")
+		return l
+	}
+
+	l.swapAsyncWriter(sinks.NewAsyncWriter(config))
+
+	return l
+}
+
+// GetAsyncWriter returns the AsyncWriter used in async mode, or nil if the async mode is disabled.
+func (l *Logger) GetAsyncWriter() *sinks.AsyncWriter {
+	return l.loadConfigs().asyncWriter
+}
+
+// Flush blocks until all the pending async log entries are written. It does nothing in synchronous mode.
+func (l *Logger) Flush() {
+	if asyncWriter := l.loadConfigs().asyncWriter; asyncWriter != nil {
+		asyncWriter.Flush()
+	}
+}
+
+// DisableAsync drains the pending async log entries, stops the background writer and
+// switches the Logger back to synchronous mode. Like SetAsync, it does nothing on a child Logger.
+func (l *Logger) DisableAsync() *Logger {
+	if l.owner != nil {
+		l.Warn("the async mode is owned by the parent Logger, skipping async mode change")
+		return l
+	}
+
+	if l.loadConfigs().asyncWriter != nil {
+		l.swapAsyncWriter(nil)
+	}
+
+	return l
+}
+
 // GetDateTimeFormat returns the current DateTimeFormat of the logger.
 func (l *Logger) GetDateTimeFormat() s.DateTimeFormat {
-	return l.dateTimeFormat
+	return l.loadConfigs().GetDateTimeFormat()
 }
 
 // SetDateTimeFormat sets the DateTimeFormat of the logger.
 func (l *Logger) SetDateTimeFormat(format s.DateTimeFormat) *Logger {
-	l.dateTimeFormat = format
+	return l.update(func(c *loggerConfigs) {
+		c.dateTimeFormat = format
+	})
+}
+
+// update applies the given changes to a copy of the current configs and atomically swaps it in.
+// The updates are serialized, so that concurrent setters never overwrite each other's changes.
+// The updates of a child Logger are skipped with a warning, since its configs belong to its owner.
+func (l *Logger) update(apply func(c *loggerConfigs)) *Logger {
+	if l.owner != nil {
+		l.Warn("the configuration is owned by the parent Logger, skipping configuration change")
+		return l
+	}
+
+	l.updateMu.Lock()
+	defer l.updateMu.Unlock()
+
+	c := l.loadConfigs().clone()
+	apply(c)
+	l.configs.Store(c)
 
 	return l
 }
 
+// swapAsyncWriter replaces the current AsyncWriter with the given one, or switches the Logger to synchronous mode
+// if it is nil. The replaced writer is closed after the swap: since the writes performed after Close are
+// executed synchronously, the entries logged concurrently through the previous configs are never lost.
+func (l *Logger) swapAsyncWriter(asyncWriter *sinks.AsyncWriter) {
+	var previous *sinks.AsyncWriter
+
+	l.update(func(c *loggerConfigs) {
+		previous = c.asyncWriter
+		c.asyncWriter = asyncWriter
+		c.refreshAsyncOutputs()
+	})
+
+	if previous != nil {
+		_ = previous.Close()
+	}
+}
+
+// loadConfigs returns the current configs of the Logger. The configs of a child Logger are derived from the ones
+// of its owner and cached until either the owner configs or the child overlay change.
+func (l *Logger) loadConfigs() *loggerConfigs {
+	if l.owner == nil {
+		return l.configs.Load()
+	}
+
+	base, overlay := l.owner.configs.Load(), l.overlay.Load()
+	if c := l.configs.Load(); c != nil && c.base == base && c.overlay == overlay {
+		return c
+	}
+
+	c := base.clone()
+	c.base, c.overlay = base, overlay
+	c.fields = overlay.fields
+	c.name = overlay.name
+	c.callerSkip = max(base.callerSkip+overlay.callerSkip, 0)
+	c.resolveNamedLvl()
+	l.configs.Store(c)
+
+	return c
+}
+
+// newChild returns a child Logger sharing the configs of the Logger, with the given changes applied
+// to a copy of its overlay.
+func (l *Logger) newChild(apply func(o *childOverlay)) *Logger {
+	child := &Logger{owner: l}
+	overlay := childOverlay{}
+
+	if l.owner != nil {
+		child.owner = l.owner
+		overlay = *l.overlay.Load()
+	}
+
+	apply(&overlay)
+	child.overlay.Store(&overlay)
+
+	return child
+}
+
+// isLvlEnabled returns true if the Logger or any of its destinations allows the given log level.
+func (l *Logger) isLvlEnabled(lvl int8) bool {
+	return l.loadConfigs().isLvlEnabled(lvl)
+}
+
+// log sends the given args to the Logger encoder and to every destination that allows the given log level,
+// retrieving the caller and the stack trace if they are enabled.
+// It must be called directly by the logging methods for the caller skip depth to be correct.
+func (l *Logger) log(lvl int8, lvlName ll.LogLvlName, outType s.OutputType, args ...any) {
+	c := l.loadConfigs()
+
+	var pc uintptr
+	if c.callerEnabled || c.needsCallSite() {
+		pc = c.callerPC()
+	}
+
+	loggerLvl := c.loggerLvlAt(pc)
+	if isForcedLvl(lvl) {
+		// The entries terminating the application, or more severe, are always written, whatever the Logger level
+		loggerLvl = max(loggerLvl, lvl)
+	} else if loggerLvl < lvl && c.destinationsLvl < lvl {
+		return
+	}
+
+	if c.deduplicator != nil && c.deduplicator.isDuplicate(l, c, pc, lvl, lvlName, outType, args) {
+		return
+	}
+
+	if c.sampler != nil && c.isSampledOut(l, lvl, args[0]) {
+		return
+	}
+
+	var stacktrace string
+	if lvl == ll.FatalErrorLvl && c.fatalDumpEnabled {
+		stacktrace = dumpGoroutines()
+	} else if c.isStacktraceEnabled(lvl) {
+		// The trace starts from the code calling the logging method, two frames above log
+		stacktrace = c.captureStacktrace(2+c.callerSkip, 0)
+	}
+
+	if !c.callerEnabled {
+		pc = 0
+	}
+
+	c.logWith(loggerLvl, pc, stacktrace, time.Time{}, lvl, lvlName, outType, args...)
+}
+
+// logWith sends the given args to the Logger encoder, if the given Logger level allows the given log level,
+// and to every destination that allows it, annotating the entry with the given stack trace, the caller
+// resolved from the given program counter and the given entry time, unless they are empty.
+// The entries with a zero time are logged at the current time.
+func (c *loggerConfigs) logWith(
+	loggerLvl int8,
+	pc uintptr,
+	stacktrace string,
+	entryTime time.Time,
+	lvl int8,
+	lvlName ll.LogLvlName,
+	outType s.OutputType,
+	args ...any,
+) {
+	var caller *s.Caller
+	if pc != 0 {
+		caller = services.RetrieveCaller(pc)
+	}
+
+	if loggerLvl >= lvl {
+		c.encoder.Log(c.withEntryInfo(c, caller, stacktrace, entryTime), lvlName, c.checkOutFile(outType), args...)
+	}
+
+	for _, d := range c.destinations {
+		if d.logLvl >= lvl {
+			d.encoder.Log(c.withEntryInfo(d, caller, stacktrace, entryTime), lvlName, s.FileOutput, args...)
+		}
+	}
+}
+
+// isLvlEnabled returns true if the configs, any of their log level overrides or any of their destinations
+// allow the given log level.
+func (c *loggerConfigs) isLvlEnabled(lvl int8) bool {
+	return c.logLvl.Lvl >= lvl || c.destinationsLvl >= lvl || (c.lvlOverrides != nil && c.lvlOverrides.maxLvl >= lvl)
+}
+
+// isStacktraceEnabled returns true if a stack trace must be captured for the entries of the given log level.
+func (c *loggerConfigs) isStacktraceEnabled(lvl int8) bool {
+	return c.stacktraceEnabled && lvl <= c.stacktraceLvl
+}
+
+// refreshAsyncOutputs wraps the current output writers with the AsyncWriter, if the async mode is enabled.
+// It must be called only on configs that are not in use yet.
+func (c *loggerConfigs) refreshAsyncOutputs() {
+	if c.asyncWriter == nil {
+		c.asyncOutputs = [3]io.Writer{}
+
+		for _, d := range c.destinations {
+			d.asyncWriter = nil
+		}
+
+		return
+	}
+
+	c.asyncOutputs[s.StdOutput] = c.asyncWriter.Wrap(stdWriterOr(c.stdOutWriter, s.StdOutput))
+	c.asyncOutputs[s.StdErrOutput] = c.asyncWriter.Wrap(stdWriterOr(c.stdErrWriter, s.StdErrOutput))
+	c.asyncOutputs[s.FileOutput] = nil
+
+	if c.output != nil {
+		c.asyncOutputs[s.FileOutput] = c.asyncWriter.Wrap(c.output)
+	}
+
+	for _, d := range c.destinations {
+		d.asyncWriter = c.asyncWriter.Wrap(d.writer)
+	}
+}
+
+// newEncoder returns a new encoder of the given type, or nil if the type is unknown.
+func (c *loggerConfigs) newEncoder(encoderType s.EncoderType) s.EncoderInterface {
+	switch encoderType {
+	case s.DefaultEncoderType:
+		return encoders.NewDefaultEncoder(c.printer, c.dateTimePrinter)
+	case s.JsonEncoderType:
+		return encoders.NewJSONEncoder(c.printer, services.NewJsonMarshaler(), c.dateTimePrinter).
+			SetEscapeHTML(c.jsonEscapeHTML)
+	case s.YamlEncoderType:
+		return encoders.NewYAMLEncoder(c.printer, services.NewYamlMarshaler(), c.dateTimePrinter)
+	case s.LogfmtEncoderType:
+		return encoders.NewLogfmtEncoder(c.printer, services.NewLogfmtMarshaler(), c.dateTimePrinter)
+	case s.SyslogEncoderType:
+		return encoders.NewSyslogEncoder(encoders.SyslogConfig{})
+	case s.JournaldEncoderType:
+		return encoders.NewJournaldEncoder(encoders.JournaldConfig{})
+	}
+
+	return encoders.NewRegistered(encoderType)
+}
+
 // areAllNil returns true if all the given args are 'nil', false otherwise.
 func (l *Logger) areAllNil(args ...any) bool {
 	for _, arg := range args {
@@ -221,9 +900,30 @@ func (l *Logger) areAllNil(args ...any) bool {
 	return true
 }
 
-// checkOutFile returns FileOutput if a log file is set, otherwise returns the provided outType.
-func (l *Logger) checkOutFile(outType s.OutputType) s.OutputType {
-	if l.outFile != nil {
+// isForcedLvl returns true if the entries of the given log level are written whatever the Logger level:
+// the PANIC and FATAL_ERROR ones, and the ones of the custom levels more severe than them.
+func isForcedLvl(lvl int8) bool {
+	return lvl <= ll.FatalErrorLvl
+}
+
+// lvlOutputType returns the standard output type of the given log level:
+// StdErrOutput for the levels from ErrorLvl up in severity, StdOutput for the others.
+func lvlOutputType(lvl int8) s.OutputType {
+	if lvl <= ll.ErrorLvl {
+		return s.StdErrOutput
+	}
+
+	return s.StdOutput
+}
+
+// concatenateArgs returns the given args formatted with their default format and concatenated by a white space.
+func concatenateArgs(args ...any) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
+
+// checkOutFile returns FileOutput if an output writer is set, otherwise returns the provided outType.
+func (c *loggerConfigs) checkOutFile(outType s.OutputType) s.OutputType {
+	if c.output != nil {
 		return s.FileOutput
 	}
 
@@ -232,11 +932,19 @@ func (l *Logger) checkOutFile(outType s.OutputType) s.OutputType {
 
 // NewLogger creates and returns a new Logger instance with default settings.
 func NewLogger() *Logger {
-	logger := &Logger{showLogLevel: true, dateTimeFormat: s.IT}
-	logger.SetLogLvlEnvVariable(ll.DefaultEnvLogLvlVar)
-	logger.printer = services.NewPrinter()
-	logger.dateTimePrinter = services.GetDateTimePrinter()
-	logger.SetEncoder(s.DefaultEncoderType)
+	c := &loggerConfigs{showLogLevel: true, dateTimeFormat: s.IT, destinationsLvl: math.MinInt8}
+	c.logLvl.EnvVariable = ll.DefaultEnvLogLvlVar
+	c.logLvl.Lvl = ll.RetrieveLogLvlFromEnv(c.logLvl.EnvVariable)
+	c.printer = services.NewPrinter()
+	c.dateTimePrinter = services.GetDateTimePrinter()
+	c.encoder = c.newEncoder(s.DefaultEncoderType)
+
+	logger := &Logger{}
+	logger.configs.Store(c)
+
+	if spec := os.Getenv(ll.DefaultEnvLvlOverridesVar); spec != "" {
+		logger.SetLvlOverrides(spec)
+	}
 
 	return logger
 }
@@ -4,13 +4,19 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"sync"
 	"testing"
 
 	"github.com/Pho3b/tiny-logger/logs/colors"
+	"github.com/Pho3b/tiny-logger/logs/encoders"
 	"github.com/Pho3b/tiny-logger/logs/log_level"
+	"github.com/Pho3b/tiny-logger/logs/sinks"
 	"github.com/Pho3b/tiny-logger/shared"
 	"github.com/Pho3b/tiny-logger/test"
 	"github.com/stretchr/testify/assert"
@@ -20,18 +26,18 @@ func TestLoggerLogLvl(t *testing.T) {
 	testLogsLvlVar1 := "MY_INSTANCE_LOGS_LVL"
 	testLogsLvlVar2 := "MY_INSTANCE_LOGS_LVL_2"
 	logger := NewLogger()
-	assert.Equal(t, log_level.DebugLvl, logger.logLvl.Lvl)
+	assert.Equal(t, log_level.DebugLvl, logger.configs.Load().logLvl.Lvl)
 
 	_ = os.Setenv(testLogsLvlVar1, string(log_level.WarnLvlName))
 	logger = NewLogger()
 	logger.SetLogLvlEnvVariable(testLogsLvlVar1)
-	assert.Equal(t, log_level.WarnLvl, logger.logLvl.Lvl)
+	assert.Equal(t, log_level.WarnLvl, logger.configs.Load().logLvl.Lvl)
 
 	_ = os.Setenv(testLogsLvlVar2, string(log_level.InfoLvlName))
 	logger = NewLogger()
 	logger.SetLogLvlEnvVariable(testLogsLvlVar2)
-	assert.NotEqual(t, log_level.WarnLvl, logger.logLvl.Lvl)
-	assert.Equal(t, log_level.InfoLvl, logger.logLvl.Lvl)
+	assert.NotEqual(t, log_level.WarnLvl, logger.configs.Load().logLvl.Lvl)
+	assert.Equal(t, log_level.InfoLvl, logger.configs.Load().logLvl.Lvl)
 
 	_ = os.Unsetenv(testLogsLvlVar1)
 	_ = os.Unsetenv(testLogsLvlVar2)
@@ -157,6 +163,201 @@ func TestLogger_FatalError(t *testing.T) {
 	assert.Equal(t, buf.String(), "")
 }
 
+// stringerCounter counts the times it is formatted.
+type stringerCounter struct {
+	calls int
+}
+
+func (c *stringerCounter) String() string {
+	c.calls++
+	return "counter"
+}
+
+func TestLogger_FormattingMethods(t *testing.T) {
+	var out, errOut bytes.Buffer
+	var logger shared.LoggerInterface = NewLogger().SetStdOutWriter(&out).SetStdErrWriter(&errOut)
+
+	logger.Debugf("debug %d", 1)
+	logger.Infof("info %s", "two")
+	logger.Warnf("warn %.1f", 3.0)
+	logger.Errorf("error %v", []int{4})
+	assert.Equal(t, "DEBUG: debug 1\nINFO: info two\nWARN: warn 3.0\n", out.String())
+	assert.Equal(t, "ERROR: error [4]\n", errOut.String())
+}
+
+func TestLogger_FormattingMethods_Lazy(t *testing.T) {
+	var out bytes.Buffer
+	counter := &stringerCounter{}
+	logger := NewLogger().SetStdOutWriter(&out).SetLogLvl(log_level.WarnLvlName)
+
+	logger.Debugf("debug %s", counter)
+	logger.Infof("info %s", counter)
+	assert.Equal(t, 0, counter.calls)
+	assert.Empty(t, out.String())
+
+	logger.Warnf("warn %s", counter)
+	assert.Equal(t, 1, counter.calls)
+	assert.Equal(t, "WARN: warn counter\n", out.String())
+}
+
+func TestLogger_FormattingMethods_Encoders(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).SetEncoder(shared.JsonEncoderType)
+
+	logger.Infof("user %q logged in %d times", "alice", 3)
+	assert.Equal(t, `{"level":"INFO","msg":"user \"alice\" logged in 3 times"}`+"\n", out.String())
+
+	out.Reset()
+	logger.SetEncoder(shared.YamlEncoderType)
+	logger.Infof("user %s logged in %d times", "alice", 3)
+	assert.Equal(t, "---\nlevel: INFO\nmsg: user alice logged in 3 times\n\n", out.String())
+}
+
+func TestLogger_FatalErrorf(t *testing.T) {
+	if os.Getenv("BE_CRASHER") == "1" {
+		NewLogger().FatalErrorf("fatal %s", "message")
+		return
+	}
+
+	var errOut bytes.Buffer
+	cmd := exec.Command(os.Args[0], "-test.run=TestLogger_FatalErrorf")
+	cmd.Env = append(os.Environ(), "BE_CRASHER=1")
+	cmd.Stderr = &errOut
+
+	err := cmd.Run()
+	exitError, ok := err.(*exec.ExitError)
+	assert.True(t, ok && exitError.ExitCode() == 1)
+	assert.Equal(t, "FATAL_ERROR: fatal message\n", errOut.String())
+}
+
+func TestLogger_FatalError_AllLvls(t *testing.T) {
+	const criticalLvlName = log_level.LogLvlName("FATAL_TEST_CRITICAL")
+
+	if lvlName := os.Getenv("BE_CRASHER_LVL"); lvlName != "" {
+		_ = log_level.RegisterLogLvl(criticalLvlName, int8(-30), colors.Red)
+		logger := NewLogger().SetLogLvl(log_level.LogLvlName(lvlName)).SetLvlOverrides("logs=" + lvlName)
+		logger.FatalError("fatal message")
+		return
+	}
+
+	for _, lvlName := range []log_level.LogLvlName{
+		log_level.TraceLvlName,
+		log_level.InfoLvlName,
+		log_level.ErrorLvlName,
+		log_level.FatalErrorLvlName,
+		log_level.PanicLvlName,
+		criticalLvlName,
+	} {
+		var errOut bytes.Buffer
+		cmd := exec.Command(os.Args[0], "-test.run=TestLogger_FatalError_AllLvls")
+		cmd.Env = append(os.Environ(), "BE_CRASHER_LVL="+lvlName.String())
+		cmd.Stderr = &errOut
+
+		err := cmd.Run()
+		exitError, ok := err.(*exec.ExitError)
+		assert.True(t, ok && exitError.ExitCode() == 1, lvlName)
+		assert.Equal(t, "FATAL_ERROR: fatal message\n", errOut.String(), lvlName)
+	}
+}
+
+func TestLogger_Panic_CustomLvl(t *testing.T) {
+	const criticalLvlName = log_level.LogLvlName("PANIC_TEST_CRITICAL")
+	assert.NoError(t, log_level.RegisterLogLvl(criticalLvlName, int8(-31), colors.Red))
+
+	var errOut bytes.Buffer
+	logger := NewLogger().SetStdErrWriter(&errOut).SetLogLvl(criticalLvlName)
+
+	assert.PanicsWithValue(t, "connection lost", func() { logger.Panic("connection lost") })
+	assert.PanicsWithValue(t, "log panic", func() { logger.Log(log_level.PanicLvlName, "log panic") })
+	assert.Equal(t, "PANIC: connection lost\nPANIC: log panic\n", errOut.String())
+}
+
+func TestLogger_Log_CustomLvlOrder(t *testing.T) {
+	const noticeLvlName = log_level.LogLvlName("LOGGER_NOTICE")
+	const criticalLvlName = log_level.LogLvlName("LOGGER_CRITICAL")
+	const offLvlName = log_level.LogLvlName("LOGGER_OFF")
+	assert.NoError(t, log_level.RegisterLogLvl(noticeLvlName, log_level.WarnLvl+5, colors.Cyan))
+	assert.NoError(t, log_level.RegisterLogLvl(criticalLvlName, log_level.PanicLvl-3, colors.Red))
+	assert.NoError(t, log_level.RegisterLogLvl(offLvlName, log_level.PanicLvl-50, colors.Gray))
+
+	var out, errOut bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).SetStdErrWriter(&errOut).SetLogLvl(log_level.WarnLvlName)
+
+	// NOTICE is less severe than WARN and more severe than INFO
+	logger.Log(noticeLvlName, "notice message")
+	assert.Empty(t, out.String())
+
+	logger.SetLogLvl(noticeLvlName)
+	logger.Log(noticeLvlName, "notice message")
+	logger.Info("info message")
+	logger.Warn("warn message")
+	assert.Equal(t, "LOGGER_NOTICE: notice message\nWARN: warn message\n", out.String())
+
+	// The levels more severe than PANIC are always written, as PANIC and FATAL_ERROR
+	logger.SetLogLvl(offLvlName)
+	logger.Log(criticalLvlName, "critical message")
+	assert.Equal(t, "LOGGER_CRITICAL: critical message\n", errOut.String())
+}
+
+func TestLogger_Trace(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out)
+
+	logger.Trace("trace message")
+	logger.Tracef("trace %s", "message")
+	assert.Empty(t, out.String())
+
+	logger.SetLogLvl(log_level.TraceLvlName)
+	logger.Trace("trace message")
+	logger.Tracef("trace %s", "formatted")
+	assert.Equal(t, "TRACE: trace message\nTRACE: trace formatted\n", out.String())
+}
+
+func TestLogger_Panic(t *testing.T) {
+	var errOut bytes.Buffer
+	logger := NewLogger().SetStdErrWriter(&errOut).SetLogLvl(log_level.FatalErrorLvlName)
+
+	assert.NotPanics(t, func() { logger.Panic(nil) })
+	assert.PanicsWithValue(t, "connection lost 3", func() { logger.Panic("connection lost", 3) })
+	assert.PanicsWithValue(t, "panic formatted", func() { logger.Panicf("panic %s", "formatted") })
+	assert.Equal(t, "PANIC: connection lost 3\nPANIC: panic formatted\n", errOut.String())
+}
+
+func TestLogger_Log(t *testing.T) {
+	const auditLvlName, auditLvl = log_level.LogLvlName("LOGGER_AUDIT"), int8(-25)
+	assert.NoError(t, log_level.RegisterLogLvl(auditLvlName, auditLvl, colors.BrightBlue))
+
+	var out, errOut bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).SetStdErrWriter(&errOut).SetLogLvl(log_level.InfoLvlName)
+
+	logger.Log(log_level.InfoLvlName, "info message")
+	logger.Log(log_level.DebugLvlName, "debug message")
+	logger.Log(auditLvlName, "user deleted", "id", 3)
+	logger.Log(log_level.ErrorLvlName, nil)
+	logger.Log(log_level.WarnLvlName)
+	assert.Equal(t, "INFO: info message\n", out.String())
+	assert.Equal(t, "LOGGER_AUDIT: user deleted id 3\n", errOut.String())
+
+	out.Reset()
+	logger.SetLogLvl(log_level.DebugLvlName)
+	logger.Log("UNKNOWN", "unknown level message")
+	assert.Equal(t, "DEBUG: unknown level message\n", out.String())
+
+	out.Reset()
+	errOut.Reset()
+	logger.EnableColors(true)
+	logger.Log(auditLvlName, "colored")
+	assert.Equal(
+		t,
+		colors.BrightBlue.String()+"LOGGER_AUDIT: "+colors.Reset.String()+"colored\n",
+		errOut.String(),
+	)
+
+	logger.SetLogLvl(auditLvlName)
+	assert.PanicsWithValue(t, "panic message", func() { logger.Log(log_level.PanicLvlName, "panic message") })
+	assert.Equal(t, auditLvlName, logger.GetLogLvlName())
+}
+
 func TestLogger_BuildingMethods(t *testing.T) {
 	logger := NewLogger()
 	assert.IsType(t, &Logger{}, logger)
@@ -167,24 +368,24 @@ func TestLogger_BuildingMethods(t *testing.T) {
 func TestLogger_AddDateTime(t *testing.T) {
 	logger := NewLogger()
 	logger.AddDate(true)
-	assert.True(t, logger.dateEnabled)
+	assert.True(t, logger.configs.Load().dateEnabled)
 
 	logger.AddDate(false)
-	assert.False(t, logger.dateEnabled)
+	assert.False(t, logger.configs.Load().dateEnabled)
 
 	logger.AddTime(true)
-	assert.True(t, logger.timeEnabled)
+	assert.True(t, logger.configs.Load().timeEnabled)
 
 	logger.AddTime(false)
-	assert.False(t, logger.timeEnabled)
+	assert.False(t, logger.configs.Load().timeEnabled)
 
 	logger.AddDateTime(true)
-	assert.True(t, logger.dateEnabled)
-	assert.True(t, logger.timeEnabled)
+	assert.True(t, logger.configs.Load().dateEnabled)
+	assert.True(t, logger.configs.Load().timeEnabled)
 
 	logger.AddDateTime(false)
-	assert.False(t, logger.dateEnabled)
-	assert.False(t, logger.timeEnabled)
+	assert.False(t, logger.configs.Load().dateEnabled)
+	assert.False(t, logger.configs.Load().timeEnabled)
 }
 
 func TestLogger_EnableColors(t *testing.T) {
@@ -219,6 +420,138 @@ func writeDummyLogsWithNewLogger(logsNumber int, wg *sync.WaitGroup, logger *Log
 	}
 }
 
+// syncBuffer is a bytes.Buffer safe for concurrent writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestLogger_ConcurrentReconfiguration(t *testing.T) {
+	var out, errOut, file, dest syncBuffer
+	var loggers, setters sync.WaitGroup
+
+	logger := NewLogger().SetStdOutWriter(&out).SetStdErrWriter(&errOut)
+	slogger := slog.New(NewSlogHandler(logger))
+	stop := make(chan struct{})
+
+	for i := 0; i < 8; i++ {
+		loggers.Add(1)
+		go func() {
+			defer loggers.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				logger.Debug("debug message", "key", 1)
+				logger.Infof("info message %d", 2)
+				logger.Error("error message")
+				logger.With("id", 3).Warn("warn message")
+				logger.Color(colors.Cyan, "colored message")
+				slogger.Info("slog message", "key", 4)
+				_ = logger.GetLogLvlName()
+				_ = logger.GetEncoderType()
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		setters.Add(1)
+		go func() {
+			defer setters.Done()
+
+			logger.AddCallerSkip(1)
+		}()
+	}
+
+	encoderTypes := []shared.EncoderType{
+		shared.DefaultEncoderType, shared.JsonEncoderType, shared.YamlEncoderType, shared.LogfmtEncoderType,
+	}
+
+	for i := 0; i < 200; i++ {
+		enable := i%2 == 0
+		logger.
+			SetLogLvl(log_level.LogLvlIntToName[int8(i%5)]).
+			SetEncoder(encoderTypes[i%len(encoderTypes)]).
+			EnableColors(enable).
+			ShowLogLevel(enable).
+			AddDateTime(enable).
+			SetDateTimeFormat(shared.JP).
+			AddCaller(enable).
+			AddCallerFunc(enable).
+			AddStacktrace(log_level.ErrorLvlName).
+			TrimStacktrace(enable).
+			EscapeJsonHTML(enable)
+
+		switch i % 4 {
+		case 0:
+			logger.SetOutput(&file)
+		case 1:
+			_ = logger.CloseLogFile()
+		case 2:
+			logger.AddDestination(&dest, log_level.InfoLvlName, shared.JsonEncoderType, false)
+			logger.SetAsync(sinks.AsyncConfig{})
+		case 3:
+			logger.ClearDestinations().DisableAsync().DisableStacktrace()
+		}
+	}
+
+	close(stop)
+	loggers.Wait()
+	setters.Wait()
+
+	// Concurrent setters must never overwrite each other's changes
+	assert.Equal(t, 50, logger.configs.Load().callerSkip)
+}
+
+func TestLogger_ConcurrentAsyncToggle(t *testing.T) {
+	var out syncBuffer
+	var wg sync.WaitGroup
+
+	logger := NewLogger().SetStdOutWriter(&out).SetLogLvl(log_level.InfoLvlName)
+	numGoroutines := 8
+	numMessages := 500
+
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < numMessages; j++ {
+				logger.Info("message", j)
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		logger.SetAsync(sinks.AsyncConfig{})
+		logger.DisableAsync()
+	}
+
+	wg.Wait()
+	logger.DisableAsync()
+
+	// The entries logged through a replaced AsyncWriter must never be lost
+	assert.Equal(t, numGoroutines*numMessages, strings.Count(out.String(), "\n"))
+}
+
 func TestLogger_Color(t *testing.T) {
 	var output string
 	testLog := "my testing DEBUG log"
@@ -260,16 +593,20 @@ func TestLogger_ShowLogLevel(t *testing.T) {
 
 func TestLogger_SetEncoder(t *testing.T) {
 	l := NewLogger().SetEncoder(shared.DefaultEncoderType)
-	assert.Equal(t, shared.DefaultEncoderType, l.encoder.GetType())
+	assert.Equal(t, shared.DefaultEncoderType, l.configs.Load().encoder.GetType())
 	assert.Equal(t, shared.DefaultEncoderType, l.GetEncoderType())
 
 	l.SetEncoder(shared.JsonEncoderType)
-	assert.Equal(t, shared.JsonEncoderType, l.encoder.GetType())
+	assert.Equal(t, shared.JsonEncoderType, l.configs.Load().encoder.GetType())
 	assert.Equal(t, shared.JsonEncoderType, l.GetEncoderType())
 
 	l.SetEncoder(shared.YamlEncoderType)
 	assert.Equal(t, shared.YamlEncoderType, l.GetEncoderType())
-	assert.Equal(t, shared.YamlEncoderType, l.encoder.GetType())
+	assert.Equal(t, shared.YamlEncoderType, l.configs.Load().encoder.GetType())
+
+	l.SetEncoder(shared.LogfmtEncoderType)
+	assert.Equal(t, shared.LogfmtEncoderType, l.GetEncoderType())
+	assert.Equal(t, shared.LogfmtEncoderType, l.configs.Load().encoder.GetType())
 }
 
 func TestLogger_CorrectLogsFormattingDefaultEncoder(t *testing.T) {
@@ -343,7 +680,7 @@ func TestLogger_SetLogFile(t *testing.T) {
 	result := logger.SetLogFile(file)
 	assert.NotNil(t, result)
 	assert.IsType(t, &Logger{}, result)
-	assert.NotNil(t, logger.outFile)
+	assert.NotNil(t, logger.configs.Load().outFile)
 	assert.NotNil(t, logger.GetLogFile())
 
 	// Verify the file was created
@@ -390,7 +727,7 @@ func TestLogger_SetLogFile_Nil(t *testing.T) {
 	logger := NewLogger()
 	warnOut := test.CaptureOutput(func() { logger.SetLogFile(nil) })
 	assert.Equal(t, "WARN: the given log file is nil, skipping logs redirection\n", warnOut)
-	assert.Nil(t, logger.outFile)
+	assert.Nil(t, logger.configs.Load().outFile)
 	assert.Nil(t, logger.GetLogFile())
 }
 
@@ -410,7 +747,7 @@ func TestLogger_CloseLogFile(t *testing.T) {
 	// Close the log file
 	logger.CloseLogFile()
 	assert.Nil(t, logger.GetLogFile())
-	assert.Nil(t, logger.outFile)
+	assert.Nil(t, logger.configs.Load().outFile)
 }
 
 func TestLogger_CloseLogFile_NoFileSet(t *testing.T) {
@@ -473,6 +810,249 @@ func TestLogger_LogsRedirectedToFile(t *testing.T) {
 	assert.Contains(t, contentStr, "error message")
 }
 
+func TestLogger_SetOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger().SetOutput(&buf)
+	assert.Equal(t, &buf, logger.GetOutput())
+	assert.Nil(t, logger.GetLogFile())
+
+	stdOut := test.CaptureOutput(func() {
+		logger.Info("info message")
+		logger.Error("error message")
+	})
+	assert.Empty(t, stdOut)
+	assert.Contains(t, buf.String(), "INFO: info message\n")
+	assert.Contains(t, buf.String(), "ERROR: error message\n")
+
+	assert.NoError(t, logger.CloseLogFile())
+	assert.Nil(t, logger.GetOutput())
+}
+
+func TestLogger_SetOutput_Nil(t *testing.T) {
+	logger := NewLogger()
+	warnOut := test.CaptureOutput(func() { logger.SetOutput(nil) })
+	assert.Equal(t, "WARN: the given output writer is nil, skipping logs redirection\n", warnOut)
+	assert.Nil(t, logger.GetOutput())
+}
+
+func TestLogger_SetStdWriters(t *testing.T) {
+	var outBuf, errBuf bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&outBuf).SetStdErrWriter(&errBuf)
+
+	logger.Debug("debug message")
+	logger.Error("error message")
+	assert.Equal(t, "DEBUG: debug message\n", outBuf.String())
+	assert.Equal(t, "ERROR: error message\n", errBuf.String())
+
+	logger.SetStdOutWriter(nil)
+	output := test.CaptureOutput(func() { logger.Info("info message") })
+	assert.Equal(t, "INFO: info message\n", output)
+	assert.Equal(t, "DEBUG: debug message\n", outBuf.String())
+}
+
+func TestLogger_SetOutput_RotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rotating.log")
+	rotatingFile, err := sinks.NewRotatingFile(sinks.RotatingFileConfig{Path: path, MaxSize: 1024})
+	assert.NoError(t, err)
+
+	logger := NewLogger().SetOutput(rotatingFile)
+	logger.Info("rotating file message")
+	assert.NoError(t, logger.CloseLogFile())
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "INFO: rotating file message\n", string(content))
+
+	_, err = rotatingFile.Write([]byte("closed"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestLogger_SetAsync(t *testing.T) {
+	var out, file bytes.Buffer
+	logger := NewLogger().
+		SetStdOutWriter(&out).
+		AddDestination(&file, log_level.DebugLvlName, shared.DefaultEncoderType, false).
+		SetAsync(sinks.AsyncConfig{Capacity: 8})
+	assert.NotNil(t, logger.GetAsyncWriter())
+
+	for i := 0; i < 20; i++ {
+		logger.Info("async message", i)
+	}
+
+	logger.Flush()
+	assert.Equal(t, 20, strings.Count(out.String(), "INFO: async message"))
+	assert.Equal(t, 20, strings.Count(file.String(), "INFO: async message"))
+
+	logger.Debug("last async message")
+	logger.DisableAsync()
+	assert.Nil(t, logger.GetAsyncWriter())
+	assert.Contains(t, out.String(), "DEBUG: last async message\n")
+
+	logger.Debug("sync message")
+	assert.Contains(t, out.String(), "DEBUG: sync message\n")
+}
+
+func TestLogger_SetAsync_StdOutput(t *testing.T) {
+	logger := NewLogger().SetAsync(sinks.AsyncConfig{})
+	defer logger.DisableAsync()
+
+	output := test.CaptureOutput(func() {
+		logger.Info("async stdout message")
+		logger.Flush()
+	})
+	assert.Equal(t, "INFO: async stdout message\n", output)
+
+	output = test.CaptureErrorOutput(func() {
+		logger.Error("async stderr message")
+		logger.Flush()
+	})
+	assert.Equal(t, "ERROR: async stderr message\n", output)
+}
+
+func TestLogger_SetAsync_CloseLogFileFlushes(t *testing.T) {
+	testFileName := "async_test_log_file.txt"
+	file := createMockOutFile(testFileName)
+	defer os.Remove(testFileName)
+
+	logger := NewLogger().SetAsync(sinks.AsyncConfig{}).SetLogFile(file)
+	defer logger.DisableAsync()
+
+	logger.Warn("async file message")
+	assert.NoError(t, logger.CloseLogFile())
+
+	content, err := os.ReadFile(testFileName)
+	assert.NoError(t, err)
+	assert.Equal(t, "WARN: async file message\n", string(content))
+}
+
+func TestLogger_With(t *testing.T) {
+	var out, file bytes.Buffer
+	logger := NewLogger().
+		SetStdOutWriter(&out).
+		AddDestination(&file, log_level.DebugLvlName, shared.JsonEncoderType, false)
+
+	child := logger.With("user", "alice")
+	grandChild := child.With("id", 3)
+	assert.Nil(t, logger.GetBoundFields())
+	assert.Equal(t, []any{"user", "alice"}, child.GetBoundFields().KeyVals())
+
+	logger.Info("parent message")
+	child.Info("child message")
+	grandChild.Info("grand child message", "ip", "10.0.0.1")
+
+	assert.Equal(
+		t,
+		"INFO: parent message\nINFO: child message user=alice\nINFO: grand child message user=alice id=3 ip 10.0.0.1\n",
+		out.String(),
+	)
+
+	lines := strings.Split(strings.TrimSpace(file.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, `{"level":"INFO","msg":"parent message"}`, lines[0])
+	assert.Equal(t, `{"level":"INFO","msg":"child message","extras":{"user":"alice"}}`, lines[1])
+	assert.Equal(
+		t,
+		`{"level":"INFO","msg":"grand child message","extras":{"user":"alice","id":3,"ip":"10.0.0.1"}}`,
+		lines[2],
+	)
+}
+
+func TestLogger_With_SharedConfiguration(t *testing.T) {
+	var buf, other bytes.Buffer
+	logger := NewLogger().SetOutput(&buf)
+	child := logger.With("user", "alice")
+	grandChild := child.Named("payments")
+
+	// The setters called on a child are skipped with a warning, leaving the parent and the siblings untouched
+	child.SetLogLvl(log_level.ErrorLvlName).SetEncoder(shared.JsonEncoderType)
+	child.AddDestination(&bytes.Buffer{}, log_level.DebugLvlName, shared.DefaultEncoderType, false)
+	assert.Equal(t, log_level.DebugLvlName, logger.GetLogLvlName())
+	assert.Equal(t, shared.DefaultEncoderType, logger.GetEncoderType())
+	assert.Empty(t, logger.GetDestinations())
+	warning := "WARN: the configuration is owned by the parent Logger, skipping configuration change user=alice\n"
+	assert.Equal(t, strings.Repeat(warning, 3), buf.String())
+
+	buf.Reset()
+	logger.SetLogLvl(log_level.InfoLvlName).SetEncoder(shared.JsonEncoderType)
+	assert.Equal(t, log_level.InfoLvlName, child.GetLogLvlName())
+	assert.Equal(t, log_level.InfoLvlName, grandChild.GetLogLvlName())
+	assert.Equal(t, shared.JsonEncoderType, child.GetEncoderType())
+
+	child.Debug("child debug")
+	child.Info("child info")
+	logger.SetOutput(&other).SetLvlOverrides("payments=error")
+	grandChild.Info("grand child info")
+	grandChild.Error("grand child error")
+
+	assert.Equal(t, "{\"level\":\"INFO\",\"msg\":\"child info\",\"extras\":{\"user\":\"alice\"}}\n", buf.String())
+	assert.Equal(
+		t,
+		"{\"level\":\"ERROR\",\"msg\":\"grand child error\",\"extras\":{\"user\":\"alice\"}}\n",
+		other.String(),
+	)
+	assert.Nil(t, logger.GetBoundFields())
+	assert.Empty(t, logger.GetName())
+}
+
+func TestLogger_With_CloseLogFile(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "child-*.log")
+	assert.NoError(t, err)
+
+	var stdout bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&stdout).SetLogFile(file)
+	child := logger.With("user", "alice")
+
+	assert.NoError(t, child.CloseLogFile())
+	assert.Same(t, file, logger.GetLogFile())
+
+	child.Info("before close")
+	assert.NoError(t, logger.CloseLogFile())
+	assert.Nil(t, child.GetLogFile())
+
+	stdout.Reset()
+	child.Info("after close")
+	assert.Equal(t, "INFO: after close user=alice\n", stdout.String())
+
+	content, err := os.ReadFile(file.Name())
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"WARN: the log file is owned by the parent Logger, skipping close user=alice\nINFO: before close user=alice\n",
+		string(content),
+	)
+}
+
+func TestLogger_With_AsyncOwnedByParent(t *testing.T) {
+	var out syncBuffer
+	logger := NewLogger().SetOutput(&out).SetAsync(sinks.AsyncConfig{})
+	asyncWriter := logger.GetAsyncWriter()
+	child := logger.With("user", "alice")
+
+	assert.Same(t, asyncWriter, child.GetAsyncWriter())
+	child.DisableAsync().SetAsync(sinks.AsyncConfig{Capacity: 16})
+	assert.Same(t, asyncWriter, logger.GetAsyncWriter())
+
+	child.Info("still async")
+	child.Flush()
+	assert.Contains(t, out.String(), "INFO: still async user=alice\n")
+
+	logger.DisableAsync()
+	assert.Nil(t, child.GetAsyncWriter())
+}
+
+func TestLogger_With_CallerSkip(t *testing.T) {
+	logger := NewLogger().AddCallerSkip(1)
+	child := logger.With().AddCallerSkip(2)
+	grandChild := child.With("user", "alice")
+
+	assert.Equal(t, 1, logger.loadConfigs().callerSkip)
+	assert.Equal(t, 3, child.loadConfigs().callerSkip)
+	assert.Equal(t, 3, grandChild.loadConfigs().callerSkip)
+
+	logger.AddCallerSkip(1)
+	assert.Equal(t, 4, grandChild.loadConfigs().callerSkip)
+}
+
 func TestLogger_NestedStructParameterCorrectLogging(t *testing.T) {
 	type Address struct {
 		Street string
@@ -529,3 +1109,98 @@ func createMockOutFile(fileName string) *os.File {
 
 	return file
 }
+
+func TestLogger_EscapeJsonHTML(t *testing.T) {
+	var out, file bytes.Buffer
+	logger := NewLogger().
+		SetEncoder(shared.JsonEncoderType).
+		SetStdOutWriter(&out).
+		AddDestination(&file, log_level.DebugLvlName, shared.JsonEncoderType, false).
+		EscapeJsonHTML(true)
+
+	logger.Info("<b>bold</b>", "q", "a&b")
+	assert.Equal(t, `{"level":"INFO","msg":"\u003cb\u003ebold\u003c/b\u003e","extras":{"q":"a\u0026b"}}`+"\n", out.String())
+	assert.Equal(t, out.String(), file.String())
+
+	out.Reset()
+	logger.SetEncoder(shared.JsonEncoderType).Info("a \"quoted\"\nmessage")
+	assert.Equal(t, `{"level":"INFO","msg":"a \"quoted\"\nmessage"}`+"\n", out.String())
+}
+
+func TestLogger_EscapeJsonHTML_BoundFields(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetEncoder(shared.JsonEncoderType).SetStdOutWriter(&out)
+	child := logger.With("q", "a&b")
+
+	child.Info("msg")
+	logger.EscapeJsonHTML(true)
+	child.Info("msg")
+
+	assert.Equal(
+		t,
+		`{"level":"INFO","msg":"msg","extras":{"q":"a&b"}}`+"\n"+`{"level":"INFO","msg":"msg","extras":{"q":"a\u0026b"}}`+"\n",
+		out.String(),
+	)
+}
+
+// upperEncoder is a custom encoder writing the level and the message in uppercase.
+type upperEncoder struct {
+	*encoders.BaseEncoder
+}
+
+func (u *upperEncoder) Log(logger shared.LoggerConfigsInterface, lvl log_level.LogLvlName, outType shared.OutputType, args ...any) {
+	buf := u.GetBuffer()
+	buf.WriteString(lvl.String() + " " + strings.ToUpper(u.CastToString(args[0])) + "\n")
+	u.PrintLog(logger, outType, buf)
+	u.PutBuffer(buf)
+}
+
+func (u *upperEncoder) Color(logger shared.LoggerConfigsInterface, _ colors.Color, args ...any) {
+	u.Log(logger, log_level.InfoLvlName, shared.StdOutput, args...)
+}
+
+func TestLogger_RegisteredEncoder(t *testing.T) {
+	upperType := shared.EncoderType("upper")
+	assert.NoError(t, encoders.Register(upperType, func() shared.EncoderInterface {
+		return &upperEncoder{BaseEncoder: encoders.NewBaseEncoder(upperType)}
+	}))
+	defer encoders.Unregister(upperType)
+
+	var out, file bytes.Buffer
+	logger := NewLogger().
+		SetStdOutWriter(&out).
+		SetEncoder(upperType).
+		AddDestination(&file, log_level.DebugLvlName, upperType, false)
+
+	logger.Info("custom format")
+	assert.Equal(t, upperType, logger.GetEncoderType())
+	assert.Equal(t, "INFO CUSTOM FORMAT\n", out.String())
+	assert.Equal(t, out.String(), file.String())
+
+	logger.SetEncoder("unknown")
+	assert.Equal(t, upperType, logger.GetEncoderType())
+}
+
+func TestLogger_CustomEncoderInstance(t *testing.T) {
+	var out, file bytes.Buffer
+	encoder := &upperEncoder{BaseEncoder: encoders.NewBaseEncoder("upper")}
+	logger := NewLogger().
+		SetStdOutWriter(&out).
+		SetCustomEncoder(encoder).
+		AddDestinationWithEncoder(&file, log_level.WarnLvlName, encoder, false)
+
+	logger.Info("info message")
+	logger.Warn("warn message")
+	assert.Equal(t, "INFO INFO MESSAGE\nWARN WARN MESSAGE\n", out.String())
+	assert.Equal(t, "WARN WARN MESSAGE\n", file.String())
+
+	out.Reset()
+	logger.SetCustomEncoder(nil).AddDestinationWithEncoder(&file, log_level.DebugLvlName, nil, false)
+	assert.Equal(
+		t,
+		"WARN THE GIVEN ENCODER IS NIL, SKIPPING ENCODER CHANGE\n"+
+			"WARN THE GIVEN DESTINATION ENCODER IS NIL, SKIPPING DESTINATION\n",
+		out.String(),
+	)
+	assert.Len(t, logger.GetDestinations(), 1)
+}
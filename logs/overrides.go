@@ -0,0 +1,123 @@
+package logs
+
+import (
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+)
+
+// resolvedLvl is the override level resolved for a call site, if any override matches it.
+type resolvedLvl struct {
+	lvl   int8
+	found bool
+}
+
+// lvlOverrides holds the log level overrides of a Logger, caching the level resolved for each call site,
+// so that the packages are matched against the overrides patterns only once per call site.
+type lvlOverrides struct {
+	spec      string
+	overrides []ll.LvlOverride
+	maxLvl    int8
+	callSites atomic.Pointer[map[uintptr]resolvedLvl]
+}
+
+// lvlAt returns the override level of the package of the code at the given program counter,
+// or false if no override matches it.
+func (o *lvlOverrides) lvlAt(pc uintptr) (int8, bool) {
+	cache := o.callSites.Load()
+	if cache != nil {
+		if resolved, found := (*cache)[pc]; found {
+			return resolved.lvl, resolved.found
+		}
+	}
+
+	var resolved resolvedLvl
+	resolved.lvl, resolved.found = ll.MatchLvlOverrides(o.overrides, callerPackage(pc))
+
+	for {
+		newCache := make(map[uintptr]resolvedLvl, 8)
+		if cache != nil {
+			for k, v := range *cache {
+				newCache[k] = v
+			}
+		}
+
+		newCache[pc] = resolved
+		if o.callSites.CompareAndSwap(cache, &newCache) {
+			return resolved.lvl, resolved.found
+		}
+
+		cache = o.callSites.Load()
+	}
+}
+
+// callerPackage returns the import path of the package of the function at the given program counter,
+// or an empty string if it cannot be resolved.
+func callerPackage(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	function := frame.Function
+
+	// The package path ends at the first dot after the last slash: "github.com/acme/app/db.(*Conn).Query"
+	lastSlash := strings.LastIndexByte(function, '/')
+	if dot := strings.IndexByte(function[lastSlash+1:], '.'); dot >= 0 {
+		function = function[:lastSlash+1+dot]
+	}
+
+	// The dots of the last path element are escaped in the symbol names: "gopkg.in/yaml%2ev3"
+	return strings.ReplaceAll(function, "%2e", ".")
+}
+
+// newLvlOverrides parses the given overrides spec, returning nil if it holds no override.
+func newLvlOverrides(spec string) (*lvlOverrides, error) {
+	overrides, err := ll.ParseLvlOverrides(spec)
+	if err != nil || len(overrides) == 0 {
+		return nil, err
+	}
+
+	o := &lvlOverrides{spec: spec, overrides: overrides, maxLvl: overrides[0].Lvl}
+	for _, override := range overrides[1:] {
+		o.maxLvl = max(o.maxLvl, override.Lvl)
+	}
+
+	return o, nil
+}
+
+// loggerLvlAt returns the log level applying to the entries logged by the code at the given program counter:
+// the level of the first override matching the Logger name or, failing that, the code package.
+// Without matching overrides, the Logger log level is returned.
+func (c *loggerConfigs) loggerLvlAt(pc uintptr) int8 {
+	if c.lvlOverrides == nil {
+		return c.logLvl.Lvl
+	}
+
+	if c.namedLvl.found {
+		return c.namedLvl.lvl
+	}
+
+	if lvl, found := c.lvlOverrides.lvlAt(pc); found {
+		return lvl
+	}
+
+	return c.logLvl.Lvl
+}
+
+// needsCallSite returns true if the log level depends on the package of the code calling the logging methods.
+func (c *loggerConfigs) needsCallSite() bool {
+	return c.lvlOverrides != nil && !c.namedLvl.found
+}
+
+// resolveNamedLvl resolves the override level matching the Logger name, if any.
+// It must be called whenever the name or the overrides change.
+func (c *loggerConfigs) resolveNamedLvl() {
+	c.namedLvl = resolvedLvl{}
+
+	if c.lvlOverrides != nil && c.name != "" {
+		c.namedLvl.lvl, c.namedLvl.found = ll.MatchLvlOverrides(c.lvlOverrides.overrides, c.name)
+	}
+}
@@ -0,0 +1,144 @@
+package logs
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	s "github.com/Pho3b/tiny-logger/shared"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+const overridesTestPkg = "github.com/Pho3b/tiny-logger/logs"
+
+func TestLogger_SetLvlOverrides(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).SetLogLvl(ll.WarnLvlName)
+
+	logger.SetLvlOverrides("payments/*=error,tiny-logger/logs=debug")
+	assert.Equal(t, "payments/*=error,tiny-logger/logs=debug", logger.GetLvlOverrides())
+	logger.Debug("debug message")
+	logger.Trace("trace message")
+	assert.Equal(t, "DEBUG: debug message\n", out.String())
+
+	out.Reset()
+	logger.SetLvlOverrides("logs=error")
+	logger.Warn("warn message")
+	assert.Empty(t, out.String())
+
+	out.Reset()
+	logger.SetLvlOverrides("")
+	assert.Equal(t, "", logger.GetLvlOverrides())
+	logger.Warn("warn message")
+	assert.Equal(t, "WARN: warn message\n", out.String())
+}
+
+func TestLogger_SetLvlOverrides_Invalid(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).SetLvlOverrides("db=warn")
+
+	logger.SetLvlOverrides("db=verbose")
+	assert.Contains(t, out.String(), "WARN: the given log level overrides are invalid, skipping overrides change:")
+	assert.Equal(t, "db=warn", logger.GetLvlOverrides())
+}
+
+func TestLogger_SetLvlOverrides_CallSiteCache(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).SetLogLvl(ll.ErrorLvlName).SetLvlOverrides("logs=info")
+
+	for i := 0; i < 3; i++ {
+		logger.Info("cached message")
+	}
+
+	assert.Equal(t, "INFO: cached message\nINFO: cached message\nINFO: cached message\n", out.String())
+	assert.Len(t, *logger.configs.Load().lvlOverrides.callSites.Load(), 1)
+
+	// Child loggers share the call sites cache of their parent
+	logger.With("id", 1).Info("child message")
+	assert.Len(t, *logger.configs.Load().lvlOverrides.callSites.Load(), 2)
+}
+
+func TestLogger_SetLvlOverrides_Named(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).SetLogLvl(ll.ErrorLvlName).SetLvlOverrides("payments/*=debug")
+
+	stripe := logger.Named("payments").Named("stripe")
+	assert.Equal(t, "payments/stripe", stripe.GetName())
+	assert.Equal(t, "payments", logger.Named("/payments/").GetName())
+
+	stripe.Debug("stripe message")
+	logger.Named("payments").Debug("payments message")
+	logger.Named("db").Debug("db message")
+	assert.Equal(t, "DEBUG: stripe message\n", out.String())
+
+	// The overrides are matched against the Logger name first
+	out.Reset()
+	logger.SetLvlOverrides("logs=info,payments/stripe=warn")
+	stripe.Info("info message")
+	stripe.Warn("warn message")
+	assert.Equal(t, "WARN: warn message\n", out.String())
+}
+
+func TestLogger_SetLvlOverrides_Destinations(t *testing.T) {
+	var out, dest bytes.Buffer
+	logger := NewLogger().
+		SetStdOutWriter(&out).
+		SetLogLvl(ll.InfoLvlName).
+		AddDestination(&dest, ll.WarnLvlName, s.DefaultEncoderType, false).
+		SetLvlOverrides("logs=error")
+
+	logger.Info("info message")
+	logger.Warn("warn message")
+	assert.Empty(t, out.String())
+	assert.Equal(t, "WARN: warn message\n", dest.String())
+}
+
+func TestLogger_SetLvlOverridesEnvVariable(t *testing.T) {
+	var out bytes.Buffer
+
+	t.Setenv(ll.DefaultEnvLvlOverridesVar, "logs=trace")
+	logger := NewLogger().SetStdOutWriter(&out).SetLogLvl(ll.ErrorLvlName)
+	assert.Equal(t, "logs=trace", logger.GetLvlOverrides())
+
+	logger.Trace("trace message")
+	assert.Equal(t, "TRACE: trace message\n", out.String())
+
+	t.Setenv("MY_LVL_OVERRIDES", "db=warn")
+	assert.Equal(t, "db=warn", logger.SetLvlOverridesEnvVariable("MY_LVL_OVERRIDES").GetLvlOverrides())
+	assert.Equal(t, "", logger.SetLvlOverridesEnvVariable("MISSING_LVL_OVERRIDES").GetLvlOverrides())
+}
+
+func TestSlogHandler_LvlOverrides(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).SetLogLvl(ll.ErrorLvlName).SetLvlOverrides("logs=debug")
+	slogger := slog.New(NewSlogHandler(logger))
+
+	slogger.Debug("slog message")
+	assert.Equal(t, "DEBUG: slog message\n", out.String())
+
+	out.Reset()
+	logger.SetLvlOverrides("logs=error")
+	slogger.Info("slog message")
+	assert.Empty(t, out.String())
+}
+
+func TestCallerPackage(t *testing.T) {
+	pc, _, _, _ := runtime.Caller(0)
+	assert.Equal(t, overridesTestPkg, callerPackage(pc))
+
+	method := (*Logger).Info
+	assert.Equal(t, overridesTestPkg, callerPackage(funcPC(method)))
+	assert.Equal(t, "gopkg.in/yaml.v3", callerPackage(funcPC(yaml.Marshal)))
+	assert.Equal(t, "os", callerPackage(funcPC(os.Getenv)))
+	assert.Equal(t, "", callerPackage(0))
+}
+
+// funcPC returns a program counter inside the given function.
+func funcPC(function any) uintptr {
+	return reflect.ValueOf(function).Pointer() + 1
+}
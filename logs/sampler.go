@@ -0,0 +1,302 @@
+package logs
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+const (
+	// samplerCountersNum is the number of counters the messages are hashed into. It must be a power of two.
+	samplerCountersNum = 4096
+	// defaultSamplingInterval is the interval used when SamplerConfig.Interval is not set.
+	defaultSamplingInterval = time.Second
+	// samplingReportMsg is the message of the summary entries reporting the dropped entries.
+	samplingReportMsg = "tiny-logger: log entries dropped by the sampler"
+)
+
+// SamplingRule defines how the entries with the same level and message are sampled within each interval:
+// the First ones are logged, then only every Thereafter-th one. A zero Thereafter drops all the entries past the
+// First ones, while a negative First or a zero rule, as the one of the levels not configured, disables the sampling.
+type SamplingRule struct {
+	First      int
+	Thereafter int
+}
+
+// SamplerConfig holds the configuration of a Sampler.
+type SamplerConfig struct {
+	// Interval is the period over which the entries are counted. Defaults to 1 second when not set.
+	Interval time.Duration
+	// SamplingRule applies to all the levels not configured through Levels.
+	SamplingRule
+	// Levels overrides the SamplingRule of specific levels.
+	Levels map[ll.LogLvlName]SamplingRule
+	// ReportInterval, if set, enables the summary entries logged every ReportInterval, reporting the number of
+	// entries dropped since the previous summary for each level, through the Logger that dropped the last one.
+	ReportInterval time.Duration
+}
+
+// samplingCounter counts the entries logged within the current interval.
+type samplingCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+// incr increments the counter, restarting it from 1 if the interval ending at resetAt has expired.
+func (sc *samplingCounter) incr(now int64, interval time.Duration) uint64 {
+	resetAt := sc.resetAt.Load()
+	if now > resetAt && sc.resetAt.CompareAndSwap(resetAt, now+int64(interval)) {
+		sc.count.Store(1)
+		return 1
+	}
+
+	return sc.count.Add(1)
+}
+
+// Sampler caps the volume of the entries logged by a Logger, counting the entries by level and message.
+// The messages are hashed into a fixed number of lock-free counters, so messages sharing a counter are sampled
+// together and the counts may be slightly approximate when an interval restarts under concurrent calls.
+// The entries logged at PANIC and FATAL_ERROR levels are never sampled.
+// A Sampler is safe for concurrent use and shared by the child loggers created through With.
+// If the summaries are enabled, Close must be called to report the last dropped entries and to stop its
+// background goroutine.
+type Sampler struct {
+	interval       time.Duration
+	rule           SamplingRule
+	lvlRules       map[int8]SamplingRule
+	reportInterval time.Duration
+	nextReport     atomic.Int64
+	counters       [samplerCountersNum]samplingCounter
+	// dropped and pending count the dropped entries by level: in total and since the previous summary
+	dropped [math.MaxUint8 + 1]atomic.Uint64
+	pending [math.MaxUint8 + 1]atomic.Uint64
+	// reporter is the Logger the summaries are logged through, the one that dropped the last entry
+	reporter  atomic.Pointer[Logger]
+	doneCh    chan struct{}
+	closeOnce sync.Once
+	loopWg    sync.WaitGroup
+	now       func() time.Time
+}
+
+// Dropped returns the total number of entries dropped by the Sampler for each level.
+func (sp *Sampler) Dropped() map[ll.LogLvlName]uint64 {
+	dropped := map[ll.LogLvlName]uint64{}
+
+	for i := range sp.dropped {
+		if n := sp.dropped[i].Load(); n > 0 {
+			dropped[ll.LogLvlIntToName[int8(i)]] = n
+		}
+	}
+
+	return dropped
+}
+
+// Close reports the entries dropped since the previous summary, if the summaries are enabled,
+// and stops the background goroutine.
+func (sp *Sampler) Close() error {
+	sp.closeOnce.Do(func() {
+		close(sp.doneCh)
+		sp.loopWg.Wait()
+
+		if sp.reportInterval > 0 {
+			sp.report(true)
+		}
+	})
+
+	return nil
+}
+
+// report logs the summary of the dropped entries through the Logger that dropped the last one, if any.
+func (sp *Sampler) report(force bool) {
+	if l := sp.reporter.Load(); l != nil {
+		l.loadConfigs().reportSampling(sp, force)
+	}
+}
+
+// loop is the background goroutine logging the summaries of the dropped entries every ReportInterval,
+// until the Sampler is closed.
+func (sp *Sampler) loop() {
+	defer sp.loopWg.Done()
+
+	ticker := time.NewTicker(sp.reportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sp.report(false)
+		case <-sp.doneCh:
+			return
+		}
+	}
+}
+
+// sample returns true if the entry of the given level and message must be logged,
+// and whether a summary of the dropped entries is due.
+func (sp *Sampler) sample(lvl int8, msg any) (keep bool, reportDue bool) {
+	if lvl <= ll.FatalErrorLvl {
+		return true, false
+	}
+
+	rule := sp.rule
+	if lvlRule, found := sp.lvlRules[lvl]; found {
+		rule = lvlRule
+	}
+
+	now := sp.now().UnixNano()
+	reportDue = sp.reportInterval > 0 && now >= sp.nextReport.Load()
+
+	if rule.First < 0 || rule == (SamplingRule{}) {
+		return true, reportDue
+	}
+
+	n := sp.counters[samplingHash(lvl, messageString(msg))&(samplerCountersNum-1)].incr(now, sp.interval)
+	if n <= uint64(rule.First) || (rule.Thereafter > 0 && (n-uint64(rule.First))%uint64(rule.Thereafter) == 0) {
+		return true, reportDue
+	}
+
+	sp.dropped[uint8(lvl)].Add(1)
+	sp.pending[uint8(lvl)].Add(1)
+
+	return false, reportDue
+}
+
+// reportKeyVals returns the key/value pairs of the summary entry, resetting the counts of the entries dropped
+// since the previous summary. It returns nil if no entry has been dropped or if another goroutine is reporting.
+func (sp *Sampler) reportKeyVals(force bool) []any {
+	nextReport := sp.nextReport.Load()
+	now := sp.now().UnixNano()
+
+	if !force && now < nextReport {
+		return nil
+	}
+
+	if !sp.nextReport.CompareAndSwap(nextReport, now+int64(sp.reportInterval)) {
+		return nil
+	}
+
+	var total uint64
+	var keyVals []any
+
+	for i := range sp.pending {
+		if n := sp.pending[i].Swap(0); n > 0 {
+			total += n
+			keyVals = append(keyVals, strings.ToLower(ll.LogLvlIntToName[int8(i)].String()), n)
+		}
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	return append([]any{samplingReportMsg, "dropped", total}, keyVals...)
+}
+
+// messageString returns the given message as a string, without formatting it if it is a string, an error or
+// a fmt.Stringer.
+func messageString(msg any) string {
+	switch v := msg.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// samplingHash returns the FNV-1a hash of the given level and message.
+func samplingHash(lvl int8, msg string) uint32 {
+	const prime32 = 16777619
+
+	hash := uint32(2166136261)
+	hash = (hash ^ uint32(uint8(lvl))) * prime32
+
+	for i := 0; i < len(msg); i++ {
+		hash = (hash ^ uint32(msg[i])) * prime32
+	}
+
+	return hash
+}
+
+// SetSampler attaches the given Sampler to the Logger, or detaches the current one if nil.
+// The Sampler applies to the Logger destinations too, and it is shared by the child loggers created through With.
+func (l *Logger) SetSampler(sampler *Sampler) *Logger {
+	return l.update(func(c *loggerConfigs) {
+		c.sampler = sampler
+	})
+}
+
+// GetSampler returns the Sampler attached to the Logger, or nil if there is none.
+func (l *Logger) GetSampler() *Sampler {
+	return l.loadConfigs().sampler
+}
+
+// ReportSampling logs the summary of the entries dropped by the Sampler since the previous summary, if any,
+// regardless of the SamplerConfig.ReportInterval. It is useful to report the last drops before shutting down.
+func (l *Logger) ReportSampling() {
+	if c := l.loadConfigs(); c.sampler != nil {
+		c.reportSampling(c.sampler, true)
+	}
+}
+
+// isSampledOut returns true if the Sampler drops the entry of the given level and message logged through
+// the given Logger, logging the summary of the dropped entries first if it is due.
+func (c *loggerConfigs) isSampledOut(l *Logger, lvl int8, msg any) bool {
+	keep, reportDue := c.sampler.sample(lvl, msg)
+	if reportDue {
+		c.reportSampling(c.sampler, false)
+	}
+
+	if !keep && c.sampler.reporter.Load() != l {
+		c.sampler.reporter.Store(l)
+	}
+
+	return !keep
+}
+
+// reportSampling logs the summary of the entries dropped by the given Sampler as a WARN entry,
+// through the Logger encoder and the destinations allowing it.
+func (c *loggerConfigs) reportSampling(sampler *Sampler, force bool) {
+	if keyVals := sampler.reportKeyVals(force); keyVals != nil && c.isLvlEnabled(ll.WarnLvl) {
+		c.logWith(c.logLvl.Lvl, 0, "", time.Time{}, ll.WarnLvl, ll.WarnLvlName, s.StdOutput, keyVals...)
+	}
+}
+
+// NewSampler creates and returns a new Sampler with the given configuration,
+// starting the background goroutine that logs the summaries of the dropped entries if they are enabled.
+func NewSampler(config SamplerConfig) *Sampler {
+	sampler := &Sampler{
+		interval:       config.Interval,
+		rule:           config.SamplingRule,
+		lvlRules:       make(map[int8]SamplingRule, len(config.Levels)),
+		reportInterval: config.ReportInterval,
+		doneCh:         make(chan struct{}),
+		now:            time.Now,
+	}
+
+	if sampler.interval <= 0 {
+		sampler.interval = defaultSamplingInterval
+	}
+
+	for lvlName, rule := range config.Levels {
+		sampler.lvlRules[ll.RetrieveLogLvlIntFromName(lvlName)] = rule
+	}
+
+	sampler.nextReport.Store(sampler.now().Add(sampler.reportInterval).UnixNano())
+
+	if sampler.reportInterval > 0 {
+		sampler.loopWg.Add(1)
+		go sampler.loop()
+	}
+
+	return sampler
+}
@@ -0,0 +1,236 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	s "github.com/Pho3b/tiny-logger/shared"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a manually advanced clock used in place of time.Now.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+}
+
+func newTestSampler(t *testing.T, config SamplerConfig) (*Sampler, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 3, 11, 18, 35, 43, 0, time.UTC)}
+
+	sampler := NewSampler(config)
+	sampler.now = clock.Now
+	t.Cleanup(func() { _ = sampler.Close() })
+	sampler.nextReport.Store(clock.Now().Add(config.ReportInterval).UnixNano())
+
+	return sampler, clock
+}
+
+func TestLogger_SetSampler(t *testing.T) {
+	var out bytes.Buffer
+	sampler, clock := newTestSampler(t, SamplerConfig{
+		Interval:     time.Second,
+		SamplingRule: SamplingRule{First: 2, Thereafter: 3},
+	})
+	logger := NewLogger().SetStdOutWriter(&out).SetSampler(sampler)
+	assert.Same(t, sampler, logger.GetSampler())
+
+	for i := 1; i <= 10; i++ {
+		logger.Info("hot loop", i)
+	}
+
+	// The first 2 entries, then every 3rd one
+	assert.Equal(t, "INFO: hot loop 1\nINFO: hot loop 2\nINFO: hot loop 5\nINFO: hot loop 8\n", out.String())
+	assert.Equal(t, map[ll.LogLvlName]uint64{ll.InfoLvlName: 6}, sampler.Dropped())
+
+	// Other messages and levels are counted separately
+	out.Reset()
+	logger.Info("other message")
+	logger.Warn("hot loop", 11)
+	assert.Equal(t, "INFO: other message\nWARN: hot loop 11\n", out.String())
+
+	// The counters restart on the next interval
+	out.Reset()
+	clock.Advance(time.Second + time.Nanosecond)
+	logger.Info("hot loop", 12)
+	logger.Info("hot loop", 13)
+	logger.Info("hot loop", 14)
+	assert.Equal(t, "INFO: hot loop 12\nINFO: hot loop 13\n", out.String())
+
+	out.Reset()
+	logger.SetSampler(nil).Info("hot loop", 15)
+	assert.Equal(t, "INFO: hot loop 15\n", out.String())
+}
+
+func TestLogger_SetSampler_Levels(t *testing.T) {
+	var out, errOut bytes.Buffer
+	sampler, _ := newTestSampler(t, SamplerConfig{
+		SamplingRule: SamplingRule{First: 1},
+		Levels: map[ll.LogLvlName]SamplingRule{
+			ll.ErrorLvlName: {First: -1},
+			ll.DebugLvlName: {First: 0, Thereafter: 2},
+		},
+	})
+	logger := NewLogger().SetStdOutWriter(&out).SetStdErrWriter(&errOut).SetSampler(sampler)
+
+	for i := 1; i <= 4; i++ {
+		logger.Debugf("debug %s", "message")
+		logger.Info("info message")
+		logger.Error("error message")
+	}
+
+	assert.Equal(t, "INFO: info message\nDEBUG: debug message\nDEBUG: debug message\n", out.String())
+	assert.Equal(t, strings.Repeat("ERROR: error message\n", 4), errOut.String())
+	assert.Equal(t, map[ll.LogLvlName]uint64{ll.DebugLvlName: 2, ll.InfoLvlName: 3}, sampler.Dropped())
+
+	keep, reportDue := sampler.sample(ll.FatalErrorLvl, "fatal message")
+	assert.True(t, keep)
+	assert.False(t, reportDue)
+}
+
+func TestLogger_SetSampler_Report(t *testing.T) {
+	var out bytes.Buffer
+	sampler, clock := newTestSampler(t, SamplerConfig{
+		SamplingRule:   SamplingRule{First: 1},
+		ReportInterval: 10 * time.Second,
+	})
+	logger := NewLogger().SetStdOutWriter(&out).SetEncoder(s.JsonEncoderType).SetSampler(sampler)
+
+	for i := 0; i < 3; i++ {
+		logger.Info("info message")
+		logger.Debug(errors.New("debug error"))
+	}
+
+	assert.Equal(t, 2, strings.Count(out.String(), "\n"))
+
+	// The summary is logged through the Logger encoder before the first entry after the ReportInterval
+	out.Reset()
+	clock.Advance(10 * time.Second)
+	logger.Info("info message")
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, `{"level":"INFO","msg":"info message"}`, lines[1])
+
+	var summary s.JsonLog
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &summary))
+	assert.Equal(t, "WARN", summary.Level)
+	assert.Equal(t, samplingReportMsg, summary.Message)
+	assert.Equal(t, map[string]any{"dropped": float64(4), "info": float64(2), "debug": float64(2)}, summary.Extras)
+
+	// The dropped entries are reported once, while the total counts are kept
+	out.Reset()
+	logger.ReportSampling()
+	assert.Empty(t, out.String())
+	assert.Equal(t, map[ll.LogLvlName]uint64{ll.DebugLvlName: 2, ll.InfoLvlName: 2}, sampler.Dropped())
+
+	logger.Info("info message")
+	logger.ReportSampling()
+	assert.Equal(t, `{"level":"WARN","msg":"`+samplingReportMsg+`","extras":{"dropped":1,"info":1}}`+"\n", out.String())
+}
+
+func TestLogger_SetSampler_ZeroRule(t *testing.T) {
+	var out, errOut bytes.Buffer
+	sampler, _ := newTestSampler(t, SamplerConfig{
+		Levels: map[ll.LogLvlName]SamplingRule{ll.InfoLvlName: {First: 1}},
+	})
+	logger := NewLogger().SetStdOutWriter(&out).SetStdErrWriter(&errOut).SetSampler(sampler)
+
+	// The levels not configured fall back to the zero rule, which does not sample
+	for i := 0; i < 3; i++ {
+		logger.Info("info message")
+		logger.Warn("warn message")
+		logger.Error("error message")
+	}
+
+	assert.Equal(t, "INFO: info message\n"+strings.Repeat("WARN: warn message\n", 3), out.String())
+	assert.Equal(t, strings.Repeat("ERROR: error message\n", 3), errOut.String())
+	assert.Equal(t, map[ll.LogLvlName]uint64{ll.InfoLvlName: 2}, sampler.Dropped())
+}
+
+func TestSampler_Loop(t *testing.T) {
+	var out syncBuffer
+	sampler := NewSampler(SamplerConfig{SamplingRule: SamplingRule{First: 1}, ReportInterval: 10 * time.Millisecond})
+	logger := NewLogger().SetStdOutWriter(&out).SetSampler(sampler)
+
+	for i := 0; i < 3; i++ {
+		logger.With("id", 1).Info("info message")
+	}
+
+	// The summary is logged by the background goroutine, without any further entry
+	assert.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "WARN: "+samplingReportMsg+" id=1 dropped 2 info 2\n")
+	}, time.Second, 5*time.Millisecond)
+
+	// Close reports the last dropped entries
+	logger.Info("info message")
+	assert.NoError(t, sampler.Close())
+	assert.True(t, strings.HasSuffix(out.String(), "WARN: "+samplingReportMsg+" dropped 1 info 1\n"), out.String())
+	assert.NoError(t, sampler.Close())
+}
+
+func TestSlogHandler_Sampler(t *testing.T) {
+	var out bytes.Buffer
+	sampler, _ := newTestSampler(t, SamplerConfig{SamplingRule: SamplingRule{First: 1}})
+	slogger := slog.New(NewSlogHandler(NewLogger().SetStdOutWriter(&out).SetSampler(sampler)))
+
+	slogger.Info("slog message", "id", 1)
+	slogger.Info("slog message", "id", 2)
+	assert.Equal(t, "INFO: slog message id 1\n", out.String())
+}
+
+func TestSampler_Concurrency(t *testing.T) {
+	var out syncBuffer
+	var wg sync.WaitGroup
+
+	sampler := NewSampler(SamplerConfig{Interval: time.Hour, SamplingRule: SamplingRule{First: 10, Thereafter: 100}})
+	logger := NewLogger().SetStdOutWriter(&out).SetSampler(sampler)
+
+	// The first entry starts the interval, which is not restarted concurrently
+	logger.Info("concurrent message")
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				logger.Info("concurrent message")
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// 10 entries, then every 100th one of the remaining 7991
+	assert.Equal(t, 10+79, strings.Count(out.String(), "\n"))
+	assert.Equal(t, uint64(8001-89), sampler.Dropped()[ll.InfoLvlName])
+}
+
+func TestSamplingMsg(t *testing.T) {
+	assert.Equal(t, "message", messageString("message"))
+	assert.Equal(t, "error", messageString(errors.New("error")))
+	assert.Equal(t, "INFO", messageString(ll.InfoLvlName))
+	assert.Equal(t, "42", messageString(42))
+	assert.NotEqual(t, samplingHash(ll.InfoLvl, "message"), samplingHash(ll.WarnLvl, "message"))
+}
@@ -0,0 +1,267 @@
+package sinks
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Pho3b/tiny-logger/internal/services"
+)
+
+const (
+	defaultAsyncCapacity = 1024
+	blockedWriteTimeout  = time.Millisecond
+)
+
+// OverflowPolicy is the Enum representing what an AsyncWriter does when its buffer is full.
+type OverflowPolicy int8
+
+const (
+	// BlockOnOverflow makes the caller wait until the background writer frees a slot.
+	BlockOnOverflow OverflowPolicy = 0
+	// DropNewestOnOverflow discards the entry that is being written.
+	DropNewestOnOverflow OverflowPolicy = 1
+	// DropOldestOnOverflow discards the oldest pending entry to make room for the new one.
+	DropOldestOnOverflow OverflowPolicy = 2
+)
+
+// AsyncConfig holds the AsyncWriter settings.
+type AsyncConfig struct {
+	// Capacity is the maximum number of pending entries, rounded up to the next power of two.
+	// Defaults to 1024 when not set.
+	Capacity int
+	// OverflowPolicy defines what happens when the pending entries reach the Capacity.
+	OverflowPolicy OverflowPolicy
+}
+
+type asyncEntry struct {
+	target io.Writer
+	buf    *bytes.Buffer
+}
+
+// AsyncWriter moves the writes of any number of wrapped writers to a single background goroutine.
+// Written bytes are copied into pooled buffers and pushed into a bounded lock-free ring buffer,
+// so that callers never wait for the underlying I/O unless BlockOnOverflow is set and the buffer is full.
+//
+// The background goroutine is the only one writing the queued entries: Flush waits for it to signal
+// the progress, and Close lets it drain the entries queued before closing, so that the order is preserved.
+type AsyncWriter struct {
+	config     AsyncConfig
+	ring       *services.RingBuffer[asyncEntry]
+	bufferPool sync.Pool
+	wakeCh     chan struct{}
+	spaceCh    chan struct{}
+	doneCh     chan struct{}
+	stoppedCh  chan struct{}
+	closeOnce  sync.Once
+	closeMu    sync.RWMutex
+	loopWg     sync.WaitGroup
+	flushMu    sync.Mutex
+	flushCond  *sync.Cond
+	closed     atomic.Bool
+	enqueued   atomic.Uint64
+	processed  atomic.Uint64
+	dropped    atomic.Uint64
+}
+
+// asyncTarget is the io.Writer returned by AsyncWriter.Wrap.
+type asyncTarget struct {
+	async  *AsyncWriter
+	target io.Writer
+}
+
+// Write queues the given bytes to be written to the wrapped writer.
+func (t *asyncTarget) Write(p []byte) (int, error) {
+	return t.async.write(t.target, p)
+}
+
+// Wrap returns an io.Writer whose writes are queued and performed on the given writer in the background.
+func (a *AsyncWriter) Wrap(w io.Writer) io.Writer {
+	return &asyncTarget{async: a, target: w}
+}
+
+// Dropped returns the number of entries discarded because of the overflow policy.
+func (a *AsyncWriter) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Pending returns the number of entries waiting to be written.
+func (a *AsyncWriter) Pending() int {
+	return a.ring.Len()
+}
+
+// Flush blocks until every entry queued before the call has been written or dropped.
+func (a *AsyncWriter) Flush() {
+	target := a.enqueued.Load()
+
+	a.flushMu.Lock()
+	defer a.flushMu.Unlock()
+
+	for a.processed.Load() < target {
+		a.wake()
+		a.flushCond.Wait()
+	}
+}
+
+// Close drains the pending entries and stops the background goroutine.
+// Writes performed after Close are executed synchronously, once the pending entries are written.
+func (a *AsyncWriter) Close() error {
+	a.closeOnce.Do(func() {
+		// Once closed is set under the lock, no write can queue new entries
+		a.closeMu.Lock()
+		a.closed.Store(true)
+		a.closeMu.Unlock()
+
+		close(a.doneCh)
+		a.loopWg.Wait()
+	})
+
+	return nil
+}
+
+// write copies the given bytes into a pooled buffer and queues it according to the overflow policy.
+func (a *AsyncWriter) write(target io.Writer, p []byte) (int, error) {
+	a.closeMu.RLock()
+	defer a.closeMu.RUnlock()
+
+	if a.closed.Load() {
+		// The entries queued before Close are written first
+		<-a.stoppedCh
+		return target.Write(p)
+	}
+
+	buf := a.bufferPool.Get().(*bytes.Buffer)
+	buf.Write(p)
+	entry := asyncEntry{target: target, buf: buf}
+
+	for !a.ring.Push(entry) {
+		switch a.config.OverflowPolicy {
+		case DropNewestOnOverflow:
+			a.dropped.Add(1)
+			a.putBuffer(buf)
+			return len(p), nil
+		case DropOldestOnOverflow:
+			if oldest, ok := a.ring.Pop(); ok {
+				a.dropped.Add(1)
+				a.processed.Add(1)
+				a.putBuffer(oldest.buf)
+			}
+		default:
+			a.wake()
+			a.waitForSpace()
+		}
+	}
+
+	a.enqueued.Add(1)
+	a.wake()
+
+	return len(p), nil
+}
+
+// waitForSpace waits until the background goroutine frees some slots, or for a short timeout.
+func (a *AsyncWriter) waitForSpace() {
+	timer := time.NewTimer(blockedWriteTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-a.spaceCh:
+	case <-timer.C:
+		runtime.Gosched()
+	}
+}
+
+// wake notifies the background goroutine that new entries are available without ever blocking.
+func (a *AsyncWriter) wake() {
+	select {
+	case a.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// loop is the background goroutine writing the queued entries until the writer is closed,
+// signalling the waiting Flush calls after every drain.
+func (a *AsyncWriter) loop() {
+	defer a.loopWg.Done()
+	defer close(a.stoppedCh)
+
+	for {
+		a.drain()
+		a.signalFlush()
+
+		select {
+		case <-a.wakeCh:
+		case <-a.doneCh:
+			// No entry can be queued anymore: the last drain writes all the remaining ones
+			a.drain()
+			a.signalFlush()
+
+			return
+		}
+	}
+}
+
+// signalFlush wakes up the Flush calls waiting for the queued entries to be written.
+func (a *AsyncWriter) signalFlush() {
+	a.flushMu.Lock()
+	a.flushCond.Broadcast()
+	a.flushMu.Unlock()
+}
+
+// drain writes all the currently queued entries.
+func (a *AsyncWriter) drain() {
+	for {
+		entry, ok := a.ring.Pop()
+		if !ok {
+			return
+		}
+
+		if _, err := entry.target.Write(entry.buf.Bytes()); err != nil {
+			_, _ = os.Stderr.Write([]byte("tiny-logger-err: " + err.Error() + "\n"))
+		}
+
+		a.putBuffer(entry.buf)
+		a.processed.Add(1)
+
+		select {
+		case a.spaceCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// putBuffer puts the given bytes buffer back to the pool.
+func (a *AsyncWriter) putBuffer(buf *bytes.Buffer) {
+	buf.Reset()
+	a.bufferPool.Put(buf)
+}
+
+// NewAsyncWriter initializes a new AsyncWriter and starts its background goroutine.
+func NewAsyncWriter(config AsyncConfig) *AsyncWriter {
+	if config.Capacity <= 0 {
+		config.Capacity = defaultAsyncCapacity
+	}
+
+	asyncWriter := &AsyncWriter{
+		config:    config,
+		ring:      services.NewRingBuffer[asyncEntry](config.Capacity),
+		wakeCh:    make(chan struct{}, 1),
+		spaceCh:   make(chan struct{}, 1),
+		doneCh:    make(chan struct{}),
+		stoppedCh: make(chan struct{}),
+	}
+	asyncWriter.flushCond = sync.NewCond(&asyncWriter.flushMu)
+	asyncWriter.bufferPool = sync.Pool{
+		New: func() any {
+			return new(bytes.Buffer)
+		},
+	}
+
+	asyncWriter.loopWg.Add(1)
+	go asyncWriter.loop()
+
+	return asyncWriter
+}
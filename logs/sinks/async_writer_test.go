@@ -0,0 +1,211 @@
+package sinks
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gatedWriter is a thread-safe writer whose writes block until the gate is opened.
+type gatedWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	gate    chan struct{}
+	entered chan struct{}
+}
+
+func (g *gatedWriter) Write(p []byte) (int, error) {
+	select {
+	case g.entered <- struct{}{}:
+	default:
+	}
+
+	<-g.gate
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.buf.Write(p)
+}
+
+func (g *gatedWriter) String() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.buf.String()
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{gate: make(chan struct{}), entered: make(chan struct{}, 1)}
+}
+
+func TestAsyncWriter_WriteAndFlush(t *testing.T) {
+	var out bytes.Buffer
+	asyncWriter := NewAsyncWriter(AsyncConfig{})
+	defer asyncWriter.Close()
+
+	w := asyncWriter.Wrap(&out)
+	for i := 0; i < 100; i++ {
+		n, err := w.Write([]byte(strconv.Itoa(i) + "\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, len(strconv.Itoa(i))+1, n)
+	}
+
+	asyncWriter.Flush()
+	assert.Equal(t, 0, asyncWriter.Pending())
+	assert.Equal(t, uint64(0), asyncWriter.Dropped())
+
+	var expected bytes.Buffer
+	for i := 0; i < 100; i++ {
+		expected.WriteString(strconv.Itoa(i) + "\n")
+	}
+
+	assert.Equal(t, expected.String(), out.String())
+}
+
+func TestAsyncWriter_WrittenBytesAreCopied(t *testing.T) {
+	var out bytes.Buffer
+	asyncWriter := NewAsyncWriter(AsyncConfig{})
+	defer asyncWriter.Close()
+
+	msg := []byte("original")
+	_, _ = asyncWriter.Wrap(&out).Write(msg)
+	copy(msg, "modified")
+
+	asyncWriter.Flush()
+	assert.Equal(t, "original", out.String())
+}
+
+func TestAsyncWriter_DropNewestOnOverflow(t *testing.T) {
+	target := newGatedWriter()
+	asyncWriter := NewAsyncWriter(AsyncConfig{Capacity: 2, OverflowPolicy: DropNewestOnOverflow})
+	w := asyncWriter.Wrap(target)
+
+	_, _ = w.Write([]byte("0"))
+	<-target.entered
+
+	for i := 1; i <= 4; i++ {
+		_, _ = w.Write([]byte(strconv.Itoa(i)))
+	}
+
+	assert.Equal(t, uint64(2), asyncWriter.Dropped())
+	close(target.gate)
+	assert.NoError(t, asyncWriter.Close())
+	assert.Equal(t, "012", target.String())
+}
+
+func TestAsyncWriter_DropOldestOnOverflow(t *testing.T) {
+	target := newGatedWriter()
+	asyncWriter := NewAsyncWriter(AsyncConfig{Capacity: 2, OverflowPolicy: DropOldestOnOverflow})
+	w := asyncWriter.Wrap(target)
+
+	_, _ = w.Write([]byte("0"))
+	<-target.entered
+
+	for i := 1; i <= 4; i++ {
+		_, _ = w.Write([]byte(strconv.Itoa(i)))
+	}
+
+	assert.Equal(t, uint64(2), asyncWriter.Dropped())
+	close(target.gate)
+	assert.NoError(t, asyncWriter.Close())
+	assert.Equal(t, "034", target.String())
+}
+
+func TestAsyncWriter_BlockOnOverflow(t *testing.T) {
+	target := newGatedWriter()
+	asyncWriter := NewAsyncWriter(AsyncConfig{Capacity: 2, OverflowPolicy: BlockOnOverflow})
+	w := asyncWriter.Wrap(target)
+
+	_, _ = w.Write([]byte("0"))
+	<-target.entered
+	_, _ = w.Write([]byte("1"))
+	_, _ = w.Write([]byte("2"))
+
+	unblocked := make(chan struct{})
+	go func() {
+		_, _ = w.Write([]byte("3"))
+		close(unblocked)
+	}()
+
+	select {
+	case <-unblocked:
+		t.Fatal("write should block while the buffer is full")
+	default:
+	}
+
+	close(target.gate)
+	<-unblocked
+	assert.NoError(t, asyncWriter.Close())
+	assert.Equal(t, uint64(0), asyncWriter.Dropped())
+	assert.Equal(t, "0123", target.String())
+}
+
+func TestAsyncWriter_WriteAfterClose(t *testing.T) {
+	var out bytes.Buffer
+	asyncWriter := NewAsyncWriter(AsyncConfig{})
+	w := asyncWriter.Wrap(&out)
+
+	_, _ = w.Write([]byte("before "))
+	assert.NoError(t, asyncWriter.Close())
+	assert.NoError(t, asyncWriter.Close())
+	assert.Equal(t, "before ", out.String())
+
+	_, _ = w.Write([]byte("after"))
+	assert.Equal(t, "before after", out.String())
+}
+
+func TestAsyncWriter_WriteDuringClose(t *testing.T) {
+	target := newGatedWriter()
+	close(target.gate)
+	asyncWriter := NewAsyncWriter(AsyncConfig{Capacity: 16})
+	w := asyncWriter.Wrap(target)
+	written := make(chan struct{})
+
+	var expected strings.Builder
+	for i := 0; i < 2000; i++ {
+		expected.WriteString(strconv.Itoa(i) + "\n")
+	}
+
+	go func() {
+		defer close(written)
+
+		for i := 0; i < 2000; i++ {
+			_, _ = w.Write([]byte(strconv.Itoa(i) + "\n"))
+		}
+	}()
+
+	// The entries written while closing are never reordered, whether they are queued or written synchronously
+	<-target.entered
+	assert.NoError(t, asyncWriter.Close())
+	<-written
+	asyncWriter.Flush()
+	assert.Equal(t, expected.String(), target.String())
+}
+
+func TestAsyncWriter_Concurrency(t *testing.T) {
+	var wg sync.WaitGroup
+	target := newGatedWriter()
+	close(target.gate)
+	asyncWriter := NewAsyncWriter(AsyncConfig{Capacity: 16})
+	w := asyncWriter.Wrap(target)
+
+	for g := 0; g < 10; g++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				_, _ = w.Write([]byte("x"))
+			}
+		}()
+	}
+
+	wg.Wait()
+	assert.NoError(t, asyncWriter.Close())
+	assert.Len(t, target.String(), 1000)
+}
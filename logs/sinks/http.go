@@ -0,0 +1,463 @@
+package sinks
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Pho3b/tiny-logger/internal/services"
+)
+
+const (
+	defaultHTTPBatchSize     = 500
+	defaultHTTPBatchBytes    = 1 << 20
+	defaultHTTPFlushInterval = time.Second
+	defaultHTTPTimeout       = 10 * time.Second
+	defaultHTTPMaxRetries    = 3
+	defaultHTTPMinBackoff    = 100 * time.Millisecond
+	defaultHTTPMaxBackoff    = 10 * time.Second
+	defaultHTTPCapacity      = 8192
+	// maxHTTPResponseLen is the maximum number of response body bytes read to check the outcome of a request.
+	maxHTTPResponseLen = 1 << 20
+)
+
+// ErrHTTPURL is returned when the HTTPWriter URL is not an absolute http or https URL.
+var ErrHTTPURL = errors.New("tiny-logger: the HTTP writer URL must be an absolute http or https URL")
+
+// HTTPConfig holds the HTTPWriter settings. Zero values fall back to the documented defaults.
+type HTTPConfig struct {
+	// URL is the endpoint the batches are POSTed to, e.g. "http://loki:3100/loki/api/v1/push".
+	URL string
+	// Format defines how the batches are encoded into the request bodies. Defaults to NDJSONFormat.
+	Format HTTPBodyFormat
+	// Headers are added to every request, e.g. the Authorization header.
+	Headers http.Header
+	// Client is the HTTP client sending the requests. Defaults to a client with a 10 seconds timeout.
+	Client *http.Client
+	// Gzip enables the gzip compression of the request bodies.
+	Gzip bool
+	// BatchSize is the maximum number of entries sent in a single request. Defaults to 500.
+	BatchSize int
+	// BatchBytes is the maximum size of the entries sent in a single request, before encoding. Defaults to 1MB.
+	BatchBytes int
+	// FlushInterval is the maximum amount of time an entry waits for its batch to be filled. Defaults to 1 second.
+	FlushInterval time.Duration
+	// MaxRetries is the number of times a request failed because of the network, a 429 or a 5xx status is retried
+	// before dropping its entries. Defaults to 3, set a negative value to disable the retries.
+	MaxRetries int
+	// MinBackoff is the delay before the first retry, doubled after every failed attempt. Defaults to 100 milliseconds.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum delay between two retries. Defaults to 10 seconds.
+	MaxBackoff time.Duration
+	// Capacity is the maximum number of entries waiting to be sent, rounded up to the next power of two.
+	// Defaults to 8192.
+	Capacity int
+	// OverflowPolicy defines what happens when the entries waiting to be sent reach the Capacity.
+	OverflowPolicy OverflowPolicy
+}
+
+// HTTPWriter is an io.WriteCloser shipping the written entries to a log backend over HTTP from a background goroutine.
+// The entries are accumulated and POSTed in batches once BatchSize entries or BatchBytes bytes are queued,
+// or every FlushInterval, encoded by the configured HTTPBodyFormat: plain NDJSON, Elasticsearch _bulk or
+// Grafana Loki push requests.
+// Failed requests are retried with an exponential backoff, and their entries are dropped once the retries are
+// exhausted or if the backend rejects them. Once closed, the failed requests are not retried anymore: their
+// entries and the ones still queued are dropped.
+type HTTPWriter struct {
+	config        HTTPConfig
+	ring          *services.RingBuffer[httpEntry]
+	bufferPool    sync.Pool
+	queuedBytes   atomic.Int64
+	pending       atomic.Int64
+	dropped       atomic.Uint64
+	batch         []httpEntry
+	batchBytes    int
+	entries       []HTTPEntry
+	body          bytes.Buffer
+	gzipBody      bytes.Buffer
+	gzipWriter    *gzip.Writer
+	wakeCh        chan struct{}
+	spaceCh       chan struct{}
+	flushCh       chan chan struct{}
+	doneCh        chan struct{}
+	closeOnce     sync.Once
+	closeMu       sync.RWMutex
+	loopWg        sync.WaitGroup
+	closed        atomic.Bool
+	abandoned     bool
+	responseCheck httpResponseChecker
+}
+
+// httpEntry is an entry waiting to be sent, stored in a pooled buffer.
+type httpEntry struct {
+	time time.Time
+	buf  *bytes.Buffer
+}
+
+// Write queues the given entry to be sent with the next batch.
+func (hw *HTTPWriter) Write(p []byte) (int, error) {
+	hw.closeMu.RLock()
+	defer hw.closeMu.RUnlock()
+
+	if hw.closed.Load() {
+		return 0, os.ErrClosed
+	}
+
+	buf := hw.bufferPool.Get().(*bytes.Buffer)
+	buf.Write(bytes.TrimSuffix(p, []byte("\n")))
+	entry := httpEntry{time: time.Now(), buf: buf}
+
+	for !hw.ring.Push(entry) {
+		switch hw.config.OverflowPolicy {
+		case DropNewestOnOverflow:
+			hw.dropped.Add(1)
+			hw.putBuffer(buf)
+			return len(p), nil
+		case DropOldestOnOverflow:
+			if oldest, ok := hw.ring.Pop(); ok {
+				hw.dropped.Add(1)
+				hw.pending.Add(-1)
+				hw.queuedBytes.Add(-int64(oldest.buf.Len()))
+				hw.putBuffer(oldest.buf)
+			}
+		default:
+			hw.wake()
+			hw.waitForSpace()
+		}
+	}
+
+	hw.pending.Add(1)
+	queuedBytes := hw.queuedBytes.Add(int64(buf.Len()))
+
+	if hw.ring.Len() >= hw.config.BatchSize || queuedBytes >= int64(hw.config.BatchBytes) {
+		hw.wake()
+	}
+
+	return len(p), nil
+}
+
+// Dropped returns the number of entries discarded because of the overflow policy, the failed requests
+// or the backend rejections.
+func (hw *HTTPWriter) Dropped() uint64 {
+	return hw.dropped.Load()
+}
+
+// Pending returns the number of entries waiting to be sent.
+func (hw *HTTPWriter) Pending() int {
+	return int(hw.pending.Load())
+}
+
+// Flush sends all the queued entries, blocking until their requests are completed.
+func (hw *HTTPWriter) Flush() {
+	done := make(chan struct{})
+
+	select {
+	case hw.flushCh <- done:
+		<-done
+	case <-hw.doneCh:
+	}
+}
+
+// Close sends all the queued entries and stops the background goroutine. A request failing after Close
+// is not retried: its entries, and the ones still queued, are dropped instead.
+func (hw *HTTPWriter) Close() error {
+	hw.closeOnce.Do(func() {
+		// Once closed is set under the lock, no write can queue new entries for the last batches
+		hw.closeMu.Lock()
+		hw.closed.Store(true)
+		hw.closeMu.Unlock()
+
+		close(hw.doneCh)
+		hw.loopWg.Wait()
+	})
+
+	return nil
+}
+
+// waitForSpace waits until the background goroutine frees some slots, or for a short timeout.
+func (hw *HTTPWriter) waitForSpace() {
+	timer := time.NewTimer(blockedWriteTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-hw.spaceCh:
+	case <-timer.C:
+		runtime.Gosched()
+	}
+}
+
+// wake notifies the background goroutine that a batch is ready without ever blocking.
+func (hw *HTTPWriter) wake() {
+	select {
+	case hw.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// loop is the background goroutine sending the batches until the writer is closed.
+func (hw *HTTPWriter) loop() {
+	defer hw.loopWg.Done()
+
+	ticker := time.NewTicker(hw.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-hw.wakeCh:
+			hw.ship(false)
+		case <-ticker.C:
+			hw.ship(true)
+		case done := <-hw.flushCh:
+			hw.ship(true)
+			close(done)
+		case <-hw.doneCh:
+			hw.ship(true)
+			return
+		}
+	}
+}
+
+// ship sends the full batches of the queued entries, and the last partial one too if all is true.
+func (hw *HTTPWriter) ship(all bool) {
+	for {
+		full := hw.fillBatch()
+
+		if !full && (!all || len(hw.batch) == 0) {
+			return
+		}
+
+		if hw.abandoned {
+			hw.drop(len(hw.batch), errors.New("tiny-logger: HTTP writer closed after a failed request"))
+			hw.resetBatch()
+			continue
+		}
+
+		hw.send()
+	}
+}
+
+// fillBatch moves the queued entries into the current batch, returning true once it is full.
+func (hw *HTTPWriter) fillBatch() bool {
+	for len(hw.batch) < hw.config.BatchSize && hw.batchBytes < hw.config.BatchBytes {
+		entry, ok := hw.ring.Pop()
+		if !ok {
+			return false
+		}
+
+		hw.batch = append(hw.batch, entry)
+		hw.batchBytes += entry.buf.Len()
+		hw.queuedBytes.Add(-int64(entry.buf.Len()))
+
+		select {
+		case hw.spaceCh <- struct{}{}:
+		default:
+		}
+	}
+
+	return true
+}
+
+// send POSTs the current batch, retrying the failed requests, then resets it.
+func (hw *HTTPWriter) send() {
+	defer hw.resetBatch()
+
+	hw.entries = hw.entries[:0]
+	for _, entry := range hw.batch {
+		hw.entries = append(hw.entries, HTTPEntry{Time: entry.time, Line: entry.buf.Bytes()})
+	}
+
+	hw.body.Reset()
+	if err := hw.config.Format.EncodeBody(&hw.body, hw.entries); err != nil {
+		hw.drop(len(hw.batch), err)
+		return
+	}
+
+	body := hw.body.Bytes()
+	if hw.config.Gzip {
+		hw.gzipBody.Reset()
+		hw.gzipWriter.Reset(&hw.gzipBody)
+		_, _ = hw.gzipWriter.Write(body)
+		_ = hw.gzipWriter.Close()
+		body = hw.gzipBody.Bytes()
+	}
+
+	backoff := hw.config.MinBackoff
+
+	for attempt := 0; ; attempt++ {
+		retry, err := hw.post(body)
+		if err == nil {
+			return
+		}
+
+		if !retry || attempt >= hw.config.MaxRetries || hw.closed.Load() {
+			hw.abandoned = hw.closed.Load()
+			hw.drop(len(hw.batch), err)
+			return
+		}
+
+		if !hw.sleep(backoff/2 + rand.N(backoff/2+1)) {
+			hw.abandoned = true
+			hw.drop(len(hw.batch), err)
+			return
+		}
+
+		backoff = min(backoff*2, hw.config.MaxBackoff)
+	}
+}
+
+// sleep waits for the given duration, returning false if the writer is closed in the meantime.
+func (hw *HTTPWriter) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-hw.doneCh:
+		return false
+	}
+}
+
+// post sends a single request with the given body, returning whether it can be retried if it failed.
+func (hw *HTTPWriter) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, hw.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	for name, values := range hw.config.Headers {
+		req.Header[name] = values
+	}
+
+	req.Header.Set("Content-Type", hw.config.Format.ContentType())
+	if hw.config.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := hw.config.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseLen))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+
+		return retry, fmt.Errorf("tiny-logger: HTTP writer request failed with status %s: %s", resp.Status, respBody)
+	}
+
+	if hw.responseCheck != nil {
+		if rejected, err := hw.responseCheck.checkResponse(respBody); rejected > 0 {
+			hw.dropped.Add(uint64(rejected))
+			_, _ = os.Stderr.Write([]byte("tiny-logger-err: " + err.Error() + "\n"))
+		}
+	}
+
+	return false, nil
+}
+
+// drop counts the given number of entries as dropped, reporting the error that caused it.
+func (hw *HTTPWriter) drop(count int, err error) {
+	hw.dropped.Add(uint64(count))
+	_, _ = os.Stderr.Write([]byte("tiny-logger-err: " + err.Error() + "\n"))
+}
+
+// resetBatch puts the buffers of the current batch back to the pool and empties it.
+func (hw *HTTPWriter) resetBatch() {
+	hw.pending.Add(-int64(len(hw.batch)))
+
+	for i, entry := range hw.batch {
+		hw.putBuffer(entry.buf)
+		hw.batch[i] = httpEntry{}
+	}
+
+	hw.batch = hw.batch[:0]
+	hw.batchBytes = 0
+}
+
+// putBuffer puts the given bytes buffer back to the pool.
+func (hw *HTTPWriter) putBuffer(buf *bytes.Buffer) {
+	buf.Reset()
+	hw.bufferPool.Put(buf)
+}
+
+// NewHTTPWriter initializes a new HTTPWriter and starts its background goroutine.
+// An error is returned if the configured URL is not an absolute http or https URL.
+func NewHTTPWriter(config HTTPConfig) (*HTTPWriter, error) {
+	endpoint, err := url.Parse(config.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, ErrHTTPURL
+	}
+
+	if config.Format == nil {
+		config.Format = NDJSONFormat{}
+	}
+
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultHTTPBatchSize
+	}
+
+	if config.BatchBytes <= 0 {
+		config.BatchBytes = defaultHTTPBatchBytes
+	}
+
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultHTTPFlushInterval
+	}
+
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultHTTPMaxRetries
+	}
+
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = defaultHTTPMinBackoff
+	}
+
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = max(defaultHTTPMaxBackoff, config.MinBackoff)
+	}
+
+	if config.Capacity <= 0 {
+		config.Capacity = defaultHTTPCapacity
+	}
+
+	// The ring buffer needs at least two slots to tell the free ones from the filled ones
+	config.Capacity = max(config.Capacity, 2)
+
+	httpWriter := &HTTPWriter{
+		config:     config,
+		ring:       services.NewRingBuffer[httpEntry](config.Capacity),
+		gzipWriter: gzip.NewWriter(io.Discard),
+		wakeCh:     make(chan struct{}, 1),
+		spaceCh:    make(chan struct{}, 1),
+		flushCh:    make(chan chan struct{}),
+		doneCh:     make(chan struct{}),
+	}
+	httpWriter.bufferPool = sync.Pool{
+		New: func() any {
+			return new(bytes.Buffer)
+		},
+	}
+	httpWriter.responseCheck, _ = config.Format.(httpResponseChecker)
+
+	httpWriter.loopWg.Add(1)
+	go httpWriter.loop()
+
+	return httpWriter, nil
+}
@@ -0,0 +1,284 @@
+package sinks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Pho3b/tiny-logger/shared"
+)
+
+// HTTPEntry is a log entry queued by the HTTPWriter.
+type HTTPEntry struct {
+	// Time is the time the entry has been written at.
+	Time time.Time
+	// Line is the entry as produced by the Logger encoder, without the trailing newline.
+	Line []byte
+}
+
+// HTTPBodyFormat defines how a batch of entries is encoded into the body of an HTTPWriter request.
+type HTTPBodyFormat interface {
+	// ContentType returns the Content-Type header of the requests.
+	ContentType() string
+	// EncodeBody writes the given entries into the buffer.
+	EncodeBody(buf *bytes.Buffer, entries []HTTPEntry) error
+}
+
+// httpResponseChecker is implemented by the formats able to detect the entries rejected by a successful request.
+type httpResponseChecker interface {
+	// checkResponse returns the number of entries rejected according to the given response body,
+	// and the first rejection reason.
+	checkResponse(body []byte) (int, error)
+}
+
+// NDJSONFormat encodes the entries as newline delimited JSON, one entry per line.
+// It is meant to be used with the JSON encoder, but forwards the entries of any encoder as they are.
+type NDJSONFormat struct{}
+
+// ContentType returns the NDJSON Content-Type.
+func (f NDJSONFormat) ContentType() string {
+	return "application/x-ndjson"
+}
+
+// EncodeBody writes the given entries into the buffer, one per line.
+func (f NDJSONFormat) EncodeBody(buf *bytes.Buffer, entries []HTTPEntry) error {
+	for _, entry := range entries {
+		buf.Write(entry.Line)
+		buf.WriteByte('\n')
+	}
+
+	return nil
+}
+
+// ElasticsearchBulkFormat encodes the entries as an Elasticsearch _bulk request, indexing every entry as a document.
+// JSON entries are indexed as they are, adding the "@timestamp" property if missing, while the other ones are
+// wrapped into a {"@timestamp": ..., "msg": ...} document.
+type ElasticsearchBulkFormat struct {
+	// Index is the target index or data stream. If empty, the index of the request URL is used,
+	// e.g. "https://es.internal:9200/logs/_bulk".
+	Index string
+	// DataStream, if true, creates the documents instead of indexing them, as required by data streams.
+	DataStream bool
+}
+
+// ContentType returns the NDJSON Content-Type required by the _bulk API.
+func (f ElasticsearchBulkFormat) ContentType() string {
+	return "application/x-ndjson"
+}
+
+// EncodeBody writes the action and the document of every given entry into the buffer.
+func (f ElasticsearchBulkFormat) EncodeBody(buf *bytes.Buffer, entries []HTTPEntry) error {
+	action := "index"
+	if f.DataStream {
+		action = "create"
+	}
+
+	actionLine := []byte("{\"" + action + "\":{}}\n")
+	if f.Index != "" {
+		actionLine = []byte("{\"" + action + "\":{\"_index\":" + strconv.Quote(f.Index) + "}}\n")
+	}
+
+	for _, entry := range entries {
+		buf.Write(actionLine)
+		line := bytes.TrimSpace(entry.Line)
+		timestamp := entry.Time.UTC().Format(time.RFC3339Nano)
+
+		switch {
+		case !isJSONObject(line):
+			msg, _ := json.Marshal(string(line))
+			buf.WriteString("{\"@timestamp\":\"" + timestamp + "\",\"msg\":")
+			buf.Write(msg)
+			buf.WriteByte('}')
+		case hasTopLevelKey(line, "@timestamp"):
+			buf.Write(line)
+		default:
+			// The timestamp is spliced in as the first property of the document
+			buf.WriteString("{\"@timestamp\":\"" + timestamp + "\"")
+			if rest := bytes.TrimSpace(line[1:]); rest[0] != '}' {
+				buf.WriteByte(',')
+			}
+			buf.Write(line[1:])
+		}
+
+		buf.WriteByte('\n')
+	}
+
+	return nil
+}
+
+// checkResponse counts the items of the _bulk response that have not been indexed.
+func (f ElasticsearchBulkFormat) checkResponse(body []byte) (int, error) {
+	var response struct {
+		Errors bool                                     `json:"errors"`
+		Items  []map[string]elasticsearchBulkItemResult `json:"items"`
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil || !response.Errors {
+		return 0, nil
+	}
+
+	rejected := 0
+	var firstErr error
+
+	for _, item := range response.Items {
+		for _, result := range item {
+			if result.Status < 300 {
+				continue
+			}
+
+			rejected++
+			if firstErr == nil {
+				firstErr = fmt.Errorf(
+					"tiny-logger: elasticsearch rejected a document with status %d: %s", result.Status, result.Error,
+				)
+			}
+		}
+	}
+
+	return rejected, firstErr
+}
+
+// elasticsearchBulkItemResult is the outcome of a single _bulk action.
+type elasticsearchBulkItemResult struct {
+	Status int             `json:"status"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// LokiFormat encodes the entries as a Grafana Loki push API request, grouping them into streams by their labels.
+// Every stream is labelled with the static Labels, the entry level (if it is a JSON entry) and the chosen LabelKeys
+// found in the entry extras. Keep the LabelKeys to low cardinality values, as every label combination is
+// a separate stream in Loki.
+type LokiFormat struct {
+	// Labels are the static labels added to every stream, e.g. {"app": "api", "env": "prod"}.
+	// Loki rejects the streams without labels, so set at least one of them when the entries are not JSON.
+	Labels map[string]string
+	// LabelKeys are the keys of the extras, or of the bound fields, used as labels when present in an entry.
+	LabelKeys []string
+}
+
+// lokiPushRequest is the body of a Loki push API request.
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+// lokiStream is a set of entries sharing the same labels, each one encoded as a [timestamp, line] pair.
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// ContentType returns the JSON Content-Type.
+func (f LokiFormat) ContentType() string {
+	return "application/json"
+}
+
+// EncodeBody writes the push request of the given entries into the buffer.
+func (f LokiFormat) EncodeBody(buf *bytes.Buffer, entries []HTTPEntry) error {
+	request := lokiPushRequest{}
+	streamsIdx := make(map[string]int)
+
+	for _, entry := range entries {
+		labels := f.entryLabels(entry.Line)
+		key := lokiStreamKey(labels)
+
+		idx, found := streamsIdx[key]
+		if !found {
+			idx = len(request.Streams)
+			streamsIdx[key] = idx
+			request.Streams = append(request.Streams, lokiStream{Stream: labels})
+		}
+
+		request.Streams[idx].Values = append(
+			request.Streams[idx].Values,
+			[2]string{strconv.FormatInt(entry.Time.UnixNano(), 10), string(entry.Line)},
+		)
+	}
+
+	return json.NewEncoder(buf).Encode(request)
+}
+
+// entryLabels returns the labels of the stream the given entry belongs to.
+func (f LokiFormat) entryLabels(line []byte) map[string]string {
+	labels := make(map[string]string, len(f.Labels)+len(f.LabelKeys)+1)
+	for name, value := range f.Labels {
+		labels[lokiLabelName(name)] = value
+	}
+
+	var jsonLog shared.JsonLog
+	if !isJSONObject(line) || json.Unmarshal(line, &jsonLog) != nil {
+		return labels
+	}
+
+	if jsonLog.Level != "" {
+		labels["level"] = strings.ToLower(jsonLog.Level)
+	}
+
+	for _, key := range f.LabelKeys {
+		if value, found := jsonLog.Extras[key]; found {
+			labels[lokiLabelName(key)] = fmt.Sprint(value)
+		}
+	}
+
+	return labels
+}
+
+// lokiStreamKey returns a string uniquely identifying the given labels.
+func lokiStreamKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	var key strings.Builder
+	for _, name := range names {
+		key.WriteString(name)
+		key.WriteByte(0)
+		key.WriteString(labels[name])
+		key.WriteByte(0)
+	}
+
+	return key.String()
+}
+
+// lokiLabelName converts the given key into a valid Loki label name, replacing the invalid characters with '_'.
+func lokiLabelName(key string) string {
+	name := []byte(key)
+
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= '0' && c <= '9' && i > 0) {
+			name[i] = '_'
+		}
+	}
+
+	if len(name) == 0 {
+		return "_"
+	}
+
+	return string(name)
+}
+
+// hasTopLevelKey returns true if the given JSON object has a property with the given key,
+// regardless of the nested objects and of the string values containing it.
+func hasTopLevelKey(object []byte, key string) bool {
+	var properties map[string]json.RawMessage
+	if err := json.Unmarshal(object, &properties); err != nil {
+		return false
+	}
+
+	_, found := properties[key]
+
+	return found
+}
+
+// isJSONObject returns true if the given line is a valid JSON object.
+func isJSONObject(line []byte) bool {
+	line = bytes.TrimSpace(line)
+
+	return len(line) > 0 && line[0] == '{' && json.Valid(line)
+}
@@ -0,0 +1,122 @@
+package sinks
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testHTTPEntryTime = time.Date(2024, 3, 11, 18, 35, 43, 123456000, time.UTC)
+
+func TestNDJSONFormat_EncodeBody(t *testing.T) {
+	var buf bytes.Buffer
+	format := NDJSONFormat{}
+
+	err := format.EncodeBody(&buf, []HTTPEntry{
+		{Time: testHTTPEntryTime, Line: []byte(`{"level":"INFO","msg":"first"}`)},
+		{Time: testHTTPEntryTime, Line: []byte(`INFO: second`)},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"level":"INFO","msg":"first"}`+"\n"+"INFO: second\n", buf.String())
+	assert.Equal(t, "application/x-ndjson", format.ContentType())
+}
+
+func TestElasticsearchBulkFormat_EncodeBody(t *testing.T) {
+	var buf bytes.Buffer
+	entries := []HTTPEntry{
+		{Time: testHTTPEntryTime, Line: []byte(`{"level":"INFO","msg":"json"}`)},
+		{Time: testHTTPEntryTime, Line: []byte(`{}`)},
+		{Time: testHTTPEntryTime, Line: []byte(`{"@timestamp":"2024-01-01T00:00:00Z","msg":"stamped"}`)},
+		{Time: testHTTPEntryTime, Line: []byte(`WARN: "plain" text`)},
+		{Time: testHTTPEntryTime, Line: []byte(`{"msg":"nested","extras":{"@timestamp":"2024-01-01"}}`)},
+	}
+
+	assert.NoError(t, ElasticsearchBulkFormat{Index: "logs"}.EncodeBody(&buf, entries))
+	assert.Equal(
+		t,
+		`{"index":{"_index":"logs"}}`+"\n"+
+			`{"@timestamp":"2024-03-11T18:35:43.123456Z","level":"INFO","msg":"json"}`+"\n"+
+			`{"index":{"_index":"logs"}}`+"\n"+
+			`{"@timestamp":"2024-03-11T18:35:43.123456Z"}`+"\n"+
+			`{"index":{"_index":"logs"}}`+"\n"+
+			`{"@timestamp":"2024-01-01T00:00:00Z","msg":"stamped"}`+"\n"+
+			`{"index":{"_index":"logs"}}`+"\n"+
+			`{"@timestamp":"2024-03-11T18:35:43.123456Z","msg":"WARN: \"plain\" text"}`+"\n"+
+			`{"index":{"_index":"logs"}}`+"\n"+
+			`{"@timestamp":"2024-03-11T18:35:43.123456Z","msg":"nested","extras":{"@timestamp":"2024-01-01"}}`+"\n",
+		buf.String(),
+	)
+
+	buf.Reset()
+	assert.NoError(t, ElasticsearchBulkFormat{DataStream: true}.EncodeBody(&buf, entries[:1]))
+	assert.Equal(
+		t,
+		`{"create":{}}`+"\n"+`{"@timestamp":"2024-03-11T18:35:43.123456Z","level":"INFO","msg":"json"}`+"\n",
+		buf.String(),
+	)
+}
+
+func TestElasticsearchBulkFormat_CheckResponse(t *testing.T) {
+	format := ElasticsearchBulkFormat{}
+
+	rejected, err := format.checkResponse([]byte(`{"errors":false,"items":[{"index":{"status":201}}]}`))
+	assert.Zero(t, rejected)
+	assert.NoError(t, err)
+
+	rejected, err = format.checkResponse([]byte("not json"))
+	assert.Zero(t, rejected)
+	assert.NoError(t, err)
+
+	rejected, err = format.checkResponse([]byte(
+		`{"errors":true,"items":[{"create":{"status":409,"error":{"type":"version_conflict"}}},` +
+			`{"create":{"status":201}},{"create":{"status":400}}]}`,
+	))
+	assert.Equal(t, 2, rejected)
+	assert.EqualError(
+		t,
+		err,
+		`tiny-logger: elasticsearch rejected a document with status 409: {"type":"version_conflict"}`,
+	)
+}
+
+func TestLokiFormat_EncodeBody(t *testing.T) {
+	var buf bytes.Buffer
+	format := LokiFormat{Labels: map[string]string{"app": "api"}, LabelKeys: []string{"tenant", "http.method"}}
+
+	err := format.EncodeBody(&buf, []HTTPEntry{
+		{Time: testHTTPEntryTime, Line: []byte(`{"level":"INFO","msg":"a","extras":{"tenant":"acme","user":"bob"}}`)},
+		{Time: testHTTPEntryTime, Line: []byte(`{"level":"ERROR","msg":"b","extras":{"http.method":"GET"}}`)},
+		{Time: testHTTPEntryTime.Add(time.Second), Line: []byte(`{"level":"INFO","msg":"c","extras":{"tenant":"acme"}}`)},
+		{Time: testHTTPEntryTime, Line: []byte(`INFO: plain`)},
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(
+		t,
+		`{"streams":[
+			{"stream":{"app":"api","level":"info","tenant":"acme"},"values":[
+				["1710182143123456000","{\"level\":\"INFO\",\"msg\":\"a\",\"extras\":{\"tenant\":\"acme\",\"user\":\"bob\"}}"],
+				["1710182144123456000","{\"level\":\"INFO\",\"msg\":\"c\",\"extras\":{\"tenant\":\"acme\"}}"]
+			]},
+			{"stream":{"app":"api","level":"error","http_method":"GET"},"values":[
+				["1710182143123456000","{\"level\":\"ERROR\",\"msg\":\"b\",\"extras\":{\"http.method\":\"GET\"}}"]
+			]},
+			{"stream":{"app":"api"},"values":[["1710182143123456000","INFO: plain"]]}
+		]}`,
+		buf.String(),
+	)
+	assert.Equal(t, "application/json", format.ContentType())
+}
+
+func TestLokiLabelName(t *testing.T) {
+	for key, expected := range map[string]string{
+		"level":       "level",
+		"http.method": "http_method",
+		"2xx":         "_xx",
+		"a-b c":       "a_b_c",
+		"":            "_",
+	} {
+		assert.Equal(t, expected, lokiLabelName(key), key)
+	}
+}
@@ -0,0 +1,333 @@
+package sinks
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testBackend is an HTTP server recording the requests received, replying with the queued responses
+// and with 200 once they are over.
+type testBackend struct {
+	*httptest.Server
+	mu        sync.Mutex
+	bodies    []string
+	headers   []http.Header
+	responses []testBackendResponse
+}
+
+// testBackendResponse is a response queued on a testBackend.
+type testBackendResponse struct {
+	status int
+	body   string
+}
+
+func (b *testBackend) requests() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]string(nil), b.bodies...)
+}
+
+func newTestBackend(t *testing.T, responses ...testBackendResponse) *testBackend {
+	backend := &testBackend{responses: responses}
+	backend.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gzipReader, err := gzip.NewReader(r.Body)
+			assert.NoError(t, err)
+			body = gzipReader
+		}
+
+		content, err := io.ReadAll(body)
+		assert.NoError(t, err)
+
+		backend.mu.Lock()
+		defer backend.mu.Unlock()
+
+		backend.bodies = append(backend.bodies, string(content))
+		backend.headers = append(backend.headers, r.Header.Clone())
+
+		if len(backend.responses) > 0 {
+			response := backend.responses[0]
+			backend.responses = backend.responses[1:]
+			w.WriteHeader(response.status)
+			_, _ = w.Write([]byte(response.body))
+		}
+	}))
+	t.Cleanup(backend.Close)
+
+	return backend
+}
+
+func newTestHTTPWriter(t *testing.T, config HTTPConfig) *HTTPWriter {
+	httpWriter, err := NewHTTPWriter(config)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = httpWriter.Close() })
+
+	return httpWriter
+}
+
+func writeEntries(t *testing.T, httpWriter *HTTPWriter, from, to int) {
+	for i := from; i < to; i++ {
+		entry := `{"msg":"entry ` + strconv.Itoa(i) + `"}` + "\n"
+		n, err := httpWriter.Write([]byte(entry))
+		assert.NoError(t, err)
+		assert.Equal(t, len(entry), n)
+	}
+}
+
+func TestHTTPWriter_BatchSize(t *testing.T) {
+	backend := newTestBackend(t)
+	httpWriter := newTestHTTPWriter(t, HTTPConfig{URL: backend.URL, BatchSize: 3, FlushInterval: time.Hour})
+
+	writeEntries(t, httpWriter, 0, 7)
+	assert.Eventually(t, func() bool { return httpWriter.Pending() == 1 }, time.Second, time.Millisecond)
+	assert.Len(t, backend.requests(), 2)
+
+	httpWriter.Flush()
+	assert.Equal(
+		t,
+		[]string{
+			`{"msg":"entry 0"}` + "\n" + `{"msg":"entry 1"}` + "\n" + `{"msg":"entry 2"}` + "\n",
+			`{"msg":"entry 3"}` + "\n" + `{"msg":"entry 4"}` + "\n" + `{"msg":"entry 5"}` + "\n",
+			`{"msg":"entry 6"}` + "\n",
+		},
+		backend.requests(),
+	)
+	assert.Equal(t, 0, httpWriter.Pending())
+	assert.Equal(t, "application/x-ndjson", backend.headers[0].Get("Content-Type"))
+}
+
+func TestHTTPWriter_BatchBytes(t *testing.T) {
+	backend := newTestBackend(t)
+	// Every entry is 17 bytes long without the trailing newline
+	httpWriter := newTestHTTPWriter(t, HTTPConfig{URL: backend.URL, BatchBytes: 34, FlushInterval: time.Hour})
+
+	writeEntries(t, httpWriter, 0, 5)
+	httpWriter.Flush()
+
+	requests := backend.requests()
+	assert.Len(t, requests, 3)
+	assert.Equal(t, 2, strings.Count(requests[0], "\n"))
+	assert.Equal(t, 1, strings.Count(requests[2], "\n"))
+}
+
+func TestHTTPWriter_FlushInterval(t *testing.T) {
+	backend := newTestBackend(t)
+	httpWriter := newTestHTTPWriter(t, HTTPConfig{URL: backend.URL, FlushInterval: 10 * time.Millisecond})
+
+	writeEntries(t, httpWriter, 0, 2)
+	assert.Eventually(t, func() bool { return len(backend.requests()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, `{"msg":"entry 0"}`+"\n"+`{"msg":"entry 1"}`+"\n", backend.requests()[0])
+}
+
+func TestHTTPWriter_GzipAndHeaders(t *testing.T) {
+	backend := newTestBackend(t)
+	httpWriter := newTestHTTPWriter(t, HTTPConfig{
+		URL:     backend.URL,
+		Gzip:    true,
+		Headers: http.Header{"Authorization": {"Bearer token"}},
+	})
+
+	writeEntries(t, httpWriter, 0, 1)
+	httpWriter.Flush()
+
+	assert.Equal(t, []string{`{"msg":"entry 0"}` + "\n"}, backend.requests())
+	assert.Equal(t, "gzip", backend.headers[0].Get("Content-Encoding"))
+	assert.Equal(t, "Bearer token", backend.headers[0].Get("Authorization"))
+}
+
+func TestHTTPWriter_Retries(t *testing.T) {
+	backend := newTestBackend(
+		t,
+		testBackendResponse{status: http.StatusServiceUnavailable},
+		testBackendResponse{status: http.StatusTooManyRequests},
+	)
+	httpWriter := newTestHTTPWriter(t, HTTPConfig{URL: backend.URL, MinBackoff: time.Millisecond})
+
+	writeEntries(t, httpWriter, 0, 2)
+	httpWriter.Flush()
+
+	assert.Len(t, backend.requests(), 3)
+	assert.Equal(t, backend.requests()[0], backend.requests()[2])
+	assert.Equal(t, uint64(0), httpWriter.Dropped())
+}
+
+func TestHTTPWriter_Retries_Exhausted(t *testing.T) {
+	backend := newTestBackend(
+		t,
+		testBackendResponse{status: http.StatusInternalServerError},
+		testBackendResponse{status: http.StatusInternalServerError},
+		testBackendResponse{status: http.StatusInternalServerError},
+		testBackendResponse{status: http.StatusBadRequest},
+	)
+	httpWriter := newTestHTTPWriter(t, HTTPConfig{URL: backend.URL, MaxRetries: 2, MinBackoff: time.Millisecond})
+
+	writeEntries(t, httpWriter, 0, 2)
+	httpWriter.Flush()
+	assert.Len(t, backend.requests(), 3)
+	assert.Equal(t, uint64(2), httpWriter.Dropped())
+
+	// The client errors are never retried
+	writeEntries(t, httpWriter, 2, 3)
+	httpWriter.Flush()
+	assert.Len(t, backend.requests(), 4)
+	assert.Equal(t, uint64(3), httpWriter.Dropped())
+	assert.Equal(t, 0, httpWriter.Pending())
+}
+
+func TestHTTPWriter_Retries_Disabled(t *testing.T) {
+	backend := newTestBackend(t, testBackendResponse{status: http.StatusBadGateway})
+	httpWriter := newTestHTTPWriter(t, HTTPConfig{URL: backend.URL, MaxRetries: -1})
+
+	writeEntries(t, httpWriter, 0, 1)
+	httpWriter.Flush()
+	assert.Len(t, backend.requests(), 1)
+	assert.Equal(t, uint64(1), httpWriter.Dropped())
+}
+
+func TestHTTPWriter_ElasticsearchRejections(t *testing.T) {
+	backend := newTestBackend(t, testBackendResponse{
+		status: http.StatusOK,
+		body: `{"errors":true,"items":[{"index":{"status":201}},` +
+			`{"index":{"status":400,"error":{"type":"mapper_parsing_exception"}}}]}`,
+	})
+	httpWriter := newTestHTTPWriter(t, HTTPConfig{URL: backend.URL, Format: ElasticsearchBulkFormat{Index: "logs"}})
+
+	writeEntries(t, httpWriter, 0, 2)
+	httpWriter.Flush()
+	assert.Len(t, backend.requests(), 1)
+	assert.Equal(t, uint64(1), httpWriter.Dropped())
+}
+
+func TestHTTPWriter_DropNewestOnOverflow(t *testing.T) {
+	backend := newTestBackend(t)
+	httpWriter := newTestHTTPWriter(
+		t,
+		HTTPConfig{URL: backend.URL, Capacity: 4, OverflowPolicy: DropNewestOnOverflow, FlushInterval: time.Hour},
+	)
+
+	writeEntries(t, httpWriter, 0, 10)
+	assert.Equal(t, 4, httpWriter.Pending())
+	assert.Equal(t, uint64(6), httpWriter.Dropped())
+
+	httpWriter.Flush()
+	assert.Equal(t, 4, strings.Count(backend.requests()[0], "\n"))
+	assert.Contains(t, backend.requests()[0], "entry 3")
+}
+
+func TestHTTPWriter_DropOldestOnOverflow(t *testing.T) {
+	backend := newTestBackend(t)
+	httpWriter := newTestHTTPWriter(
+		t,
+		HTTPConfig{URL: backend.URL, Capacity: 4, OverflowPolicy: DropOldestOnOverflow, FlushInterval: time.Hour},
+	)
+
+	writeEntries(t, httpWriter, 0, 10)
+	assert.Equal(t, 4, httpWriter.Pending())
+	assert.Equal(t, uint64(6), httpWriter.Dropped())
+
+	httpWriter.Flush()
+	assert.Equal(t, 4, strings.Count(backend.requests()[0], "\n"))
+	assert.Contains(t, backend.requests()[0], "entry 9")
+	assert.NotContains(t, backend.requests()[0], "entry 5")
+}
+
+func TestHTTPWriter_Close(t *testing.T) {
+	backend := newTestBackend(t)
+	httpWriter, err := NewHTTPWriter(HTTPConfig{URL: backend.URL, FlushInterval: time.Hour})
+	assert.NoError(t, err)
+
+	// The pending entries are sent on Close
+	writeEntries(t, httpWriter, 0, 3)
+	assert.NoError(t, httpWriter.Close())
+	assert.NoError(t, httpWriter.Close())
+	assert.Len(t, backend.requests(), 1)
+	assert.Equal(t, 0, httpWriter.Pending())
+
+	_, err = httpWriter.Write([]byte("closed\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+	httpWriter.Flush()
+}
+
+func TestHTTPWriter_Close_DuringBackoff(t *testing.T) {
+	backend := newTestBackend(
+		t,
+		testBackendResponse{status: http.StatusServiceUnavailable},
+		testBackendResponse{status: http.StatusServiceUnavailable},
+	)
+	httpWriter, err := NewHTTPWriter(
+		HTTPConfig{URL: backend.URL, BatchSize: 1, FlushInterval: time.Hour, MinBackoff: time.Minute},
+	)
+	assert.NoError(t, err)
+
+	writeEntries(t, httpWriter, 0, 1)
+	assert.Eventually(t, func() bool { return len(backend.requests()) == 1 }, time.Second, time.Millisecond)
+	writeEntries(t, httpWriter, 1, 3)
+
+	// Close interrupts the backoff and drops the entries instead of retrying
+	start := time.Now()
+	assert.NoError(t, httpWriter.Close())
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Len(t, backend.requests(), 1)
+	assert.Equal(t, uint64(3), httpWriter.Dropped())
+	assert.Equal(t, 0, httpWriter.Pending())
+}
+
+func TestHTTPWriter_Close_ConcurrentWrites(t *testing.T) {
+	var wg sync.WaitGroup
+	var written sync.Map
+	backend := newTestBackend(t)
+	httpWriter, err := NewHTTPWriter(HTTPConfig{URL: backend.URL, BatchSize: 8})
+	assert.NoError(t, err)
+
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; ; i++ {
+				entry := "entry " + strconv.Itoa(g) + "-" + strconv.Itoa(i)
+				if _, err := httpWriter.Write([]byte(entry + "\n")); err != nil {
+					return
+				}
+
+				written.Store(entry, true)
+			}
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	assert.NoError(t, httpWriter.Close())
+	wg.Wait()
+
+	// Every entry accepted by Write is sent, none is left behind by Close
+	sent := strings.Count(strings.Join(backend.requests(), ""), "\n")
+	writtenCount := 0
+	written.Range(func(_, _ any) bool {
+		writtenCount++
+		return true
+	})
+
+	assert.Equal(t, writtenCount, sent)
+	assert.Equal(t, 0, httpWriter.Pending())
+	assert.Equal(t, uint64(0), httpWriter.Dropped())
+}
+
+func TestNewHTTPWriter_Errors(t *testing.T) {
+	for _, endpoint := range []string{"", "localhost:3100", "/loki/api/v1/push", "ftp://host/path", "http://"} {
+		_, err := NewHTTPWriter(HTTPConfig{URL: endpoint})
+		assert.ErrorIs(t, err, ErrHTTPURL, endpoint)
+	}
+}
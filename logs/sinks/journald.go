@@ -0,0 +1,108 @@
+package sinks
+
+import (
+	"net"
+	"os"
+	"sync"
+)
+
+// defaultJournaldPath is the path used when JournaldConfig.Path is not set.
+const defaultJournaldPath = "/run/systemd/journal/socket"
+
+// journaldTempDir is the directory of the temporary files the large entries are passed through.
+// It must be one of the tmpfs directories journald accepts the files of unprivileged clients from.
+var journaldTempDir = "/dev/shm"
+
+// JournaldConfig holds the JournaldWriter settings.
+type JournaldConfig struct {
+	// Path is the path of the journald native protocol socket. Defaults to "/run/systemd/journal/socket".
+	Path string
+}
+
+// JournaldWriter is an io.WriteCloser sending every write, expected to be a whole journal entry in the native
+// protocol format such as the ones produced by the encoders.JournaldEncoder, to systemd-journald as a datagram.
+// The entries too large for a datagram are written to an unlinked temporary file in /dev/shm, whose file descriptor
+// is passed to journald instead.
+// Since every datagram is addressed to the socket path, the entries keep being delivered after a journald restart.
+type JournaldWriter struct {
+	mu     sync.Mutex
+	conn   *net.UnixConn
+	addr   *net.UnixAddr
+	closed bool
+}
+
+// Write sends the given entry to journald.
+func (jw *JournaldWriter) Write(p []byte) (int, error) {
+	jw.mu.Lock()
+	defer jw.mu.Unlock()
+
+	if jw.closed {
+		return 0, os.ErrClosed
+	}
+
+	_, err := jw.conn.WriteToUnix(p, jw.addr)
+	if isMsgTooLarge(err) {
+		err = jw.sendFile(p)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close closes the socket the entries are sent from.
+func (jw *JournaldWriter) Close() error {
+	jw.mu.Lock()
+	defer jw.mu.Unlock()
+
+	if jw.closed {
+		return nil
+	}
+
+	jw.closed = true
+
+	return jw.conn.Close()
+}
+
+// sendFile writes the given entry to an unlinked temporary file and passes its file descriptor to journald.
+func (jw *JournaldWriter) sendFile(p []byte) error {
+	file, err := os.CreateTemp(journaldTempDir, "tiny-logger-journal-*")
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	if err = os.Remove(file.Name()); err != nil {
+		return err
+	}
+
+	if _, err = file.Write(p); err != nil {
+		return err
+	}
+
+	return sendFd(jw.conn, jw.addr, file)
+}
+
+// NewJournaldWriter initializes and returns a new JournaldWriter sending the entries to the journald socket
+// described by the given configuration.
+// An error is returned if the socket does not exist, e.g. when not running under systemd.
+func NewJournaldWriter(config JournaldConfig) (*JournaldWriter, error) {
+	if config.Path == "" {
+		config.Path = defaultJournaldPath
+	}
+
+	if _, err := os.Stat(config.Path); err != nil {
+		return nil, err
+	}
+
+	// The socket is bound to an autogenerated abstract address, so that it can send datagrams to any path
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &JournaldWriter{conn: conn, addr: &net.UnixAddr{Name: config.Path, Net: "unixgram"}}, nil
+}
@@ -0,0 +1,19 @@
+//go:build !unix
+
+package sinks
+
+import (
+	"errors"
+	"net"
+	"os"
+)
+
+// isMsgTooLarge always returns false, since the large entries cannot be passed to journald on this platform.
+func isMsgTooLarge(_ error) bool {
+	return false
+}
+
+// sendFd always returns errors.ErrUnsupported, since file descriptors cannot be passed on this platform.
+func sendFd(_ *net.UnixConn, _ *net.UnixAddr, _ *os.File) error {
+	return errors.ErrUnsupported
+}
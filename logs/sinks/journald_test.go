@@ -0,0 +1,128 @@
+//go:build linux
+
+package sinks
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestJournald returns a unixgram listener standing in for the journald socket, and its path.
+func newTestJournald(t *testing.T) (*net.UnixConn, string) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	return listener, path
+}
+
+// readJournalEntry reads a single entry from the given listener, following the passed file descriptor if any.
+func readJournalEntry(t *testing.T, listener *net.UnixConn) []byte {
+	buf := make([]byte, 64<<10)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	_ = listener.SetReadDeadline(time.Now().Add(time.Second))
+
+	n, oobn, _, _, err := listener.ReadMsgUnix(buf, oob)
+	assert.NoError(t, err)
+
+	if oobn == 0 {
+		return buf[:n]
+	}
+
+	assert.Zero(t, n)
+	messages, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	assert.NoError(t, err)
+	fds, err := syscall.ParseUnixRights(&messages[0])
+	assert.NoError(t, err)
+
+	file := os.NewFile(uintptr(fds[0]), "journal-entry")
+	defer file.Close()
+
+	content, err := io.ReadAll(io.NewSectionReader(file, 0, 1<<30))
+	assert.NoError(t, err)
+
+	return content
+}
+
+func TestJournaldWriter_Write(t *testing.T) {
+	listener, path := newTestJournald(t)
+
+	journaldWriter, err := NewJournaldWriter(JournaldConfig{Path: path})
+	assert.NoError(t, err)
+	defer journaldWriter.Close()
+
+	n, err := journaldWriter.Write([]byte("MESSAGE=hello\nPRIORITY=6\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 25, n)
+	assert.Equal(t, "MESSAGE=hello\nPRIORITY=6\n", string(readJournalEntry(t, listener)))
+}
+
+func TestJournaldWriter_Write_LargeEntry(t *testing.T) {
+	journaldTempDir = t.TempDir()
+	defer func() { journaldTempDir = "/dev/shm" }()
+
+	listener, path := newTestJournald(t)
+
+	journaldWriter, err := NewJournaldWriter(JournaldConfig{Path: path})
+	assert.NoError(t, err)
+	defer journaldWriter.Close()
+
+	// The entry exceeds the maximum datagram size, so it is passed through an unlinked temporary file
+	entry := append([]byte("MESSAGE=large\nPAYLOAD="), bytes.Repeat([]byte{'x'}, 8<<20)...)
+	entry = append(entry, '\n')
+
+	n, err := journaldWriter.Write(entry)
+	assert.NoError(t, err)
+	assert.Equal(t, len(entry), n)
+	assert.Equal(t, entry, readJournalEntry(t, listener))
+
+	tempFiles, err := os.ReadDir(journaldTempDir)
+	assert.NoError(t, err)
+	assert.Empty(t, tempFiles)
+}
+
+func TestJournaldWriter_Restart(t *testing.T) {
+	listener, path := newTestJournald(t)
+
+	journaldWriter, err := NewJournaldWriter(JournaldConfig{Path: path})
+	assert.NoError(t, err)
+	defer journaldWriter.Close()
+
+	// journald restarts, recreating its socket
+	assert.NoError(t, listener.Close())
+	_ = os.Remove(path)
+
+	_, err = journaldWriter.Write([]byte("MESSAGE=while restarting\n"))
+	assert.Error(t, err)
+
+	listener, err = net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	_, err = journaldWriter.Write([]byte("MESSAGE=after restart\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "MESSAGE=after restart\n", string(readJournalEntry(t, listener)))
+}
+
+func TestJournaldWriter_Errors(t *testing.T) {
+	_, err := NewJournaldWriter(JournaldConfig{Path: filepath.Join(t.TempDir(), "missing.sock")})
+	assert.Error(t, err)
+
+	_, path := newTestJournald(t)
+	journaldWriter, err := NewJournaldWriter(JournaldConfig{Path: path})
+	assert.NoError(t, err)
+	assert.NoError(t, journaldWriter.Close())
+	assert.NoError(t, journaldWriter.Close())
+
+	_, err = journaldWriter.Write([]byte("MESSAGE=closed\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
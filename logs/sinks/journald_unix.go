@@ -0,0 +1,22 @@
+//go:build unix
+
+package sinks
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+)
+
+// isMsgTooLarge returns true if the given error reports a datagram too large to be sent.
+func isMsgTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
+
+// sendFd passes the given file descriptor to the given address over the given unix socket, with an empty payload.
+func sendFd(conn *net.UnixConn, addr *net.UnixAddr, file *os.File) error {
+	_, _, err := conn.WriteMsgUnix(nil, syscall.UnixRights(int(file.Fd())), addr)
+
+	return err
+}
@@ -0,0 +1,586 @@
+package sinks
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Pho3b/tiny-logger/internal/services"
+)
+
+const (
+	defaultNetworkDialTimeout  = 5 * time.Second
+	defaultNetworkWriteTimeout = 5 * time.Second
+	defaultNetworkMinBackoff   = 100 * time.Millisecond
+	defaultNetworkMaxBackoff   = 30 * time.Second
+	defaultNetworkCapacity     = 1024
+	defaultNetworkBatchSize    = 128
+	defaultMaxSpoolSize        = 64 << 20
+	// spoolRecordHeaderLen is the length of the header preceding every entry in the spool file.
+	spoolRecordHeaderLen = 4
+)
+
+// dialNetwork establishes the connections to the log collector, using TLS if the given config is not nil.
+var dialNetwork = func(dialer *net.Dialer, tlsConfig *tls.Config, network, address string) (net.Conn, error) {
+	if tlsConfig != nil {
+		return (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).Dial(network, address)
+	}
+
+	return dialer.Dial(network, address)
+}
+
+// ErrNetworkTLSDatagram is returned when TLS is configured for a datagram network.
+var ErrNetworkTLSDatagram = errors.New("tiny-logger: TLS is supported by the stream networks only")
+
+// NetworkConfig holds the NetworkWriter settings. Zero values fall back to the documented defaults.
+type NetworkConfig struct {
+	// Network is the network of the log collector: "tcp", "udp", "unix" or "unixgram"
+	// (including the "4" and "6" variants of the IP ones).
+	Network string
+	// Address is the address of the log collector, e.g. "localhost:5170" or "/run/collector.sock".
+	Address string
+	// TLSConfig, if set, enables TLS on the stream networks.
+	TLSConfig *tls.Config
+	// DialTimeout is the maximum amount of time a connection attempt can take. Defaults to 5 seconds.
+	DialTimeout time.Duration
+	// WriteTimeout is the maximum amount of time sending a batch can take. Defaults to 5 seconds.
+	WriteTimeout time.Duration
+	// MinBackoff is the delay before the first reconnection attempt, doubled after every failed attempt.
+	// Defaults to 100 milliseconds.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum delay between two reconnection attempts. Defaults to 30 seconds.
+	MaxBackoff time.Duration
+	// Capacity is the maximum number of entries queued in memory, rounded up to the next power of two.
+	// Defaults to 1024.
+	Capacity int
+	// OverflowPolicy defines what happens when the entries queued in memory reach the Capacity,
+	// unless a SpoolDir is set.
+	OverflowPolicy OverflowPolicy
+	// BatchSize is the maximum number of entries sent through a single write on the stream networks.
+	// Defaults to 128.
+	BatchSize int
+	// SpoolDir, if set, is the directory of the file the entries are spilled to once the in-memory queue is full,
+	// typically while the log collector is unreachable. The file is removed on Close.
+	SpoolDir string
+	// MaxSpoolSize is the maximum size in bytes of the spool file: the entries not fitting in it are dropped.
+	// Defaults to 64MB.
+	MaxSpoolSize int64
+}
+
+// NetworkWriter is an io.WriteCloser sending the written entries to a log collector over TCP, UDP or unix sockets
+// from a background goroutine, so that callers never wait for the network.
+// The entries are queued in a bounded lock-free ring buffer, optionally backed by a spool file on disk, and sent in
+// batches: a single write per batch on the stream networks, a datagram per entry on the datagram ones.
+// When the connection is lost, the entries keep being queued while the background goroutine reconnects with an
+// exponential backoff. A batch interrupted by a connection failure is sent again entirely, so a few entries may
+// be delivered twice.
+type NetworkWriter struct {
+	config     NetworkConfig
+	datagram   bool
+	ring       *services.RingBuffer[*bytes.Buffer]
+	bufferPool sync.Pool
+	// mu guards the spool, while spooling tells the writers that the new entries must follow the spooled ones
+	mu        sync.Mutex
+	spool     *spoolFile
+	spooling  atomic.Bool
+	conn      net.Conn
+	connected atomic.Bool
+	wakeCh    chan struct{}
+	spaceCh   chan struct{}
+	doneCh    chan struct{}
+	closeOnce sync.Once
+	loopWg    sync.WaitGroup
+	// closeMu guards the closed flag, so that no write can queue new entries once the writer is closed
+	closeMu sync.RWMutex
+	closed  atomic.Bool
+	dropped atomic.Uint64
+	// inFlight is the number of entries taken from the queues by the background goroutine and not sent yet
+	inFlight atomic.Int64
+}
+
+// Write queues the given entry to be sent to the log collector.
+func (nw *NetworkWriter) Write(p []byte) (int, error) {
+	nw.closeMu.RLock()
+	defer nw.closeMu.RUnlock()
+
+	if nw.closed.Load() {
+		return 0, os.ErrClosed
+	}
+
+	buf := nw.bufferPool.Get().(*bytes.Buffer)
+	buf.Write(p)
+
+	for nw.spooling.Load() || !nw.ring.Push(buf) {
+		if nw.spool != nil {
+			nw.spoolEntry(buf)
+			break
+		}
+
+		switch nw.config.OverflowPolicy {
+		case DropNewestOnOverflow:
+			nw.dropped.Add(1)
+			nw.putBuffer(buf)
+			return len(p), nil
+		case DropOldestOnOverflow:
+			if oldest, ok := nw.ring.Pop(); ok {
+				nw.dropped.Add(1)
+				nw.putBuffer(oldest)
+			}
+		default:
+			// The writes blocked on a full queue give up once the writer is closing, not to block Close
+			if nw.closed.Load() {
+				nw.dropped.Add(1)
+				nw.putBuffer(buf)
+				return len(p), nil
+			}
+
+			nw.wake()
+			nw.waitForSpace()
+		}
+	}
+
+	nw.wake()
+
+	return len(p), nil
+}
+
+// Dropped returns the number of entries discarded because of the overflow policy, the spool size limit,
+// or because they could not be sent before Close.
+func (nw *NetworkWriter) Dropped() uint64 {
+	return nw.dropped.Load()
+}
+
+// Pending returns the number of entries waiting to be sent, either in memory or in the spool file.
+func (nw *NetworkWriter) Pending() int {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+
+	pending := nw.ring.Len() + int(nw.inFlight.Load())
+	if nw.spool != nil {
+		pending += nw.spool.records
+	}
+
+	return pending
+}
+
+// Connected returns true if the NetworkWriter is currently connected to the log collector.
+func (nw *NetworkWriter) Connected() bool {
+	return nw.connected.Load()
+}
+
+// Close sends the pending entries, unless the log collector is unreachable, then closes the connection and
+// removes the spool file. The entries that cannot be sent are dropped.
+func (nw *NetworkWriter) Close() error {
+	var err error
+
+	nw.closeOnce.Do(func() {
+		// Once the lock is acquired, the ongoing writes have queued their entries and the new ones are rejected
+		nw.closed.Store(true)
+		nw.closeMu.Lock()
+		nw.closeMu.Unlock()
+
+		close(nw.doneCh)
+		nw.loopWg.Wait()
+
+		for {
+			buf, ok := nw.ring.Pop()
+			if !ok {
+				break
+			}
+
+			nw.dropped.Add(1)
+			nw.putBuffer(buf)
+		}
+
+		if nw.conn != nil {
+			err = nw.conn.Close()
+			nw.connected.Store(false)
+		}
+
+		if nw.spool != nil {
+			nw.dropped.Add(uint64(nw.spool.records))
+			err = errors.Join(err, nw.spool.remove())
+		}
+	})
+
+	return err
+}
+
+// spoolEntry appends the given entry to the spool file, dropping it if the file is full.
+func (nw *NetworkWriter) spoolEntry(buf *bytes.Buffer) {
+	nw.mu.Lock()
+	if nw.spool.append(buf.Bytes()) {
+		nw.spooling.Store(true)
+	} else {
+		nw.dropped.Add(1)
+	}
+	nw.mu.Unlock()
+
+	nw.putBuffer(buf)
+}
+
+// waitForSpace waits until the background goroutine frees some slots, or for a short timeout.
+func (nw *NetworkWriter) waitForSpace() {
+	timer := time.NewTimer(blockedWriteTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-nw.spaceCh:
+	case <-timer.C:
+		runtime.Gosched()
+	}
+}
+
+// wake notifies the background goroutine that new entries are available without ever blocking.
+func (nw *NetworkWriter) wake() {
+	select {
+	case nw.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// loop is the background goroutine sending the queued entries and reconnecting to the log collector,
+// until the writer is closed.
+func (nw *NetworkWriter) loop() {
+	defer nw.loopWg.Done()
+
+	// The backoff is reset by a successful send only, as datagram connections are established even when
+	// nobody is listening
+	backoff := nw.config.MinBackoff
+	var batch []*bytes.Buffer
+
+	for {
+		if nw.conn == nil {
+			if err := nw.connect(); err != nil {
+				if !nw.sleep(backoff) {
+					nw.dropBatch(batch)
+					return
+				}
+
+				backoff = min(backoff*2, nw.config.MaxBackoff)
+				continue
+			}
+		}
+
+		if len(batch) == 0 {
+			batch = nw.nextBatch(batch)
+		}
+
+		if len(batch) == 0 {
+			select {
+			case <-nw.wakeCh:
+				continue
+			case <-nw.doneCh:
+				// The entries queued since the last batch are sent before returning
+				if batch = nw.nextBatch(batch); len(batch) == 0 {
+					return
+				}
+			}
+		}
+
+		sent, err := nw.send(batch)
+		for _, buf := range batch[:sent] {
+			nw.putBuffer(buf)
+		}
+
+		nw.inFlight.Add(-int64(sent))
+		batch = batch[:copy(batch, batch[sent:])]
+
+		if err == nil {
+			backoff = nw.config.MinBackoff
+			continue
+		}
+
+		_, _ = os.Stderr.Write([]byte("tiny-logger-err: " + err.Error() + "\n"))
+		_ = nw.conn.Close()
+		nw.conn = nil
+		nw.connected.Store(false)
+
+		if !nw.sleep(backoff) {
+			nw.dropBatch(batch)
+			return
+		}
+
+		backoff = min(backoff*2, nw.config.MaxBackoff)
+	}
+}
+
+// connect establishes the connection to the log collector.
+func (nw *NetworkWriter) connect() error {
+	conn, err := dialNetwork(
+		&net.Dialer{Timeout: nw.config.DialTimeout}, nw.config.TLSConfig, nw.config.Network, nw.config.Address,
+	)
+	if err != nil {
+		return err
+	}
+
+	nw.conn = conn
+	nw.connected.Store(true)
+
+	return nil
+}
+
+// sleep waits for the given backoff, with a random jitter of up to half of it, returning false if the writer
+// is closed in the meantime.
+func (nw *NetworkWriter) sleep(backoff time.Duration) bool {
+	timer := time.NewTimer(backoff/2 + rand.N(backoff/2+1))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-nw.doneCh:
+		return false
+	}
+}
+
+// nextBatch appends to the given batch the next entries to be sent: the ones queued in memory first,
+// which are older than the spooled ones.
+func (nw *NetworkWriter) nextBatch(batch []*bytes.Buffer) []*bytes.Buffer {
+	for len(batch) < nw.config.BatchSize {
+		buf, ok := nw.ring.Pop()
+		if !ok {
+			break
+		}
+
+		batch = append(batch, buf)
+		nw.inFlight.Add(1)
+
+		select {
+		case nw.spaceCh <- struct{}{}:
+		default:
+		}
+	}
+
+	if len(batch) > 0 || !nw.spooling.Load() {
+		return batch
+	}
+
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+
+	spooled := nw.spool.records
+	batch, err := nw.spool.read(batch, nw.config.BatchSize, &nw.bufferPool)
+	nw.inFlight.Add(int64(spooled - nw.spool.records))
+	if err != nil {
+		_, _ = os.Stderr.Write([]byte("tiny-logger-err: " + err.Error() + "\n"))
+	}
+
+	if nw.spool.records == 0 {
+		if err = nw.spool.reset(); err != nil {
+			_, _ = os.Stderr.Write([]byte("tiny-logger-err: " + err.Error() + "\n"))
+		}
+
+		nw.spooling.Store(false)
+	}
+
+	return batch
+}
+
+// send writes the given batch to the current connection, returning the number of entries written or dropped
+// before the first error.
+// On the stream networks a failed batch is sent again entirely, as the entries received by the collector are
+// unknown, while on the datagram ones the entries too large to be ever sent are dropped.
+func (nw *NetworkWriter) send(batch []*bytes.Buffer) (int, error) {
+	_ = nw.conn.SetWriteDeadline(time.Now().Add(nw.config.WriteTimeout))
+
+	if nw.datagram {
+		for i, buf := range batch {
+			if _, err := nw.conn.Write(buf.Bytes()); err != nil {
+				if !isDatagramTooLarge(err) {
+					return i, err
+				}
+
+				nw.dropped.Add(1)
+				_, _ = os.Stderr.Write([]byte("tiny-logger-err: " + err.Error() + "\n"))
+			}
+		}
+
+		return len(batch), nil
+	}
+
+	buffers := make(net.Buffers, len(batch))
+	for i, buf := range batch {
+		buffers[i] = buf.Bytes()
+	}
+
+	if _, err := buffers.WriteTo(nw.conn); err != nil {
+		return 0, err
+	}
+
+	return len(batch), nil
+}
+
+// dropBatch counts the entries of the given batch as dropped.
+func (nw *NetworkWriter) dropBatch(batch []*bytes.Buffer) {
+	nw.inFlight.Add(-int64(len(batch)))
+
+	for _, buf := range batch {
+		nw.dropped.Add(1)
+		nw.putBuffer(buf)
+	}
+}
+
+// putBuffer puts the given bytes buffer back to the pool.
+func (nw *NetworkWriter) putBuffer(buf *bytes.Buffer) {
+	buf.Reset()
+	nw.bufferPool.Put(buf)
+}
+
+// spoolFile is a file based FIFO queue of entries, each one preceded by its length.
+type spoolFile struct {
+	file     *os.File
+	maxSize  int64
+	readOff  int64
+	writeOff int64
+	records  int
+}
+
+// append appends the given entry to the file, returning false if it does not fit or cannot be written.
+func (sf *spoolFile) append(p []byte) bool {
+	recordLen := int64(spoolRecordHeaderLen + len(p))
+	if sf.writeOff+recordLen > sf.maxSize {
+		return false
+	}
+
+	record := make([]byte, 0, recordLen)
+	record = binary.LittleEndian.AppendUint32(record, uint32(len(p)))
+	record = append(record, p...)
+
+	if _, err := sf.file.WriteAt(record, sf.writeOff); err != nil {
+		_, _ = os.Stderr.Write([]byte("tiny-logger-err: " + err.Error() + "\n"))
+		return false
+	}
+
+	sf.writeOff += recordLen
+	sf.records++
+
+	return true
+}
+
+// read appends to the given batch up to batchSize entries read from the file, in pooled buffers.
+func (sf *spoolFile) read(batch []*bytes.Buffer, batchSize int, pool *sync.Pool) ([]*bytes.Buffer, error) {
+	var header [spoolRecordHeaderLen]byte
+
+	for len(batch) < batchSize && sf.records > 0 {
+		if _, err := sf.file.ReadAt(header[:], sf.readOff); err != nil {
+			sf.records = 0
+			return batch, err
+		}
+
+		recordLen := int64(binary.LittleEndian.Uint32(header[:]))
+		buf := pool.Get().(*bytes.Buffer)
+
+		if _, err := buf.ReadFrom(io.NewSectionReader(sf.file, sf.readOff+spoolRecordHeaderLen, recordLen)); err != nil {
+			sf.records = 0
+			return batch, err
+		}
+
+		sf.readOff += spoolRecordHeaderLen + recordLen
+		sf.records--
+		batch = append(batch, buf)
+	}
+
+	return batch, nil
+}
+
+// reset truncates the file once all its entries have been read.
+func (sf *spoolFile) reset() error {
+	sf.readOff, sf.writeOff, sf.records = 0, 0, 0
+
+	return sf.file.Truncate(0)
+}
+
+// remove closes and removes the file.
+func (sf *spoolFile) remove() error {
+	return errors.Join(sf.file.Close(), os.Remove(sf.file.Name()))
+}
+
+// isDatagramNetwork returns true if the given network is a datagram one.
+func isDatagramNetwork(network string) bool {
+	switch network {
+	case "udp", "udp4", "udp6", "unixgram":
+		return true
+	}
+
+	return false
+}
+
+// NewNetworkWriter initializes a new NetworkWriter and starts its background goroutine, which connects to the
+// log collector described by the given configuration.
+// An error is returned if the configuration is invalid or the spool file cannot be created, while the connection
+// failures are reported on stderr and retried in the background.
+func NewNetworkWriter(config NetworkConfig) (*NetworkWriter, error) {
+	datagram := isDatagramNetwork(config.Network)
+	if datagram && config.TLSConfig != nil {
+		return nil, ErrNetworkTLSDatagram
+	}
+
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = defaultNetworkDialTimeout
+	}
+
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = defaultNetworkWriteTimeout
+	}
+
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = defaultNetworkMinBackoff
+	}
+
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = max(defaultNetworkMaxBackoff, config.MinBackoff)
+	}
+
+	if config.Capacity <= 0 {
+		config.Capacity = defaultNetworkCapacity
+	}
+
+	// The ring buffer needs at least two slots to tell the free ones from the filled ones
+	config.Capacity = max(config.Capacity, 2)
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultNetworkBatchSize
+	}
+
+	if config.MaxSpoolSize <= 0 {
+		config.MaxSpoolSize = defaultMaxSpoolSize
+	}
+
+	networkWriter := &NetworkWriter{
+		config:   config,
+		datagram: datagram,
+		ring:     services.NewRingBuffer[*bytes.Buffer](config.Capacity),
+		wakeCh:   make(chan struct{}, 1),
+		spaceCh:  make(chan struct{}, 1),
+		doneCh:   make(chan struct{}),
+	}
+	networkWriter.bufferPool = sync.Pool{
+		New: func() any {
+			return new(bytes.Buffer)
+		},
+	}
+
+	if config.SpoolDir != "" {
+		file, err := os.CreateTemp(config.SpoolDir, "tiny-logger-spool-*")
+		if err != nil {
+			return nil, err
+		}
+
+		networkWriter.spool = &spoolFile{file: file, maxSize: config.MaxSpoolSize}
+	}
+
+	networkWriter.loopWg.Add(1)
+	go networkWriter.loop()
+
+	return networkWriter, nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package sinks
+
+// isDatagramTooLarge always returns false, since the oversized datagrams are not detected on this platform.
+func isDatagramTooLarge(_ error) bool {
+	return false
+}
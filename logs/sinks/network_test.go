@@ -0,0 +1,427 @@
+package sinks
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readLines reads the given number of lines from the given connection, failing the test after a second.
+func readLines(t *testing.T, conn net.Conn, count int) []string {
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	scanner := bufio.NewScanner(conn)
+	lines := make([]string, 0, count)
+
+	for len(lines) < count && scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	assert.NoError(t, scanner.Err())
+
+	return lines
+}
+
+// reserveAddress returns a local TCP address nobody is listening on.
+func reserveAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := listener.Addr().String()
+	assert.NoError(t, listener.Close())
+
+	return address
+}
+
+// writeLines writes the lines from 0 to count-1 to the given NetworkWriter.
+func writeLines(t *testing.T, networkWriter *NetworkWriter, count int) []string {
+	lines := make([]string, count)
+	for i := range lines {
+		lines[i] = "entry " + strconv.Itoa(i)
+		n, err := networkWriter.Write([]byte(lines[i] + "\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, len(lines[i])+1, n)
+	}
+
+	return lines
+}
+
+func TestNetworkWriter_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	networkWriter, err := NewNetworkWriter(NetworkConfig{Network: "tcp", Address: listener.Addr().String(), BatchSize: 16})
+	assert.NoError(t, err)
+	defer networkWriter.Close()
+
+	conn := acceptConn(t, listener)
+	expected := writeLines(t, networkWriter, 500)
+
+	assert.Equal(t, expected, readLines(t, conn, 500))
+	assert.True(t, networkWriter.Connected())
+	assert.Eventually(t, func() bool { return networkWriter.Pending() == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, uint64(0), networkWriter.Dropped())
+}
+
+func TestNetworkWriter_UDP(t *testing.T) {
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer packetConn.Close()
+
+	networkWriter, err := NewNetworkWriter(NetworkConfig{Network: "udp", Address: packetConn.LocalAddr().String()})
+	assert.NoError(t, err)
+	defer networkWriter.Close()
+
+	// Every entry is sent as its own datagram, even when batched
+	for _, expected := range writeLines(t, networkWriter, 3) {
+		assert.Equal(t, expected+"\n", readPacket(t, packetConn))
+	}
+}
+
+func TestNetworkWriter_UDP_TooLarge(t *testing.T) {
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer packetConn.Close()
+
+	networkWriter, err := NewNetworkWriter(NetworkConfig{Network: "udp", Address: packetConn.LocalAddr().String()})
+	assert.NoError(t, err)
+	defer networkWriter.Close()
+
+	// The entry exceeding the maximum datagram size is dropped instead of being retried forever
+	_, err = networkWriter.Write(bytes.Repeat([]byte{'x'}, 70000))
+	assert.NoError(t, err)
+	_, err = networkWriter.Write([]byte("after\n"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "after\n", readPacket(t, packetConn))
+	assert.Equal(t, uint64(1), networkWriter.Dropped())
+	assert.Eventually(t, func() bool { return networkWriter.Pending() == 0 }, time.Second, time.Millisecond)
+}
+
+// failingConn is a net.Conn whose writes always fail.
+type failingConn struct {
+	net.Conn
+}
+
+func (c failingConn) Write([]byte) (int, error) {
+	return 0, errors.New("network unreachable")
+}
+
+func TestNetworkWriter_SendFailureBackoff(t *testing.T) {
+	var dials atomic.Int32
+	originalDial := dialNetwork
+	dialNetwork = func(*net.Dialer, *tls.Config, string, string) (net.Conn, error) {
+		dials.Add(1)
+		conn, _ := net.Pipe()
+		return failingConn{conn}, nil
+	}
+	defer func() { dialNetwork = originalDial }()
+
+	networkWriter, err := NewNetworkWriter(
+		NetworkConfig{Network: "udp", Address: "127.0.0.1:514", MinBackoff: 20 * time.Millisecond, MaxBackoff: time.Second},
+	)
+	assert.NoError(t, err)
+
+	// Every connection succeeds, but the backoff keeps growing as long as the sends fail
+	_, err = networkWriter.Write([]byte("entry\n"))
+	assert.NoError(t, err)
+	time.Sleep(300 * time.Millisecond)
+
+	assert.Less(t, dials.Load(), int32(10))
+	assert.Equal(t, 1, networkWriter.Pending())
+	assert.Equal(t, uint64(0), networkWriter.Dropped())
+
+	assert.NoError(t, networkWriter.Close())
+	assert.Equal(t, 0, networkWriter.Pending())
+	assert.Equal(t, uint64(1), networkWriter.Dropped())
+}
+
+func TestNetworkWriter_Unix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "collector.sock")
+	listener, err := net.Listen("unix", path)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	networkWriter, err := NewNetworkWriter(NetworkConfig{Network: "unix", Address: path})
+	assert.NoError(t, err)
+	defer networkWriter.Close()
+
+	conn := acceptConn(t, listener)
+	expected := writeLines(t, networkWriter, 10)
+	assert.Equal(t, expected, readLines(t, conn, 10))
+}
+
+func TestNetworkWriter_Reconnection(t *testing.T) {
+	address := reserveAddress(t)
+
+	networkWriter, err := NewNetworkWriter(
+		NetworkConfig{Network: "tcp", Address: address, MinBackoff: 5 * time.Millisecond, MaxBackoff: 20 * time.Millisecond},
+	)
+	assert.NoError(t, err)
+	defer networkWriter.Close()
+
+	// The entries are queued while the collector is unreachable
+	expected := writeLines(t, networkWriter, 5)
+	assert.False(t, networkWriter.Connected())
+	assert.Equal(t, 5, networkWriter.Pending())
+
+	listener, err := net.Listen("tcp", address)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	conn := acceptConn(t, listener)
+	assert.Equal(t, expected, readLines(t, conn, 5))
+
+	// The collector restarts: the writes keep failing until the reconnection, then they are delivered again
+	assert.NoError(t, conn.Close())
+	connCh := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			connCh <- conn
+		}
+	}()
+
+	assert.Eventually(t, func() bool {
+		_, _ = networkWriter.Write([]byte("probe\n"))
+		select {
+		case conn = <-connCh:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, 5*time.Millisecond)
+	defer conn.Close()
+
+	_, err = networkWriter.Write([]byte("after restart\n"))
+	assert.NoError(t, err)
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() && scanner.Text() == "probe" {
+	}
+
+	assert.Equal(t, "after restart", scanner.Text())
+}
+
+func TestNetworkWriter_DropNewestOnOverflow(t *testing.T) {
+	networkWriter, err := NewNetworkWriter(
+		NetworkConfig{Network: "tcp", Address: reserveAddress(t), Capacity: 4, OverflowPolicy: DropNewestOnOverflow},
+	)
+	assert.NoError(t, err)
+
+	writeLines(t, networkWriter, 10)
+	assert.Equal(t, 4, networkWriter.Pending())
+	assert.Equal(t, uint64(6), networkWriter.Dropped())
+
+	// The entries that cannot be sent are dropped on Close
+	assert.NoError(t, networkWriter.Close())
+	assert.Equal(t, uint64(10), networkWriter.Dropped())
+}
+
+func TestNetworkWriter_DropOldestOnOverflow(t *testing.T) {
+	address := reserveAddress(t)
+
+	networkWriter, err := NewNetworkWriter(NetworkConfig{
+		Network:        "tcp",
+		Address:        address,
+		Capacity:       4,
+		OverflowPolicy: DropOldestOnOverflow,
+		MinBackoff:     5 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+	defer networkWriter.Close()
+
+	expected := writeLines(t, networkWriter, 10)
+	assert.Equal(t, 4, networkWriter.Pending())
+	assert.Equal(t, uint64(6), networkWriter.Dropped())
+
+	listener, err := net.Listen("tcp", address)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	assert.Equal(t, expected[6:], readLines(t, acceptConn(t, listener), 4))
+}
+
+func TestNetworkWriter_Spool(t *testing.T) {
+	address := reserveAddress(t)
+	spoolDir := t.TempDir()
+
+	networkWriter, err := NewNetworkWriter(NetworkConfig{
+		Network:    "tcp",
+		Address:    address,
+		Capacity:   2,
+		SpoolDir:   spoolDir,
+		MinBackoff: 5 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	// The entries exceeding the capacity are spilled to disk instead of being dropped
+	expected := writeLines(t, networkWriter, 50)
+	assert.Equal(t, 50, networkWriter.Pending())
+	assert.Equal(t, uint64(0), networkWriter.Dropped())
+	assert.Len(t, listDir(t, spoolDir), 1)
+
+	listener, err := net.Listen("tcp", address)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	conn := acceptConn(t, listener)
+	assert.Equal(t, expected, readLines(t, conn, 50))
+
+	// Once the spool is drained, the entries are queued in memory again
+	expected = writeLines(t, networkWriter, 2)
+	assert.Equal(t, expected, readLines(t, conn, 2))
+
+	assert.NoError(t, networkWriter.Close())
+	assert.Empty(t, listDir(t, spoolDir))
+	assert.Equal(t, uint64(0), networkWriter.Dropped())
+}
+
+func TestNetworkWriter_Spool_MaxSize(t *testing.T) {
+	spoolDir := t.TempDir()
+
+	// Every "entry N\n" record takes 12 bytes in the spool file
+	networkWriter, err := NewNetworkWriter(NetworkConfig{
+		Network:      "tcp",
+		Address:      reserveAddress(t),
+		Capacity:     2,
+		SpoolDir:     spoolDir,
+		MaxSpoolSize: 36,
+	})
+	assert.NoError(t, err)
+
+	writeLines(t, networkWriter, 10)
+	assert.Equal(t, 5, networkWriter.Pending())
+	assert.Equal(t, uint64(5), networkWriter.Dropped())
+
+	assert.NoError(t, networkWriter.Close())
+	assert.Equal(t, uint64(10), networkWriter.Dropped())
+	assert.Empty(t, listDir(t, spoolDir))
+}
+
+func TestNetworkWriter_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(nil)
+	defer server.Close()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", server.TLS)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	networkWriter, err := NewNetworkWriter(NetworkConfig{
+		Network:   "tcp",
+		Address:   listener.Addr().String(),
+		TLSConfig: &tls.Config{RootCAs: rootCAs, ServerName: "example.com"},
+	})
+	assert.NoError(t, err)
+	defer networkWriter.Close()
+
+	conn := acceptConn(t, listener)
+	expected := writeLines(t, networkWriter, 20)
+	assert.Equal(t, expected, readLines(t, conn, 20))
+}
+
+func TestNetworkWriter_Close(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	networkWriter, err := NewNetworkWriter(NetworkConfig{Network: "tcp", Address: listener.Addr().String()})
+	assert.NoError(t, err)
+
+	conn := acceptConn(t, listener)
+	expected := writeLines(t, networkWriter, 100)
+
+	// The pending entries are sent before closing the connection
+	assert.NoError(t, networkWriter.Close())
+	assert.NoError(t, networkWriter.Close())
+	assert.Equal(t, expected, readLines(t, conn, 100))
+	assert.False(t, networkWriter.Connected())
+
+	_, err = networkWriter.Write([]byte("closed\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestNetworkWriter_Close_IdleLoop(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	networkWriter, err := NewNetworkWriter(NetworkConfig{Network: "tcp", Address: listener.Addr().String()})
+	assert.NoError(t, err)
+
+	conn := acceptConn(t, listener)
+	expected := writeLines(t, networkWriter, 1)
+	assert.Equal(t, expected, readLines(t, conn, 1))
+	assert.Eventually(t, func() bool { return networkWriter.Pending() == 0 }, time.Second, time.Millisecond)
+
+	// An entry queued after the last batch, without waking the idle background goroutine, is sent on Close
+	buf := networkWriter.bufferPool.Get().(*bytes.Buffer)
+	buf.WriteString("late entry\n")
+	assert.True(t, networkWriter.ring.Push(buf))
+
+	assert.NoError(t, networkWriter.Close())
+	assert.Equal(t, []string{"late entry"}, readLines(t, conn, 1))
+	assert.Equal(t, uint64(0), networkWriter.Dropped())
+}
+
+func TestNetworkWriter_Close_ConcurrentWrites(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	networkWriter, err := NewNetworkWriter(NetworkConfig{Network: "tcp", Address: listener.Addr().String()})
+	assert.NoError(t, err)
+
+	conn := acceptConn(t, listener)
+	var accepted atomic.Int64
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				if _, err := networkWriter.Write([]byte("entry\n")); err != nil {
+					return
+				}
+
+				accepted.Add(1)
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, networkWriter.Close())
+	wg.Wait()
+
+	// Every accepted entry is either sent or counted as dropped
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	received, _ := io.ReadAll(conn)
+	assert.Equal(t, accepted.Load(), int64(bytes.Count(received, []byte("\n")))+int64(networkWriter.Dropped()))
+	assert.Zero(t, networkWriter.Pending())
+}
+
+func TestNetworkWriter_Errors(t *testing.T) {
+	_, err := NewNetworkWriter(NetworkConfig{Network: "udp", Address: "127.0.0.1:514", TLSConfig: &tls.Config{}})
+	assert.ErrorIs(t, err, ErrNetworkTLSDatagram)
+
+	_, err = NewNetworkWriter(NetworkConfig{Network: "tcp", Address: "127.0.0.1:514", SpoolDir: filepath.Join(t.TempDir(), "missing")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package sinks
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isDatagramTooLarge returns true if the given error reports a datagram exceeding the maximum size of the network.
+func isDatagramTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE)
+}
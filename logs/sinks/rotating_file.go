@@ -0,0 +1,357 @@
+package sinks
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+)
+
+// renameFile renames the rotated files, replaceable by the tests to simulate failures.
+var renameFile = os.Rename
+
+// RotationInterval is the Enum representing how often a RotatingFile rotates, regardless of its size.
+type RotationInterval int8
+
+const (
+	NoRotation     RotationInterval = 0
+	HourlyRotation RotationInterval = 1
+	DailyRotation  RotationInterval = 2
+)
+
+// RotatingFileConfig holds the RotatingFile settings.
+// Zero values disable the related feature: no size rotation, no time rotation, no backups cleanup.
+type RotatingFileConfig struct {
+	// Path is the path of the file logs are currently written to.
+	Path string
+	// MaxSize is the maximum size in bytes the file can reach before being rotated.
+	MaxSize int64
+	// Interval is the time interval the file is rotated on.
+	Interval RotationInterval
+	// MaxBackups is the maximum number of rotated files to keep.
+	MaxBackups int
+	// MaxAge is the maximum age of the rotated files to keep.
+	MaxAge time.Duration
+	// Compress enables the gzip compression of the rotated files.
+	Compress bool
+}
+
+// RotatingFile is an io.WriteCloser writing logs to a file that is rotated by size and/or on a time interval.
+// Rotated files are renamed using the rotation timestamp (e.g. 'app-2024-03-11T18-35-43.000.log'), while
+// compression and cleanup of old files happen in the background.
+type RotatingFile struct {
+	config       RotatingFileConfig
+	mu           sync.Mutex
+	millMu       sync.Mutex
+	millWg       sync.WaitGroup
+	file         *os.File
+	closed       bool
+	size         int64
+	nextRotation time.Time
+	timeNow      func() time.Time
+}
+
+// Write writes the given bytes to the current file, rotating it first if the size or time limits are reached.
+// A failed rotation is reported to stderr and the bytes are written to the file reopened at the configured path,
+// so that a rotation failure never stops the logging: the rotation is retried by the next writes.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+
+	if r.file == nil {
+		// The file could not be reopened after a failed rotation
+		if err := r.openFile(); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			if r.file == nil {
+				return 0, err
+			}
+
+			_, _ = os.Stderr.Write([]byte("tiny-logger-err: " + err.Error() + "\n"))
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+
+	return n, err
+}
+
+// Rotate forces the rotation of the current file. If the rotation fails, the logs keep being written
+// to the file at the configured path.
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return os.ErrClosed
+	}
+
+	if r.file == nil {
+		if err := r.openFile(); err != nil {
+			return err
+		}
+	}
+
+	return r.rotate()
+}
+
+// Close closes the current file and waits for any background compression or cleanup to complete.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+	}
+
+	r.file = nil
+	r.closed = true
+	r.millWg.Wait()
+
+	return err
+}
+
+// shouldRotate returns true if writing 'writeLen' more bytes requires the current file to be rotated first.
+// An empty file is never rotated: reaching the rotation time, its next rotation is postponed instead.
+func (r *RotatingFile) shouldRotate(writeLen int64) bool {
+	if now := r.timeNow(); r.config.Interval != NoRotation && !now.Before(r.nextRotation) {
+		if r.size > 0 {
+			return true
+		}
+
+		r.nextRotation = r.computeNextRotation(now)
+	}
+
+	return r.config.MaxSize > 0 && r.size > 0 && r.size+writeLen > r.config.MaxSize
+}
+
+// rotate renames the current file to a timestamped backup, opens a new one and starts the background mill.
+// The file is closed before being renamed, as required on Windows: if the rotation fails, the file at the
+// configured path is reopened, leaving r.file nil only if it cannot be reopened either.
+func (r *RotatingFile) rotate() error {
+	closeErr := r.file.Close()
+	r.file = nil
+
+	now := r.timeNow()
+	backupPath := r.backupPath(now)
+	if err := renameFile(r.config.Path, backupPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Join(err, closeErr, r.openFile())
+	}
+
+	if err := r.openFile(); err != nil {
+		return err
+	}
+
+	r.millWg.Add(1)
+	go r.mill(backupPath, now)
+
+	return closeErr
+}
+
+// openFile opens (or creates) the configured file in append mode and computes the next time rotation.
+func (r *RotatingFile) openFile() error {
+	if err := os.MkdirAll(filepath.Dir(r.config.Path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(r.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	r.nextRotation = r.computeNextRotation(r.timeNow())
+
+	return nil
+}
+
+// computeNextRotation returns the beginning of the next rotation interval after 'now'.
+func (r *RotatingFile) computeNextRotation(now time.Time) time.Time {
+	switch r.config.Interval {
+	case HourlyRotation:
+		return time.Date(now.Year(), now.Month(), now.Day(), now.Hour()+1, 0, 0, 0, now.Location())
+	case DailyRotation:
+		return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	}
+
+	return time.Time{}
+}
+
+// backupPath returns a non-existing backup file path for the given rotation time.
+func (r *RotatingFile) backupPath(t time.Time) string {
+	prefix, ext := r.backupPrefixAndExt()
+	base := prefix + t.Format(backupTimeFormat)
+	backupPath := base + ext
+
+	for i := 1; r.fileExists(backupPath) || r.fileExists(backupPath+compressSuffix); i++ {
+		backupPath = base + "-" + strconv.Itoa(i) + ext
+	}
+
+	return backupPath
+}
+
+// backupPrefixAndExt returns the common prefix and the extension of the backup files.
+func (r *RotatingFile) backupPrefixAndExt() (string, string) {
+	ext := filepath.Ext(r.config.Path)
+
+	return strings.TrimSuffix(r.config.Path, ext) + "-", ext
+}
+
+// fileExists returns true if the given path exists.
+func (r *RotatingFile) fileExists(path string) bool {
+	_, err := os.Stat(path)
+
+	return err == nil
+}
+
+// mill compresses the given backup file when required and removes the backups exceeding the configured limits.
+func (r *RotatingFile) mill(backupPath string, rotationTime time.Time) {
+	defer r.millWg.Done()
+
+	r.millMu.Lock()
+	defer r.millMu.Unlock()
+
+	if r.config.Compress {
+		if err := compressFile(backupPath); err != nil {
+			_, _ = os.Stderr.Write([]byte("tiny-logger-err: " + err.Error() + "\n"))
+		}
+	}
+
+	r.removeOldBackups(rotationTime)
+}
+
+// removeOldBackups removes the backups exceeding MaxBackups or older than MaxAge at the given time.
+func (r *RotatingFile) removeOldBackups(now time.Time) {
+	if r.config.MaxBackups <= 0 && r.config.MaxAge <= 0 {
+		return
+	}
+
+	backups := r.listBackups()
+	cutoff := now.Add(-r.config.MaxAge)
+
+	for i, b := range backups {
+		if (r.config.MaxBackups > 0 && i >= r.config.MaxBackups) ||
+			(r.config.MaxAge > 0 && b.timestamp.Before(cutoff)) {
+			_ = os.Remove(b.path)
+		}
+	}
+}
+
+type backupFile struct {
+	path      string
+	timestamp time.Time
+}
+
+// listBackups returns the existing backups, newest first.
+func (r *RotatingFile) listBackups() []backupFile {
+	prefix, ext := r.backupPrefixAndExt()
+	entries, err := os.ReadDir(filepath.Dir(r.config.Path))
+	if err != nil {
+		return nil
+	}
+
+	var backups []backupFile
+	namePrefix := filepath.Base(prefix)
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, namePrefix) {
+			continue
+		}
+
+		tsStr := strings.TrimSuffix(strings.TrimSuffix(name[len(namePrefix):], compressSuffix), ext)
+		if len(tsStr) < len(backupTimeFormat) {
+			continue
+		}
+
+		ts, err := time.ParseInLocation(backupTimeFormat, tsStr[:len(backupTimeFormat)], time.Local)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, backupFile{path: filepath.Join(filepath.Dir(r.config.Path), name), timestamp: ts})
+	}
+
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].timestamp.After(backups[j].timestamp)
+	})
+
+	return backups
+}
+
+// compressFile gzip-compresses the given file into 'path.gz' and removes the original.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gzWriter := gzip.NewWriter(dst)
+	if _, err = io.Copy(gzWriter, src); err == nil {
+		err = gzWriter.Close()
+	}
+
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(path + compressSuffix)
+		return err
+	}
+
+	_ = src.Close()
+	return os.Remove(path)
+}
+
+// NewRotatingFile opens the configured file and returns a new RotatingFile instance.
+func NewRotatingFile(config RotatingFileConfig) (*RotatingFile, error) {
+	if config.Path == "" {
+		return nil, errors.New("tiny-logger: rotating file path is empty")
+	}
+
+	rotatingFile := &RotatingFile{config: config, timeNow: time.Now}
+	if err := rotatingFile.openFile(); err != nil {
+		return nil, err
+	}
+
+	return rotatingFile, nil
+}
@@ -0,0 +1,221 @@
+package sinks
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRotatingFile returns a RotatingFile whose clock is controlled by the returned pointer.
+func newTestRotatingFile(t *testing.T, config RotatingFileConfig) (*RotatingFile, *time.Time) {
+	now := time.Date(2024, 3, 11, 18, 35, 43, 0, time.Local)
+	rotatingFile := &RotatingFile{config: config, timeNow: func() time.Time { return now }}
+	assert.NoError(t, rotatingFile.openFile())
+
+	return rotatingFile, &now
+}
+
+// listDir returns the names of the files contained in the given directory.
+func listDir(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	return names
+}
+
+func TestNewRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	rotatingFile, err := NewRotatingFile(RotatingFileConfig{Path: path})
+	assert.NoError(t, err)
+
+	_, err = rotatingFile.Write([]byte("first line\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, rotatingFile.Close())
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "first line\n", string(content))
+
+	_, err = NewRotatingFile(RotatingFileConfig{})
+	assert.Error(t, err)
+}
+
+func TestRotatingFile_Write_Closed(t *testing.T) {
+	rotatingFile, err := NewRotatingFile(RotatingFileConfig{Path: filepath.Join(t.TempDir(), "app.log")})
+	assert.NoError(t, err)
+	assert.NoError(t, rotatingFile.Close())
+	assert.NoError(t, rotatingFile.Close())
+
+	_, err = rotatingFile.Write([]byte("line\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+	assert.ErrorIs(t, rotatingFile.Rotate(), os.ErrClosed)
+}
+
+func TestRotatingFile_SizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	rotatingFile, now := newTestRotatingFile(t, RotatingFileConfig{Path: filepath.Join(dir, "app.log"), MaxSize: 10})
+
+	_, _ = rotatingFile.Write([]byte("123456\n"))
+	_, _ = rotatingFile.Write([]byte("789\n"))
+	assert.Len(t, listDir(t, dir), 2)
+
+	*now = now.Add(time.Second)
+	_, _ = rotatingFile.Write([]byte("abcdefg\n"))
+	assert.NoError(t, rotatingFile.Close())
+
+	assert.ElementsMatch(
+		t,
+		[]string{"app.log", "app-2024-03-11T18-35-43.000.log", "app-2024-03-11T18-35-44.000.log"},
+		listDir(t, dir),
+	)
+
+	content, _ := os.ReadFile(filepath.Join(dir, "app-2024-03-11T18-35-43.000.log"))
+	assert.Equal(t, "123456\n", string(content))
+	content, _ = os.ReadFile(filepath.Join(dir, "app-2024-03-11T18-35-44.000.log"))
+	assert.Equal(t, "789\n", string(content))
+	content, _ = os.ReadFile(filepath.Join(dir, "app.log"))
+	assert.Equal(t, "abcdefg\n", string(content))
+}
+
+func TestRotatingFile_TimeRotation(t *testing.T) {
+	dir := t.TempDir()
+	rotatingFile, now := newTestRotatingFile(
+		t,
+		RotatingFileConfig{Path: filepath.Join(dir, "app.log"), Interval: HourlyRotation},
+	)
+
+	_, _ = rotatingFile.Write([]byte("first\n"))
+	*now = now.Add(20 * time.Minute)
+	_, _ = rotatingFile.Write([]byte("second\n"))
+	assert.Len(t, listDir(t, dir), 1)
+
+	*now = now.Add(5 * time.Minute)
+	_, _ = rotatingFile.Write([]byte("third\n"))
+	assert.NoError(t, rotatingFile.Close())
+	assert.ElementsMatch(t, []string{"app.log", "app-2024-03-11T19-00-43.000.log"}, listDir(t, dir))
+
+	daily := &RotatingFile{config: RotatingFileConfig{Interval: DailyRotation}}
+	assert.Equal(
+		t,
+		time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC),
+		daily.computeNextRotation(time.Date(2024, 3, 11, 23, 59, 0, 0, time.UTC)),
+	)
+}
+
+func TestRotatingFile_MaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	rotatingFile, now := newTestRotatingFile(t, RotatingFileConfig{Path: filepath.Join(dir, "app.log"), MaxBackups: 2})
+
+	for i := 0; i < 4; i++ {
+		_, _ = rotatingFile.Write([]byte("line\n"))
+		assert.NoError(t, rotatingFile.Rotate())
+		*now = now.Add(time.Minute)
+	}
+
+	assert.NoError(t, rotatingFile.Close())
+	assert.ElementsMatch(
+		t,
+		[]string{"app.log", "app-2024-03-11T18-37-43.000.log", "app-2024-03-11T18-38-43.000.log"},
+		listDir(t, dir),
+	)
+}
+
+func TestRotatingFile_MaxAge(t *testing.T) {
+	dir := t.TempDir()
+	rotatingFile, now := newTestRotatingFile(t, RotatingFileConfig{Path: filepath.Join(dir, "app.log"), MaxAge: time.Hour})
+
+	assert.NoError(t, rotatingFile.Rotate())
+	rotatingFile.millWg.Wait()
+	*now = now.Add(2 * time.Hour)
+	assert.NoError(t, rotatingFile.Rotate())
+	assert.NoError(t, rotatingFile.Close())
+
+	assert.ElementsMatch(t, []string{"app.log", "app-2024-03-11T20-35-43.000.log"}, listDir(t, dir))
+}
+
+func TestRotatingFile_Compress(t *testing.T) {
+	dir := t.TempDir()
+	rotatingFile, _ := newTestRotatingFile(t, RotatingFileConfig{Path: filepath.Join(dir, "app.log"), Compress: true})
+
+	_, _ = rotatingFile.Write([]byte("compressed line\n"))
+	assert.NoError(t, rotatingFile.Rotate())
+	assert.NoError(t, rotatingFile.Rotate())
+	assert.NoError(t, rotatingFile.Close())
+
+	assert.ElementsMatch(
+		t,
+		[]string{"app.log", "app-2024-03-11T18-35-43.000.log.gz", "app-2024-03-11T18-35-43.000-1.log.gz"},
+		listDir(t, dir),
+	)
+
+	file, err := os.Open(filepath.Join(dir, "app-2024-03-11T18-35-43.000.log.gz"))
+	assert.NoError(t, err)
+	defer file.Close()
+
+	gzReader, err := gzip.NewReader(file)
+	assert.NoError(t, err)
+	content, err := io.ReadAll(gzReader)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "compressed line"))
+}
+
+func TestRotatingFile_FailedRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rotatingFile, now := newTestRotatingFile(t, RotatingFileConfig{Path: path, MaxSize: 10})
+
+	renameErr := errors.New("rename failed")
+	renameFile = func(_, _ string) error { return renameErr }
+	defer func() { renameFile = os.Rename }()
+
+	_, err := rotatingFile.Write([]byte("123456\n"))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, rotatingFile.Rotate(), renameErr)
+
+	// The writes keep going to the reopened file, retrying the rotation
+	_, err = rotatingFile.Write([]byte("789\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app.log"}, listDir(t, dir))
+
+	renameFile = os.Rename
+	*now = now.Add(time.Second)
+	_, err = rotatingFile.Write([]byte("abcdefg\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, rotatingFile.Close())
+
+	content, _ := os.ReadFile(filepath.Join(dir, "app-2024-03-11T18-35-44.000.log"))
+	assert.Equal(t, "123456\n789\n", string(content))
+	content, _ = os.ReadFile(path)
+	assert.Equal(t, "abcdefg\n", string(content))
+}
+
+func TestRotatingFile_TimeRotation_Empty(t *testing.T) {
+	dir := t.TempDir()
+	rotatingFile, now := newTestRotatingFile(
+		t,
+		RotatingFileConfig{Path: filepath.Join(dir, "app.log"), Interval: HourlyRotation},
+	)
+
+	*now = now.Add(time.Hour)
+	_, _ = rotatingFile.Write([]byte("first\n"))
+	*now = now.Add(10 * time.Minute)
+	_, _ = rotatingFile.Write([]byte("second\n"))
+	assert.Equal(t, []string{"app.log"}, listDir(t, dir))
+
+	*now = now.Add(time.Hour)
+	_, _ = rotatingFile.Write([]byte("third\n"))
+	assert.NoError(t, rotatingFile.Close())
+	assert.ElementsMatch(t, []string{"app.log", "app-2024-03-11T20-45-43.000.log"}, listDir(t, dir))
+}
@@ -0,0 +1,195 @@
+package sinks
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultSyslogDialTimeout is the timeout used when SyslogConfig.DialTimeout is not set.
+const defaultSyslogDialTimeout = 5 * time.Second
+
+// syslogLocalAddresses are the paths of the local syslog socket on the supported platforms.
+var syslogLocalAddresses = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// ErrSyslogUnavailable is returned when none of the local syslog sockets can be reached.
+var ErrSyslogUnavailable = errors.New("tiny-logger: the local syslog socket is unavailable")
+
+// SyslogConfig holds the SyslogWriter settings.
+type SyslogConfig struct {
+	// Network is the network of the syslog daemon: "unixgram", "unix", "udp" or "tcp" (including their "4" and "6"
+	// variants). When empty, the entries are sent to the local syslog socket found at Address, or at the default
+	// paths if Address is empty too, as datagrams or as a stream depending on the socket type.
+	Network string
+	// Address is the address of the syslog daemon, e.g. "localhost:514" or "/dev/log".
+	Address string
+	// DialTimeout is the maximum amount of time a (re)connection can take. Defaults to 5 seconds when not set.
+	DialTimeout time.Duration
+	// WriteTimeout, if set, is the maximum amount of time a write can take.
+	WriteTimeout time.Duration
+}
+
+// SyslogWriter is an io.WriteCloser sending every write, expected to be a whole syslog message such as the ones
+// produced by the encoders.SyslogEncoder, to a syslog daemon.
+// The messages are sent as they are over the datagram networks, while they are framed over the stream ones:
+// TCP uses the RFC 6587 octet counting, while the local stream sockets use newline terminated messages,
+// whose inner newlines (multi-line messages, stack traces) are escaped as "#012" like rsyslog does,
+// so that every message is received as a single record.
+// When a write fails, the connection is re-established and the write retried once, unless part of the frame has
+// already been sent: the connection is then dropped along with the message, not to deliver it twice or to
+// misalign the stream. A failed reconnection is retried on the next write.
+type SyslogWriter struct {
+	config  SyslogConfig
+	mu      sync.Mutex
+	conn    net.Conn
+	network string
+	closed  bool
+}
+
+// Write sends the given message to the syslog daemon, reconnecting first if the connection is lost.
+func (sw *SyslogWriter) Write(p []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.closed {
+		return 0, os.ErrClosed
+	}
+
+	frame := sw.frame(p)
+
+	if sw.conn != nil {
+		n, err := sw.send(frame)
+		if err == nil {
+			return len(p), nil
+		}
+
+		if n > 0 {
+			return 0, err
+		}
+	}
+
+	if err := sw.connect(); err != nil {
+		return 0, err
+	}
+
+	if _, err := sw.send(frame); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close closes the connection to the syslog daemon.
+func (sw *SyslogWriter) Close() error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.closed {
+		return nil
+	}
+
+	sw.closed = true
+	if sw.conn == nil {
+		return nil
+	}
+
+	err := sw.conn.Close()
+	sw.conn = nil
+
+	return err
+}
+
+// frame returns the given message framed according to the network type.
+func (sw *SyslogWriter) frame(p []byte) []byte {
+	switch sw.network {
+	case "tcp", "tcp4", "tcp6":
+		frame := make([]byte, 0, len(p)+8)
+		frame = strconv.AppendInt(frame, int64(len(p)), 10)
+		frame = append(frame, ' ')
+
+		return append(frame, p...)
+	case "unix":
+		msg := bytes.TrimSuffix(p, []byte("\n"))
+		if bytes.IndexByte(msg, '\n') >= 0 {
+			msg = bytes.ReplaceAll(msg, []byte("\n"), []byte("#012"))
+		} else if len(msg) < len(p) {
+			return p
+		}
+
+		return append(msg[:len(msg):len(msg)], '\n')
+	}
+
+	return p
+}
+
+// send writes the given frame to the current connection, returning the number of bytes written.
+// The connection is dropped if the write fails, so that a partially written frame is never followed by another one.
+func (sw *SyslogWriter) send(frame []byte) (int, error) {
+	if sw.config.WriteTimeout > 0 {
+		_ = sw.conn.SetWriteDeadline(time.Now().Add(sw.config.WriteTimeout))
+	}
+
+	n, err := sw.conn.Write(frame)
+	if err != nil {
+		_ = sw.conn.Close()
+		sw.conn = nil
+	}
+
+	return n, err
+}
+
+// connect (re)establishes the connection to the syslog daemon.
+func (sw *SyslogWriter) connect() error {
+	if sw.conn != nil {
+		_ = sw.conn.Close()
+		sw.conn = nil
+	}
+
+	dialer := net.Dialer{Timeout: sw.config.DialTimeout}
+
+	if sw.config.Network != "" {
+		conn, err := dialer.Dial(sw.config.Network, sw.config.Address)
+		if err != nil {
+			return err
+		}
+
+		sw.conn, sw.network = conn, sw.config.Network
+		return nil
+	}
+
+	addresses := syslogLocalAddresses
+	if sw.config.Address != "" {
+		addresses = []string{sw.config.Address}
+	}
+
+	for _, address := range addresses {
+		for _, network := range []string{"unixgram", "unix"} {
+			if conn, err := dialer.Dial(network, address); err == nil {
+				sw.conn, sw.network = conn, network
+				return nil
+			}
+		}
+	}
+
+	return ErrSyslogUnavailable
+}
+
+// NewSyslogWriter initializes and returns a new SyslogWriter connected to the syslog daemon
+// described by the given configuration.
+// An error is returned if the connection cannot be established.
+func NewSyslogWriter(config SyslogConfig) (*SyslogWriter, error) {
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = defaultSyslogDialTimeout
+	}
+
+	syslogWriter := &SyslogWriter{config: config}
+	if err := syslogWriter.connect(); err != nil {
+		return nil, err
+	}
+
+	return syslogWriter, nil
+}
@@ -0,0 +1,241 @@
+package sinks
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readPacket reads a single datagram from the given connection.
+func readPacket(t *testing.T, conn net.PacketConn) string {
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+
+	n, _, err := conn.ReadFrom(buf)
+	assert.NoError(t, err)
+
+	return string(buf[:n])
+}
+
+// acceptConn accepts a connection on the given listener, failing the test after a second.
+func acceptConn(t *testing.T, listener net.Listener) net.Conn {
+	connCh := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			connCh <- conn
+		}
+	}()
+
+	select {
+	case conn := <-connCh:
+		t.Cleanup(func() { _ = conn.Close() })
+		return conn
+	case <-time.After(time.Second):
+		t.Fatal("no connection accepted")
+		return nil
+	}
+}
+
+func TestSyslogWriter_UDP(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	syslogWriter, err := NewSyslogWriter(SyslogConfig{Network: "udp", Address: listener.LocalAddr().String()})
+	assert.NoError(t, err)
+	defer syslogWriter.Close()
+
+	n, err := syslogWriter.Write([]byte("<14>1 - - - - - - first message"))
+	assert.NoError(t, err)
+	assert.Equal(t, 31, n)
+	assert.Equal(t, "<14>1 - - - - - - first message", readPacket(t, listener))
+}
+
+func TestSyslogWriter_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	syslogWriter, err := NewSyslogWriter(SyslogConfig{
+		Network:      "tcp",
+		Address:      listener.Addr().String(),
+		WriteTimeout: time.Second,
+	})
+	assert.NoError(t, err)
+	defer syslogWriter.Close()
+
+	conn := acceptConn(t, listener)
+
+	// The messages are octet counted
+	_, err = syslogWriter.Write([]byte("<14>1 - - - - - - first"))
+	assert.NoError(t, err)
+	_, err = syslogWriter.Write([]byte("<14>1 - - - - - - second\nline"))
+	assert.NoError(t, err)
+
+	frame := make([]byte, len("23 <14>1 - - - - - - first29 <14>1 - - - - - - second\nline"))
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = io.ReadFull(conn, frame)
+	assert.NoError(t, err)
+	assert.Equal(t, "23 <14>1 - - - - - - first29 <14>1 - - - - - - second\nline", string(frame))
+}
+
+func TestSyslogWriter_TCP_Reconnection(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	syslogWriter, err := NewSyslogWriter(SyslogConfig{Network: "tcp", Address: listener.Addr().String()})
+	assert.NoError(t, err)
+	defer syslogWriter.Close()
+
+	// The daemon drops the connection: the writes keep failing until the connection is re-established
+	assert.NoError(t, acceptConn(t, listener).Close())
+
+	connCh := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			connCh <- conn
+		}
+	}()
+
+	var conn net.Conn
+	for deadline := time.Now().Add(time.Second); conn == nil && time.Now().Before(deadline); {
+		_, _ = syslogWriter.Write([]byte("lost?"))
+
+		select {
+		case conn = <-connCh:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	if assert.NotNil(t, conn) {
+		defer conn.Close()
+
+		_, err = syslogWriter.Write([]byte("reconnected"))
+		assert.NoError(t, err)
+
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		line, _ := bufio.NewReader(conn).ReadString('d')
+		assert.True(t, strings.HasSuffix(line, "11 reconnected"), line)
+	}
+}
+
+// partialConn is a net.Conn writing only the first half of every write before failing.
+type partialConn struct {
+	net.Conn
+}
+
+func (c partialConn) Write(p []byte) (int, error) {
+	n, _ := c.Conn.Write(p[:len(p)/2])
+
+	return n, errors.New("connection reset")
+}
+
+func TestSyslogWriter_TCP_PartialWrite(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	syslogWriter, err := NewSyslogWriter(SyslogConfig{Network: "tcp", Address: listener.Addr().String()})
+	assert.NoError(t, err)
+	defer syslogWriter.Close()
+
+	conn := acceptConn(t, listener)
+	syslogWriter.conn = partialConn{syslogWriter.conn}
+
+	// The partially written frame is not sent again, and the connection it was written to is closed
+	_, err = syslogWriter.Write([]byte("<14>1 - - - - - - partial"))
+	assert.EqualError(t, err, "connection reset")
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	received, err := io.ReadAll(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, "25 <14>1 - - -", string(received))
+
+	// The next message is sent through a new connection
+	_, err = syslogWriter.Write([]byte("<14>1 - - - - - - next"))
+	assert.NoError(t, err)
+
+	frame := make([]byte, len("22 <14>1 - - - - - - next"))
+	conn = acceptConn(t, listener)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = io.ReadFull(conn, frame)
+	assert.NoError(t, err)
+	assert.Equal(t, "22 <14>1 - - - - - - next", string(frame))
+}
+
+func TestSyslogWriter_Unixgram(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	listener, err := net.ListenPacket("unixgram", path)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	// The local socket type is detected when the network is not set
+	syslogWriter, err := NewSyslogWriter(SyslogConfig{Address: path})
+	assert.NoError(t, err)
+	defer syslogWriter.Close()
+
+	_, err = syslogWriter.Write([]byte("<14>Mar 11 18:35:43 api[42]: local message"))
+	assert.NoError(t, err)
+	assert.Equal(t, "<14>Mar 11 18:35:43 api[42]: local message", readPacket(t, listener))
+}
+
+func TestSyslogWriter_UnixStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	listener, err := net.Listen("unix", path)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	syslogWriter, err := NewSyslogWriter(SyslogConfig{Address: path})
+	assert.NoError(t, err)
+	defer syslogWriter.Close()
+
+	conn := acceptConn(t, listener)
+
+	// The messages are newline terminated
+	_, err = syslogWriter.Write([]byte("first message"))
+	assert.NoError(t, err)
+	_, err = syslogWriter.Write([]byte("second message\n"))
+	assert.NoError(t, err)
+
+	// The inner newlines are escaped, so that multi-line messages are not split into several records
+	_, err = syslogWriter.Write([]byte("third message\nmain.main\n\t/app/main.go:14\n"))
+	assert.NoError(t, err)
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	reader := bufio.NewReader(conn)
+	first, _ := reader.ReadString('\n')
+	second, _ := reader.ReadString('\n')
+	third, _ := reader.ReadString('\n')
+	assert.Equal(t, "first message\n", first)
+	assert.Equal(t, "second message\n", second)
+	assert.Equal(t, "third message#012main.main#012\t/app/main.go:14\n", third)
+}
+
+func TestSyslogWriter_Errors(t *testing.T) {
+	_, err := NewSyslogWriter(SyslogConfig{Address: filepath.Join(t.TempDir(), "missing.sock")})
+	assert.ErrorIs(t, err, ErrSyslogUnavailable)
+
+	_, err = NewSyslogWriter(SyslogConfig{Network: "tcp", Address: "127.0.0.1:0", DialTimeout: time.Second})
+	assert.Error(t, err)
+
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	syslogWriter, err := NewSyslogWriter(SyslogConfig{Network: "udp", Address: listener.LocalAddr().String()})
+	assert.NoError(t, err)
+	assert.NoError(t, syslogWriter.Close())
+	assert.NoError(t, syslogWriter.Close())
+
+	_, err = syslogWriter.Write([]byte("message"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
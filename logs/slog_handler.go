@@ -0,0 +1,202 @@
+package logs
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+const averageSlogAttrsNum = 8
+
+var slogArgsPool = sync.Pool{
+	New: func() any {
+		args := make([]any, 0, 1+2*averageSlogAttrsNum)
+		return &args
+	},
+}
+
+// groupOrAttrs holds either a group name or a list of attributes added through
+// SlogHandler.WithGroup and SlogHandler.WithAttrs.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// SlogHandler is a log/slog Handler writing the records through a Logger and its encoders.
+// Record attributes become the entry extras, while slog groups are rendered as nested objects.
+type SlogHandler struct {
+	logger *Logger
+	goas   []groupOrAttrs
+}
+
+// Enabled reports whether the Logger, or any of its destinations, allows the given slog level.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	lvl, _ := slogLvlToLogLvl(level)
+
+	return h.logger.isLvlEnabled(lvl)
+}
+
+// Handle writes the given record through the Logger encoders.
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	lvl, lvlName := slogLvlToLogLvl(r.Level)
+	c := h.logger.loadConfigs()
+
+	// The log level overrides are resolved against the record caller
+	loggerLvl := c.loggerLvlAt(r.PC)
+	if loggerLvl < lvl && c.destinationsLvl < lvl {
+		return nil
+	}
+
+	// Records with a zero time must not report any date or time
+	if r.Time.IsZero() && (c.dateEnabled || c.timeEnabled) {
+		c = c.clone()
+		c.dateEnabled = false
+		c.timeEnabled = false
+	}
+
+	argsPtr := slogArgsPool.Get().(*[]any)
+	args := append((*argsPtr)[:0], r.Message)
+	args = h.appendKeyVals(args, h.goas, r)
+
+	outType := lvlOutputType(lvl)
+
+	if (c.deduplicator != nil && c.deduplicator.isDuplicate(h.logger, c, r.PC, lvl, lvlName, outType, args)) ||
+		(c.sampler != nil && c.isSampledOut(h.logger, lvl, r.Message)) {
+		putSlogArgs(argsPtr, args)
+		return nil
+	}
+
+	// The record carries its own caller, so the Logger stack frames must not be inspected
+	var pc uintptr
+	if c.callerEnabled {
+		pc = r.PC
+	}
+
+	var stacktrace string
+	if c.isStacktraceEnabled(lvl) {
+		stacktrace = c.captureStacktrace(0, r.PC)
+	}
+
+	c.logWith(loggerLvl, pc, stacktrace, r.Time, lvl, lvlName, outType, args...)
+
+	putSlogArgs(argsPtr, args)
+
+	return nil
+}
+
+// putSlogArgs clears the given args and puts them back into the pool.
+func putSlogArgs(argsPtr *[]any, args []any) {
+	clear(args)
+	*argsPtr = args[:0]
+	slogArgsPool.Put(argsPtr)
+}
+
+// WithAttrs returns a new SlogHandler whose records will include the given attributes.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	return h.withGroupOrAttrs(groupOrAttrs{attrs: attrs})
+}
+
+// WithGroup returns a new SlogHandler whose following attributes will be nested under the given group name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return h.withGroupOrAttrs(groupOrAttrs{group: name})
+}
+
+// withGroupOrAttrs returns a copy of the handler with the given groupOrAttrs appended.
+func (h *SlogHandler) withGroupOrAttrs(goa groupOrAttrs) *SlogHandler {
+	goas := make([]groupOrAttrs, len(h.goas), len(h.goas)+1)
+	copy(goas, h.goas)
+
+	return &SlogHandler{logger: h.logger, goas: append(goas, goa)}
+}
+
+// appendKeyVals appends the given handler attributes and the record ones to 'keyVals' as key/value pairs.
+// Groups are appended as shared.Group values, and omitted when they do not contain any attribute.
+func (h *SlogHandler) appendKeyVals(keyVals []any, goas []groupOrAttrs, r slog.Record) []any {
+	for i, goa := range goas {
+		if goa.group != "" {
+			if nested := h.appendKeyVals(nil, goas[i+1:], r); len(nested) > 0 {
+				keyVals = append(keyVals, goa.group, s.Group(nested))
+			}
+
+			return keyVals
+		}
+
+		for _, attr := range goa.attrs {
+			keyVals = h.appendAttr(keyVals, attr)
+		}
+	}
+
+	r.Attrs(func(attr slog.Attr) bool {
+		keyVals = h.appendAttr(keyVals, attr)
+		return true
+	})
+
+	return keyVals
+}
+
+// appendAttr appends the given resolved attribute to 'keyVals', ignoring empty attributes and groups.
+func (h *SlogHandler) appendAttr(keyVals []any, attr slog.Attr) []any {
+	attr.Value = attr.Value.Resolve()
+
+	if attr.Equal(slog.Attr{}) {
+		return keyVals
+	}
+
+	if attr.Value.Kind() != slog.KindGroup {
+		return append(keyVals, attr.Key, attr.Value.Any())
+	}
+
+	groupAttrs := attr.Value.Group()
+
+	// Groups with an empty key are inlined
+	if attr.Key == "" {
+		for _, groupAttr := range groupAttrs {
+			keyVals = h.appendAttr(keyVals, groupAttr)
+		}
+
+		return keyVals
+	}
+
+	var nested []any
+	for _, groupAttr := range groupAttrs {
+		nested = h.appendAttr(nested, groupAttr)
+	}
+
+	if len(nested) == 0 {
+		return keyVals
+	}
+
+	return append(keyVals, attr.Key, s.Group(nested))
+}
+
+// slogLvlToLogLvl maps the given slog level to the closest lower-severity Logger level.
+func slogLvlToLogLvl(level slog.Level) (int8, ll.LogLvlName) {
+	switch {
+	case level < slog.LevelDebug:
+		return ll.TraceLvl, ll.TraceLvlName
+	case level < slog.LevelInfo:
+		return ll.DebugLvl, ll.DebugLvlName
+	case level < slog.LevelWarn:
+		return ll.InfoLvl, ll.InfoLvlName
+	case level < slog.LevelError:
+		return ll.WarnLvl, ll.WarnLvlName
+	default:
+		return ll.ErrorLvl, ll.ErrorLvlName
+	}
+}
+
+// NewSlogHandler returns a new SlogHandler writing through the given Logger.
+func NewSlogHandler(logger *Logger) *SlogHandler {
+	return &SlogHandler{logger: logger}
+}
@@ -0,0 +1,132 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	"github.com/Pho3b/tiny-logger/shared"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// parseJsonLines parses the given JSON log lines into slog-like maps: the log entry properties
+// are mapped to the slog built-in keys, while the extras are moved to the top level.
+func parseJsonLines(t *testing.T, output string) []map[string]any {
+	var results []map[string]any
+
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var entry map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+
+		result := map[string]any{slog.LevelKey: entry["level"], slog.MessageKey: entry["msg"]}
+		for _, timeKey := range []string{"datetime", "date", "time", "ts"} {
+			if ts, found := entry[timeKey]; found {
+				result[slog.TimeKey] = ts
+			}
+		}
+
+		if extras, ok := entry["extras"].(map[string]any); ok {
+			for k, v := range extras {
+				result[k] = v
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func TestSlogHandler_Conformance(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger().SetEncoder(shared.JsonEncoderType).AddDateTime(true).SetOutput(&buf)
+
+	err := slogtest.TestHandler(NewSlogHandler(logger), func() []map[string]any {
+		return parseJsonLines(t, buf.String())
+	})
+	assert.NoError(t, err)
+}
+
+func TestSlogHandler_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger().SetLogLvl(ll.InfoLvlName).SetOutput(&buf)
+	slogger := slog.New(NewSlogHandler(logger))
+
+	assert.False(t, slogger.Enabled(t.Context(), slog.LevelDebug))
+	assert.True(t, slogger.Enabled(t.Context(), slog.LevelInfo))
+
+	slogger.Debug("debug message")
+	slogger.Info("info message")
+	slogger.Log(t.Context(), slog.LevelInfo+2, "notice message")
+	slogger.Warn("warn message")
+	slogger.Error("error message")
+	slogger.Log(t.Context(), slog.LevelError+4, "critical message")
+
+	assert.Equal(
+		t,
+		"INFO: info message\nINFO: notice message\nWARN: warn message\nERROR: error message\nERROR: critical message\n",
+		buf.String(),
+	)
+
+	buf.Reset()
+	logger.SetLogLvl(ll.TraceLvlName)
+	slogger.Log(t.Context(), slog.LevelDebug-4, "trace message")
+	slogger.Debug("debug message")
+	assert.Equal(t, "TRACE: trace message\nDEBUG: debug message\n", buf.String())
+}
+
+func TestSlogHandler_Groups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger().SetEncoder(shared.JsonEncoderType).SetOutput(&buf)
+	slogger := slog.New(NewSlogHandler(logger)).With("service", "payments").WithGroup("request")
+
+	slogger.Info("request served", "id", 3, slog.Group("user", "name", "alice", "admin", true))
+	assert.Equal(
+		t,
+		`{"level":"INFO","msg":"request served","extras":{"service":"payments",`+
+			`"request":{"id":3,"user":{"name":"alice","admin":true}}}}`+"\n",
+		buf.String(),
+	)
+}
+
+func TestSlogHandler_YamlGroups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger().SetEncoder(shared.YamlEncoderType).SetOutput(&buf)
+	slogger := slog.New(NewSlogHandler(logger))
+
+	slogger.Warn("disk usage", slog.Group("disk", "path", "/var", "usage", 0.93), "host", "db-1")
+
+	var entry shared.YamlLog
+	assert.NoError(t, yaml.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "WARN", entry.Level)
+	assert.Equal(t, "disk usage", entry.Message)
+	assert.Equal(t, map[string]any{"path": "/var", "usage": 0.93}, entry.Extras["disk"])
+	assert.Equal(t, "db-1", entry.Extras["host"])
+}
+
+func TestSlogHandler_RecordTime(t *testing.T) {
+	var buf, file bytes.Buffer
+	logger := NewLogger().
+		AddDateTime(true).
+		SetOutput(&buf).
+		AddDestination(&file, ll.DebugLvlName, shared.JsonEncoderType, false)
+	handler := NewSlogHandler(logger)
+	recordTime := time.Date(2024, 3, 11, 18, 35, 43, 0, time.Local)
+
+	// The entries are dated with the time of the record, not with the time they are handled at
+	assert.NoError(t, handler.Handle(t.Context(), slog.NewRecord(recordTime, slog.LevelInfo, "replayed", 0)))
+	assert.Equal(t, "INFO [11/03/2024 18:35:43]: replayed\n", buf.String())
+	assert.Equal(t, `{"level":"INFO","datetime":"11/03/2024 18:35:43","msg":"replayed"}`+"\n", file.String())
+
+	buf.Reset()
+	logger.SetDateTimeFormat(shared.UnixTimestamp)
+	assert.NoError(t, handler.Handle(t.Context(), slog.NewRecord(recordTime, slog.LevelInfo, "replayed", 0)))
+	assert.Equal(t, "INFO ["+strconv.FormatInt(recordTime.Unix(), 10)+"]: replayed\n", buf.String())
+}
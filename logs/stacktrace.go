@@ -0,0 +1,94 @@
+package logs
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxStacktraceDepth is the maximum number of frames captured in a stack trace.
+	maxStacktraceDepth = 64
+	// initialGoroutinesDumpSize and maxGoroutinesDumpSize bound the buffer the goroutines stacks are dumped into.
+	initialGoroutinesDumpSize = 64 << 10
+	maxGoroutinesDumpSize     = 64 << 20
+)
+
+var stacktracePCsPool = sync.Pool{
+	New: func() any {
+		pcs := make([]uintptr, maxStacktraceDepth)
+		return &pcs
+	},
+}
+
+// captureStacktrace returns the stack trace of the current goroutine skipping the given number of frames, where 0
+// identifies the caller of captureStacktrace. If 'fromPC' is not 0 and it is found in the stack, the trace starts
+// from its frame.
+func (c *loggerConfigs) captureStacktrace(skip int, fromPC uintptr) string {
+	pcsPtr := stacktracePCsPool.Get().(*[]uintptr)
+	pcs := (*pcsPtr)[:runtime.Callers(skip+2, *pcsPtr)]
+
+	if fromPC != 0 {
+		for i, pc := range pcs {
+			if pc == fromPC {
+				pcs = pcs[i:]
+				break
+			}
+		}
+	}
+
+	stacktrace := c.formatStacktrace(pcs)
+	stacktracePCsPool.Put(pcsPtr)
+
+	return stacktrace
+}
+
+// formatStacktrace returns the frames of the given program counters as "function\n\tfile:line" blocks separated by
+// new lines, leaving out the runtime and testing frames if the trimming is enabled.
+func (c *loggerConfigs) formatStacktrace(pcs []uintptr) string {
+	var sb strings.Builder
+	frames := runtime.CallersFrames(pcs)
+
+	for {
+		frame, more := frames.Next()
+
+		if frame.Function != "" && !(c.stacktraceTrim && isRuntimeFrame(frame.Function)) {
+			if sb.Len() > 0 {
+				sb.WriteByte('\n')
+			}
+
+			sb.WriteString(frame.Function)
+			sb.WriteString("\n\t")
+			sb.WriteString(frame.File)
+			sb.WriteByte(':')
+			sb.WriteString(strconv.Itoa(frame.Line))
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return sb.String()
+}
+
+// isRuntimeFrame returns true if the given function belongs to the runtime or testing packages.
+func isRuntimeFrame(function string) bool {
+	return strings.HasPrefix(function, "runtime.") || strings.HasPrefix(function, "testing.")
+}
+
+// dumpGoroutines returns the stack traces of all the goroutines in the runtime.Stack format,
+// truncated to maxGoroutinesDumpSize.
+func dumpGoroutines() string {
+	buf := make([]byte, initialGoroutinesDumpSize)
+
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxGoroutinesDumpSize {
+			return strings.TrimSuffix(string(buf[:n]), "\n")
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,124 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	ll "github.com/Pho3b/tiny-logger/logs/log_level"
+	s "github.com/Pho3b/tiny-logger/shared"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+const stacktraceTestPkg = "github.com/Pho3b/tiny-logger/logs."
+
+func TestLogger_AddStacktrace(t *testing.T) {
+	var out, errOut bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).SetStdErrWriter(&errOut).AddStacktrace(ll.ErrorLvlName)
+	assert.Equal(t, ll.ErrorLvlName, logger.GetStacktraceLvlName())
+
+	logger.Warn("warn message")
+	assert.Equal(t, "WARN: warn message\n", out.String())
+
+	location := currentLocation()
+	logger.With("id", 1).Error("error message")
+	lines := strings.Split(errOut.String(), "\n")
+	assert.Equal(t, "ERROR: error message id=1", lines[0])
+	assert.Equal(t, stacktraceTestPkg+"TestLogger_AddStacktrace", lines[1])
+	assert.True(t, strings.HasSuffix(lines[2], location))
+	assert.True(t, strings.HasPrefix(lines[2], "\t"))
+	assert.Contains(t, errOut.String(), "testing.tRunner")
+	assert.Equal(t, "", lines[len(lines)-1])
+
+	errOut.Reset()
+	logger.DisableStacktrace()
+	logger.Error("error message")
+	assert.Equal(t, "ERROR: error message\n", errOut.String())
+	assert.Equal(t, ll.LogLvlName(""), logger.GetStacktraceLvlName())
+}
+
+func TestLogger_TrimStacktrace(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).AddStacktrace(ll.InfoLvlName).TrimStacktrace(true)
+
+	logger.Info("info message")
+	assert.Contains(t, out.String(), stacktraceTestPkg+"TestLogger_TrimStacktrace")
+	assert.NotContains(t, out.String(), "testing.")
+	assert.NotContains(t, out.String(), "runtime.")
+}
+
+func TestLogger_AddStacktrace_Encoders(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).AddStacktrace(ll.WarnLvlName).TrimStacktrace(true)
+	expectedStart := stacktraceTestPkg + "TestLogger_AddStacktrace_Encoders\n\t"
+
+	logger.SetEncoder(s.JsonEncoderType)
+	logger.Warn("json message", "key", 1)
+
+	var jsonLog s.JsonLog
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &jsonLog))
+	assert.Equal(t, float64(1), jsonLog.Extras["key"])
+	assert.True(t, strings.HasPrefix(jsonLog.Stacktrace, expectedStart))
+
+	out.Reset()
+	logger.SetEncoder(s.YamlEncoderType)
+	logger.Warn("yaml message")
+	assert.Contains(t, out.String(), "stacktrace: |-\n  "+stacktraceTestPkg)
+
+	var yamlLog s.YamlLog
+	assert.NoError(t, yaml.Unmarshal(out.Bytes(), &yamlLog))
+	assert.Equal(t, "yaml message", yamlLog.Message)
+	assert.True(t, strings.HasPrefix(yamlLog.Stacktrace, expectedStart))
+
+	out.Reset()
+	logger.SetEncoder(s.LogfmtEncoderType)
+	logger.Warn("logfmt message")
+	assert.True(t, strings.HasPrefix(out.String(), `level=warn msg="logfmt message" stacktrace="`+stacktraceTestPkg))
+}
+
+func TestSlogHandler_AddStacktrace(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger().SetStdOutWriter(&out).AddStacktrace(ll.InfoLvlName)
+	slogger := slog.New(NewSlogHandler(logger))
+
+	slogger.Info("slog message")
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, "INFO: slog message", lines[0])
+	assert.Equal(t, stacktraceTestPkg+"TestSlogHandler_AddStacktrace", lines[1])
+}
+
+func TestLogger_DumpGoroutinesOnFatal(t *testing.T) {
+	if os.Getenv("BE_CRASHER") == "1" {
+		NewLogger().DumpGoroutinesOnFatal(true).FatalError("fatal message")
+		return
+	}
+
+	var errOut bytes.Buffer
+	cmd := exec.Command(os.Args[0], "-test.run=TestLogger_DumpGoroutinesOnFatal")
+	cmd.Env = append(os.Environ(), "BE_CRASHER=1")
+	cmd.Stderr = &errOut
+
+	err := cmd.Run()
+	exitError, ok := err.(*exec.ExitError)
+	assert.True(t, ok && exitError.ExitCode() == 1)
+	assert.True(t, strings.HasPrefix(errOut.String(), "FATAL_ERROR: fatal message\ngoroutine "))
+	assert.Contains(t, errOut.String(), stacktraceTestPkg+"TestLogger_DumpGoroutinesOnFatal")
+}
+
+func TestDumpGoroutines(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() { <-done }()
+
+	dump := dumpGoroutines()
+	assert.True(t, strings.HasPrefix(dump, "goroutine "))
+	assert.Contains(t, dump, "[running]")
+	assert.Contains(t, dump, "[chan receive]")
+	assert.False(t, strings.HasSuffix(dump, "\n"))
+}
@@ -0,0 +1,30 @@
+package logs
+
+import (
+	"io"
+	"os"
+
+	s "github.com/Pho3b/tiny-logger/shared"
+)
+
+// stdWriter writes to os.Stdout or os.Stderr, resolving them at write time so that
+// redirections of the standard outputs are honoured by writers created in advance.
+type stdWriter s.OutputType
+
+// Write writes the given bytes to the standard output matching the stdWriter.
+func (w stdWriter) Write(p []byte) (int, error) {
+	if s.OutputType(w) == s.StdErrOutput {
+		return os.Stderr.Write(p)
+	}
+
+	return os.Stdout.Write(p)
+}
+
+// stdWriterOr returns the given writer if not nil, otherwise the stdWriter of the given output type.
+func stdWriterOr(w io.Writer, outType s.OutputType) io.Writer {
+	if w != nil {
+		return w
+	}
+
+	return stdWriter(outType)
+}
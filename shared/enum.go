@@ -3,11 +3,16 @@ package shared
 type EncoderType string
 
 const (
-	DefaultEncoderType EncoderType = "default"
-	JsonEncoderType    EncoderType = "json"
-	YamlEncoderType    EncoderType = "yaml"
+	DefaultEncoderType  EncoderType = "default"
+	JsonEncoderType     EncoderType = "json"
+	YamlEncoderType     EncoderType = "yaml"
+	LogfmtEncoderType   EncoderType = "logfmt"
+	SyslogEncoderType   EncoderType = "syslog"
+	JournaldEncoderType EncoderType = "journald"
 )
 
+// OutputType identifies the destination of a log entry.
+// FileOutput refers to any writer set through Logger.SetOutput or Logger.SetLogFile.
 type OutputType int8
 
 const (
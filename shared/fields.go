@@ -0,0 +1,93 @@
+package shared
+
+import (
+	"bytes"
+	"reflect"
+	"sync/atomic"
+)
+
+// maxCachedEncodings is the number of encoders whose encoding of the BoundFields is cached.
+// Reaching it, the cache is reset, so that replacing the encoders of a Logger does not grow it indefinitely.
+const maxCachedEncodings = 8
+
+// FieldsEncoderInterface is implemented by the encoders able to pre-encode BoundFields.
+type FieldsEncoderInterface interface {
+	EncodeFields(buf *bytes.Buffer, keyVals ...any)
+	GetType() EncoderType
+}
+
+// BoundFields holds the key/value pairs bound to a Logger through Logger.With.
+// The pairs are encoded only once per encoder and cached, so that every log entry can simply copy
+// the pre-encoded bytes. The cache is keyed by the encoder instance, since encoders of the same type
+// can be configured differently.
+type BoundFields struct {
+	keyVals []any
+	encoded atomic.Pointer[map[FieldsEncoderInterface][]byte]
+}
+
+// KeyVals returns the bound key/value pairs.
+func (f *BoundFields) KeyVals() []any {
+	return f.keyVals
+}
+
+// With returns new BoundFields containing the current key/value pairs followed by the given ones.
+func (f *BoundFields) With(keyVals ...any) *BoundFields {
+	if len(keyVals) == 0 {
+		return f
+	}
+
+	if f == nil {
+		return NewBoundFields(keyVals...)
+	}
+
+	merged := make([]any, 0, len(f.keyVals)+len(keyVals)+1)
+	merged = append(merged, f.keyVals...)
+
+	return NewBoundFields(append(merged, keyVals...)...)
+}
+
+// Encoded returns the key/value pairs encoded by the given encoder, encoding and caching them on the first call.
+// The encoders that cannot be used as map keys (non-comparable types) encode the pairs at every call.
+func (f *BoundFields) Encoded(encoder FieldsEncoderInterface) []byte {
+	var buf bytes.Buffer
+	if !reflect.TypeOf(encoder).Comparable() {
+		encoder.EncodeFields(&buf, f.keyVals...)
+		return buf.Bytes()
+	}
+
+	cache := f.encoded.Load()
+	if cache != nil {
+		if encoded, found := (*cache)[encoder]; found {
+			return encoded
+		}
+	}
+
+	encoder.EncodeFields(&buf, f.keyVals...)
+	encoded := buf.Bytes()
+
+	for {
+		newCache := make(map[FieldsEncoderInterface][]byte, 4)
+		if cache != nil && len(*cache) < maxCachedEncodings {
+			for k, v := range *cache {
+				newCache[k] = v
+			}
+		}
+
+		newCache[encoder] = encoded
+		if f.encoded.CompareAndSwap(cache, &newCache) {
+			return encoded
+		}
+
+		cache = f.encoded.Load()
+	}
+}
+
+// NewBoundFields initializes and returns new BoundFields holding the given key/value pairs.
+// A missing value for the last key is set to nil.
+func NewBoundFields(keyVals ...any) *BoundFields {
+	if len(keyVals)%2 != 0 {
+		keyVals = append(keyVals[:len(keyVals):len(keyVals)], nil)
+	}
+
+	return &BoundFields{keyVals: keyVals}
+}
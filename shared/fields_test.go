@@ -0,0 +1,65 @@
+package shared
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingEncoder is a FieldsEncoderInterface counting how many times the fields are encoded.
+type countingEncoder struct {
+	calls  int
+	prefix string
+}
+
+func (c *countingEncoder) EncodeFields(buf *bytes.Buffer, keyVals ...any) {
+	c.calls++
+	buf.WriteString(c.prefix + fmt.Sprintf("%v", keyVals))
+}
+
+func (c *countingEncoder) GetType() EncoderType {
+	return DefaultEncoderType
+}
+
+func TestNewBoundFields(t *testing.T) {
+	fields := NewBoundFields("user", "alice", "id")
+	assert.Equal(t, []any{"user", "alice", "id", nil}, fields.KeyVals())
+}
+
+func TestBoundFields_With(t *testing.T) {
+	var nilFields *BoundFields
+	parent := nilFields.With("user", "alice")
+	child := parent.With("id", 3)
+
+	assert.Equal(t, []any{"user", "alice"}, parent.KeyVals())
+	assert.Equal(t, []any{"user", "alice", "id", 3}, child.KeyVals())
+}
+
+func TestBoundFields_Encoded(t *testing.T) {
+	encoder := &countingEncoder{}
+	fields := NewBoundFields("user", "alice")
+
+	assert.Equal(t, "[user alice]", string(fields.Encoded(encoder)))
+	assert.Equal(t, "[user alice]", string(fields.Encoded(encoder)))
+	assert.Equal(t, 1, encoder.calls)
+}
+
+func TestBoundFields_Encoded_PerEncoder(t *testing.T) {
+	first, second := &countingEncoder{}, &countingEncoder{prefix: "second"}
+	fields := NewBoundFields("user", "alice")
+
+	// The encoders share the same type, but not their configuration
+	assert.Equal(t, "[user alice]", string(fields.Encoded(first)))
+	assert.Equal(t, "second[user alice]", string(fields.Encoded(second)))
+	assert.Equal(t, "[user alice]", string(fields.Encoded(first)))
+	assert.Equal(t, 1, first.calls)
+	assert.Equal(t, 1, second.calls)
+
+	for range 2 * maxCachedEncodings {
+		fields.Encoded(&countingEncoder{})
+	}
+
+	assert.LessOrEqual(t, len(*fields.encoded.Load()), maxCachedEncodings)
+}
@@ -1,18 +1,29 @@
 package shared
 
 import (
-	"os"
+	"io"
+	"time"
 
 	"github.com/Pho3b/tiny-logger/logs/colors"
 	"github.com/Pho3b/tiny-logger/logs/log_level"
 )
 
 type LoggerInterface interface {
+	Trace(args ...any)
 	Debug(args ...any)
 	Info(args ...any)
 	Warn(args ...any)
 	Error(args ...any)
 	FatalError(args ...any)
+	Panic(args ...any)
+	Log(logLvlName log_level.LogLvlName, args ...any)
+	Tracef(format string, args ...any)
+	Debugf(format string, args ...any)
+	Infof(format string, args ...any)
+	Warnf(format string, args ...any)
+	Errorf(format string, args ...any)
+	FatalErrorf(format string, args ...any)
+	Panicf(format string, args ...any)
 }
 
 type LoggerConfigsInterface interface {
@@ -22,8 +33,27 @@ type LoggerConfigsInterface interface {
 	GetLogLvlName() log_level.LogLvlName
 	GetLogLvlIntValue() int8
 	GetEncoderType() EncoderType
-	GetLogFile() *os.File
+	GetOutputWriter(outType OutputType) io.Writer
 	GetDateTimeFormat() DateTimeFormat
+	GetBoundFields() *BoundFields
+}
+
+// CallerProviderInterface is implemented by the LoggerConfigsInterface values carrying the caller of the entry
+// being logged. A nil caller means the caller annotation is disabled or the caller could not be resolved.
+type CallerProviderInterface interface {
+	GetCaller() (caller *Caller, showFunction bool)
+}
+
+// StacktraceProviderInterface is implemented by the LoggerConfigsInterface values carrying the stack trace of the
+// entry being logged. An empty string means no stack trace has been captured for the entry.
+type StacktraceProviderInterface interface {
+	GetStacktrace() string
+}
+
+// TimeProviderInterface is implemented by the LoggerConfigsInterface values carrying the time of the entry
+// being logged. The zero time means the entry is logged at the current time.
+type TimeProviderInterface interface {
+	GetTime() time.Time
 }
 
 type EncoderInterface interface {
@@ -1,21 +1,69 @@
 package shared
 
+import (
+	"fmt"
+	"strings"
+)
+
 // JsonLog represents the structure of a JSON log and can be used to Unmarshal JSON logEntries.
 type JsonLog struct {
-	Level    string         `json:"level,omitempty"`
-	Date     string         `json:"date,omitempty"`
-	Time     string         `json:"time,omitempty"`
-	DateTime string         `json:"datetime,omitempty"`
-	Message  string         `json:"msg"`
-	Extras   map[string]any `json:"extras,omitempty"`
+	Level      string         `json:"level,omitempty"`
+	Date       string         `json:"date,omitempty"`
+	Time       string         `json:"time,omitempty"`
+	DateTime   string         `json:"datetime,omitempty"`
+	Caller     string         `json:"caller,omitempty"`
+	Function   string         `json:"func,omitempty"`
+	Message    string         `json:"msg"`
+	Extras     map[string]any `json:"extras,omitempty"`
+	Stacktrace string         `json:"stacktrace,omitempty"`
 }
 
 // YamlLog represents the structure of a YAML log and can be used to Unmarshal YAML log entries.
 type YamlLog struct {
-	Level    string         `yaml:"level,omitempty"`
-	Date     string         `yaml:"date,omitempty"`
-	Time     string         `yaml:"time,omitempty"`
-	DateTime string         `yaml:"datetime,omitempty"`
-	Message  string         `yaml:"msg"`
-	Extras   map[string]any `yaml:"extras,omitempty"`
+	Level      string         `yaml:"level,omitempty"`
+	Date       string         `yaml:"date,omitempty"`
+	Time       string         `yaml:"time,omitempty"`
+	DateTime   string         `yaml:"datetime,omitempty"`
+	Caller     string         `yaml:"caller,omitempty"`
+	Function   string         `yaml:"func,omitempty"`
+	Message    string         `yaml:"msg"`
+	Extras     map[string]any `yaml:"extras,omitempty"`
+	Stacktrace string         `yaml:"stacktrace,omitempty"`
+}
+
+// Caller describes the source code location a log entry comes from.
+// File is trimmed to its parent directory, Function is qualified by its package name only,
+// while Location holds the pre-formatted "File:Line" string written by the encoders.
+type Caller struct {
+	File     string
+	Line     int
+	Function string
+	Location string
+}
+
+// Group is an ordered list of key/value pairs that the encoders render as a nested object.
+type Group []any
+
+// String returns the Group as space separated key=value pairs enclosed in curly braces.
+func (g Group) String() string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+
+	for i := 0; i < len(g); i += 2 {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+
+		_, _ = fmt.Fprint(&sb, g[i])
+		sb.WriteByte('=')
+
+		if i+1 < len(g) {
+			_, _ = fmt.Fprint(&sb, g[i+1])
+		} else {
+			sb.WriteString("<nil>")
+		}
+	}
+
+	sb.WriteByte('}')
+	return sb.String()
 }
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/Pho3b/tiny-logger/logs"
+	"github.com/Pho3b/tiny-logger/logs/log_level"
+	"github.com/Pho3b/tiny-logger/logs/sinks"
 	"github.com/Pho3b/tiny-logger/shared"
 )
 
@@ -82,3 +84,135 @@ func BenchmarkYamlEncoderAllPropertiesEnabled(b *testing.B) {
 		logger.Debug("YAML encoder", "all-properties-enabled", true, "id", i)
 	}
 }
+
+func BenchmarkLogfmtEncoderAllPropertiesDisabled(b *testing.B) {
+	b.ReportAllocs()
+
+	logger := logs.NewLogger().
+		SetEncoder(shared.LogfmtEncoderType).ShowLogLevel(false).SetLogFile(initDevNullFile())
+
+	for i := 0; i < b.N; i++ {
+		logger.Debug("Logfmt encoder", "all-properties-enabled", false, "id", i)
+	}
+}
+
+func BenchmarkLogfmtEncoderAllPropertiesEnabled(b *testing.B) {
+	b.ReportAllocs()
+
+	logger := logs.NewLogger().
+		SetEncoder(shared.LogfmtEncoderType).
+		ShowLogLevel(true).
+		AddDateTime(true).
+		SetLogFile(initDevNullFile())
+
+	for i := 0; i < b.N; i++ {
+		logger.Debug("Logfmt encoder", "all-properties-enabled", true, "id", i)
+	}
+}
+
+func BenchmarkJsonEncoderWithBoundFields(b *testing.B) {
+	b.ReportAllocs()
+
+	logger := logs.NewLogger().
+		SetEncoder(shared.JsonEncoderType).
+		ShowLogLevel(true).
+		AddDateTime(true).
+		SetLogFile(initDevNullFile()).
+		With("service", "payments", "version", 3)
+
+	for i := 0; i < b.N; i++ {
+		logger.Debug("JSON encoder", "bound-fields", true, "id", i)
+	}
+}
+
+func BenchmarkJsonEncoderWithCaller(b *testing.B) {
+	b.ReportAllocs()
+
+	logger := logs.NewLogger().
+		SetEncoder(shared.JsonEncoderType).
+		AddCaller(true).
+		AddCallerFunc(true).
+		SetLogFile(initDevNullFile())
+
+	for i := 0; i < b.N; i++ {
+		logger.Debug("JSON encoder", "caller-enabled", true, "id", i)
+	}
+}
+
+func BenchmarkJsonEncoderWithLvlOverrides(b *testing.B) {
+	b.ReportAllocs()
+
+	logger := logs.NewLogger().
+		SetEncoder(shared.JsonEncoderType).
+		SetLogLvl(log_level.ErrorLvlName).
+		SetLvlOverrides("payments/*=error,tiny-logger/test=debug").
+		SetLogFile(initDevNullFile())
+
+	for i := 0; i < b.N; i++ {
+		logger.Debug("JSON encoder", "overrides-enabled", true, "id", i)
+	}
+}
+
+func BenchmarkDefaultEncoderAsync(b *testing.B) {
+	b.ReportAllocs()
+
+	logger := logs.NewLogger().
+		SetEncoder(shared.DefaultEncoderType).
+		ShowLogLevel(true).
+		AddDateTime(true).
+		SetLogFile(initDevNullFile()).
+		SetAsync(sinks.AsyncConfig{Capacity: 4096, OverflowPolicy: sinks.BlockOnOverflow})
+	defer logger.DisableAsync()
+
+	for i := 0; i < b.N; i++ {
+		logger.Debug("DEFAULT encoder", "async", true, "id", i)
+	}
+}
+
+func BenchmarkJsonEncoderAsync(b *testing.B) {
+	b.ReportAllocs()
+
+	logger := logs.NewLogger().
+		SetEncoder(shared.JsonEncoderType).
+		ShowLogLevel(true).
+		AddDateTime(true).
+		SetLogFile(initDevNullFile()).
+		SetAsync(sinks.AsyncConfig{Capacity: 4096, OverflowPolicy: sinks.BlockOnOverflow})
+	defer logger.DisableAsync()
+
+	for i := 0; i < b.N; i++ {
+		logger.Debug("JSON encoder", "async", true, "id", i)
+	}
+}
+
+func BenchmarkJsonEncoderAsyncDropNewest(b *testing.B) {
+	b.ReportAllocs()
+
+	logger := logs.NewLogger().
+		SetEncoder(shared.JsonEncoderType).
+		ShowLogLevel(true).
+		AddDateTime(true).
+		SetLogFile(initDevNullFile()).
+		SetAsync(sinks.AsyncConfig{Capacity: 4096, OverflowPolicy: sinks.DropNewestOnOverflow})
+	defer logger.DisableAsync()
+
+	for i := 0; i < b.N; i++ {
+		logger.Debug("JSON encoder", "async", true, "id", i)
+	}
+}
+
+func BenchmarkYamlEncoderAsync(b *testing.B) {
+	b.ReportAllocs()
+
+	logger := logs.NewLogger().
+		SetEncoder(shared.YamlEncoderType).
+		ShowLogLevel(true).
+		AddDateTime(true).
+		SetLogFile(initDevNullFile()).
+		SetAsync(sinks.AsyncConfig{Capacity: 4096, OverflowPolicy: sinks.BlockOnOverflow})
+	defer logger.DisableAsync()
+
+	for i := 0; i < b.N; i++ {
+		logger.Debug("YAML encoder", "async", true, "id", i)
+	}
+}
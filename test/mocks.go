@@ -1,17 +1,20 @@
 package test
 
 import (
-	"os"
+	"io"
 
 	"github.com/Pho3b/tiny-logger/logs/log_level"
 	"github.com/Pho3b/tiny-logger/shared"
 )
 
 type LoggerConfigMock struct {
-	DateEnabled   bool
-	TimeEnabled   bool
-	ColorsEnabled bool
-	ShowLogLevel  bool
+	DateEnabled    bool
+	TimeEnabled    bool
+	ColorsEnabled  bool
+	ShowLogLevel   bool
+	DateTimeFormat shared.DateTimeFormat
+	Output         io.Writer
+	Fields         *shared.BoundFields
 }
 
 func (m *LoggerConfigMock) GetLogLvlName() log_level.LogLvlName {
@@ -38,10 +41,18 @@ func (m *LoggerConfigMock) GetShowLogLevel() bool {
 	return m.ShowLogLevel
 }
 
-func (m *LoggerConfigMock) GetLogFile() *os.File {
+func (m *LoggerConfigMock) GetOutputWriter(outType shared.OutputType) io.Writer {
+	if outType == shared.FileOutput {
+		return m.Output
+	}
+
 	return nil
 }
 
 func (m *LoggerConfigMock) GetDateTimeFormat() shared.DateTimeFormat {
-	return shared.IT
+	return m.DateTimeFormat
+}
+
+func (m *LoggerConfigMock) GetBoundFields() *shared.BoundFields {
+	return m.Fields
 }